@@ -0,0 +1,162 @@
+// backfill-reference memetakan customers.industry / customers.region (teks bebas)
+// ke nama kanonik di tabel industries / regions (lihat migrations/006).
+//
+//	go run ./cmd/backfill-reference -dry-run
+//	go run ./cmd/backfill-reference
+//
+// Nilai kosong / "-" dikosongkan (NULL). Nilai yang tidak dikenal dilaporkan
+// dan dibiarkan apa adanya (tambahkan alias lalu jalankan ulang), kecuali
+// -clear-unmatched. Jika semua sudah kanonik, FK customers_*_fkey divalidasi.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"sales-system-backend/database"
+
+	"github.com/joho/godotenv"
+)
+
+type change struct {
+	id       int64
+	name     string
+	field    string
+	from     string
+	to       *string
+	resolved bool
+}
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "tampilkan perubahan tanpa menulis ke database")
+	clearUnmatched := flag.Bool("clear-unmatched", false, "kosongkan nilai yang tidak dikenal")
+	flag.Parse()
+
+	_ = godotenv.Load()
+
+	if err := database.Init(); err != nil {
+		log.Fatalf("Database initialization failed: %v", err)
+	}
+	defer database.Pool.Close()
+
+	ctx := context.Background()
+
+	rows, err := database.Pool.Query(ctx, `
+		SELECT id, name,
+		       industry, resolve_industry(industry),
+		       region, resolve_region(region)
+		FROM customers
+		ORDER BY id
+	`)
+	if err != nil {
+		log.Fatalf("query customers: %v", err)
+	}
+
+	changes := []change{}
+	unmatched := map[string]int{}
+
+	for rows.Next() {
+		var (
+			id                   int64
+			name                 string
+			industry, industryTo *string
+			region, regionTo     *string
+		)
+		if err := rows.Scan(&id, &name, &industry, &industryTo, &region, &regionTo); err != nil {
+			log.Fatalf("scan customer: %v", err)
+		}
+
+		for _, f := range []struct {
+			field    string
+			from, to *string
+		}{
+			{"industry", industry, industryTo},
+			{"region", region, regionTo},
+		} {
+			if f.from == nil {
+				continue
+			}
+			from := *f.from
+			trimmed := strings.TrimSpace(from)
+
+			switch {
+			case trimmed == "" || trimmed == "-":
+				changes = append(changes, change{id, name, f.field, from, nil, true})
+			case f.to != nil:
+				if *f.to != from {
+					changes = append(changes, change{id, name, f.field, from, f.to, true})
+				}
+			default:
+				unmatched[f.field]++
+				changes = append(changes, change{id, name, f.field, from, nil, false})
+			}
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		log.Fatalf("read customers: %v", err)
+	}
+
+	for _, ch := range changes {
+		to := "NULL"
+		if ch.to != nil {
+			to = *ch.to
+		}
+		status := "map"
+		if !ch.resolved {
+			status = "UNMATCHED"
+			if !*clearUnmatched {
+				to = "(unchanged)"
+			}
+		}
+		fmt.Printf("%-9s #%-5d %-40.40s %-8s %q → %s\n", status, ch.id, ch.name, ch.field, ch.from, to)
+	}
+	fmt.Printf("\n%d change(s), unmatched industry=%d region=%d\n",
+		len(changes), unmatched["industry"], unmatched["region"])
+
+	if *dryRun {
+		fmt.Println("dry-run: no changes written")
+		return
+	}
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		log.Fatalf("begin tx: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	applied := 0
+	for _, ch := range changes {
+		if !ch.resolved && !*clearUnmatched {
+			continue
+		}
+		// field berasal dari whitelist di atas
+		q := fmt.Sprintf(`UPDATE customers SET %s = $1, updated_at = NOW() WHERE id = $2`, ch.field)
+		if _, err := tx.Exec(ctx, q, ch.to, ch.id); err != nil {
+			log.Fatalf("update customer #%d: %v", ch.id, err)
+		}
+		applied++
+	}
+
+	for _, field := range []string{"industry", "region"} {
+		if unmatched[field] > 0 && !*clearUnmatched {
+			fmt.Printf("skip VALIDATE customers_%s_fkey: %d unmatched value(s)\n", field, unmatched[field])
+			continue
+		}
+		if _, err := tx.Exec(ctx, fmt.Sprintf(
+			`ALTER TABLE customers VALIDATE CONSTRAINT customers_%s_fkey`, field,
+		)); err != nil {
+			log.Fatalf("validate customers_%s_fkey: %v", field, err)
+		}
+		fmt.Printf("validated customers_%s_fkey\n", field)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Fatalf("commit: %v", err)
+	}
+
+	fmt.Printf("applied %d change(s)\n", applied)
+}
@@ -0,0 +1,777 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"sales-system-backend/database"
+	"sales-system-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+var activityTypes = []string{"call", "meeting", "visit", "email", "other"}
+
+func normalizeActivityType(t string) (string, bool) {
+	t = strings.ToLower(strings.TrimSpace(t))
+	for _, v := range activityTypes {
+		if v == t {
+			return v, true
+		}
+	}
+	return t, false
+}
+
+// parseActivityDate → RFC3339 atau YYYY-MM-DD (tanggal saja = 00:00 Asia/Jakarta)
+func parseActivityDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", s, mustLoadLocation("Asia/Jakarta"))
+}
+
+// customer & divisi activity project diambil dari project (bukan kolom activities),
+// supaya ACL ikut kalau project pindah divisi
+const activityDivisionExpr = `COALESCE(p.division, a.division)`
+
+const activityColumns = `
+	a.id, a.type, a.activity_date, a.project_id, p.project_code,
+	COALESCE(a.customer_id, p.customer_id), cu.name, ` + activityDivisionExpr + `,
+	a.subject, a.notes, a.outcome,
+	a.next_step, a.next_step_due, a.next_step_owner_id, nu.username, a.next_step_done_at,
+	a.created_by, cb.username, a.created_at, a.updated_at
+`
+
+const activityJoins = `
+	FROM activities a
+	LEFT JOIN projects p ON p.id = a.project_id
+	LEFT JOIN customers cu ON cu.id = COALESCE(a.customer_id, p.customer_id)
+	LEFT JOIN users nu ON nu.id = a.next_step_owner_id
+	LEFT JOIN users cb ON cb.id = a.created_by
+`
+
+func scanActivity(row pgx.Row, extra ...any) (models.Activity, error) {
+	var a models.Activity
+	dest := []any{
+		&a.ID, &a.Type, &a.ActivityDate, &a.ProjectID, &a.ProjectCode,
+		&a.CustomerID, &a.CustomerName, &a.Division,
+		&a.Subject, &a.Notes, &a.Outcome,
+		&a.NextStep, &a.NextStepDue, &a.NextStepOwnerID, &a.NextStepOwner, &a.NextStepDoneAt,
+		&a.CreatedBy, &a.CreatedByName, &a.CreatedAt, &a.UpdatedAt,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	a.Participants = []models.ActivityParticipant{}
+	return a, err
+}
+
+// attachActivityParticipants → load peserta untuk banyak activity sekaligus
+func attachActivityParticipants(ctx context.Context, db dbtx, list []models.Activity) error {
+	if len(list) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(list))
+	idx := map[int64]int{}
+	for i, a := range list {
+		ids[i] = a.ID
+		idx[a.ID] = i
+	}
+
+	rows, err := db.Query(ctx, `
+		SELECT ap.activity_id, ap.contact_id, ap.user_id,
+		       COALESCE(cc.name, u.username, ap.name)
+		FROM activity_participants ap
+		LEFT JOIN customer_contacts cc ON cc.id = ap.contact_id
+		LEFT JOIN users u ON u.id = ap.user_id
+		WHERE ap.activity_id = ANY($1)
+		ORDER BY ap.activity_id, ap.id
+	`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var activityID int64
+		var p models.ActivityParticipant
+		if err := rows.Scan(&activityID, &p.ContactID, &p.UserID, &p.Name); err != nil {
+			return err
+		}
+		switch {
+		case p.ContactID != nil:
+			p.Kind = "contact"
+		case p.UserID != nil:
+			p.Kind = "user"
+		default:
+			p.Kind = "external"
+		}
+		i := idx[activityID]
+		list[i].Participants = append(list[i].Participants, p)
+	}
+
+	return rows.Err()
+}
+
+func loadActivity(ctx context.Context, db dbtx, id int64) (models.Activity, error) {
+	a, err := scanActivity(db.QueryRow(ctx, `SELECT `+activityColumns+activityJoins+` WHERE a.id = $1`, id))
+	if err != nil {
+		return a, err
+	}
+	list := []models.Activity{a}
+	if err := attachActivityParticipants(ctx, db, list); err != nil {
+		return a, err
+	}
+	return list[0], nil
+}
+
+// checkActivityAccess → 404 / 403 berdasarkan divisi activity
+func checkActivityAccess(c *gin.Context, id int64) (models.Activity, bool) {
+	a, err := loadActivity(c.Request.Context(), database.Pool, id)
+	if err == pgx.ErrNoRows {
+		c.JSON(404, gin.H{"error": "activity not found"})
+		return a, false
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return a, false
+	}
+
+	userDivision := NormalizeDivision(c.GetString("division"))
+	if c.GetString("role") == "user" && (userDivision == "" || userDivision != NormalizeDivision(a.Division)) {
+		c.JSON(403, gin.H{"error": "forbidden: cannot access activity in another division"})
+		return a, false
+	}
+
+	return a, true
+}
+
+func parseActivityID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid activity id"})
+		return 0, false
+	}
+	return id, true
+}
+
+// activityInput = request yang sudah divalidasi & dinormalisasi
+type activityInput struct {
+	Type         string
+	ActivityDate time.Time
+	NextStepDue  *time.Time
+	Subject      string
+	Notes        *string
+	Outcome      *string
+	NextStep     *string
+}
+
+func validateActivityRequest(req *models.ActivityRequest) (activityInput, string) {
+	var in activityInput
+
+	t, ok := normalizeActivityType(req.Type)
+	if !ok {
+		return in, "invalid type (call, meeting, visit, email, other)"
+	}
+	in.Type = t
+
+	at, err := parseActivityDate(req.ActivityDate)
+	if err != nil {
+		return in, "invalid activity_date (RFC3339 or YYYY-MM-DD)"
+	}
+	in.ActivityDate = at
+
+	in.Subject = strings.TrimSpace(req.Subject)
+	if in.Subject == "" {
+		return in, "subject is required"
+	}
+
+	in.Notes = trimmedOrNil(req.Notes)
+	in.Outcome = trimmedOrNil(req.Outcome)
+	in.NextStep = trimmedOrNil(req.NextStep)
+
+	if req.NextStepDue != nil && strings.TrimSpace(*req.NextStepDue) != "" {
+		due, err := time.Parse("2006-01-02", strings.TrimSpace(*req.NextStepDue))
+		if err != nil {
+			return in, "invalid next_step_due (YYYY-MM-DD)"
+		}
+		if in.NextStep == nil {
+			return in, "next_step is required when next_step_due is set"
+		}
+		in.NextStepDue = &due
+	}
+
+	for _, p := range req.Participants {
+		n := 0
+		if p.ContactID != nil {
+			n++
+		}
+		if p.UserID != nil {
+			n++
+		}
+		if p.Name != nil && strings.TrimSpace(*p.Name) != "" {
+			n++
+		}
+		if n != 1 {
+			return in, "each participant needs exactly one of contact_id, user_id or name"
+		}
+	}
+
+	return in, ""
+}
+
+func trimmedOrNil(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := strings.TrimSpace(*s)
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+// replaceActivityParticipantsTx → hapus lalu insert ulang; contact harus milik customer activity
+func replaceActivityParticipantsTx(ctx context.Context, tx pgx.Tx, activityID int64, customerID *int64, list []models.ActivityParticipantRequest) (string, error) {
+	if _, err := tx.Exec(ctx, `DELETE FROM activity_participants WHERE activity_id = $1`, activityID); err != nil {
+		return "", err
+	}
+
+	for _, p := range list {
+		if p.ContactID != nil {
+			var owner int64
+			err := tx.QueryRow(ctx, `SELECT customer_id FROM customer_contacts WHERE id = $1`, *p.ContactID).Scan(&owner)
+			if err == pgx.ErrNoRows {
+				return fmt.Sprintf("contact %d not found", *p.ContactID), nil
+			}
+			if err != nil {
+				return "", err
+			}
+			if customerID == nil || owner != *customerID {
+				return fmt.Sprintf("contact %d does not belong to the activity's customer", *p.ContactID), nil
+			}
+		}
+		if p.UserID != nil {
+			var exists bool
+			if err := tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)`, *p.UserID).Scan(&exists); err != nil {
+				return "", err
+			}
+			if !exists {
+				return fmt.Sprintf("user %d not found", *p.UserID), nil
+			}
+		}
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO activity_participants (activity_id, contact_id, user_id, name)
+			VALUES ($1, $2, $3, $4)
+		`, activityID, p.ContactID, p.UserID, trimmedOrNil(p.Name)); err != nil {
+			return "", err
+		}
+	}
+
+	return "", nil
+}
+
+// ======================================================
+// LIST — GET /activities?project_id=&customer_id=&type=&division=
+//   &mine=&owner_id=&from=&to=&open_next_steps=
+// mine / owner_id → pembuat activity atau owner next step
+// ======================================================
+
+func ListActivities(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	role := c.GetString("role")
+	userDiv := NormalizeDivision(c.GetString("division"))
+
+	conds := []string{"1=1"}
+	args := []any{}
+	i := 1
+
+	division := NormalizeDivision(strings.TrimSpace(c.Query("division")))
+	if role == "user" {
+		division = userDiv
+	}
+	if division != "" && strings.ToUpper(division) != "ALL" {
+		conds = append(conds, fmt.Sprintf(activityDivisionExpr+" = $%d", i))
+		args = append(args, division)
+		i++
+	}
+
+	if v := strings.TrimSpace(c.Query("project_id")); v != "" {
+		projectID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid project_id"})
+			return
+		}
+		conds = append(conds, fmt.Sprintf("a.project_id = $%d", i))
+		args = append(args, projectID)
+		i++
+	}
+
+	// customer → activity customer + activity semua project customer tsb
+	if v := strings.TrimSpace(c.Query("customer_id")); v != "" {
+		customerID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid customer_id"})
+			return
+		}
+		conds = append(conds, fmt.Sprintf("COALESCE(a.customer_id, p.customer_id) = $%d", i))
+		args = append(args, customerID)
+		i++
+	}
+
+	if v := strings.TrimSpace(c.Query("type")); v != "" && strings.ToUpper(v) != "ALL" {
+		t, ok := normalizeActivityType(v)
+		if !ok {
+			c.JSON(400, gin.H{"error": "invalid type"})
+			return
+		}
+		conds = append(conds, fmt.Sprintf("a.type = $%d", i))
+		args = append(args, t)
+		i++
+	}
+
+	var ownerID int64
+	if v := strings.TrimSpace(c.Query("mine")); v == "true" || v == "1" {
+		ownerID = c.GetInt64("user_id")
+	} else if v := strings.TrimSpace(c.Query("owner_id")); v != "" && strings.ToUpper(v) != "ALL" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid owner_id"})
+			return
+		}
+		ownerID = id
+	}
+	if ownerID > 0 {
+		conds = append(conds, fmt.Sprintf("(a.created_by = $%[1]d OR a.next_step_owner_id = $%[1]d)", i))
+		args = append(args, ownerID)
+		i++
+	}
+
+	for _, f := range []struct{ key, cond string }{
+		{"from", "a.activity_date >= $%d::date"},
+		{"to", "a.activity_date < $%d::date + 1"},
+	} {
+		v := strings.TrimSpace(c.Query(f.key))
+		if v == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", v); err != nil {
+			c.JSON(400, gin.H{"error": "invalid " + f.key + " (YYYY-MM-DD)"})
+			return
+		}
+		conds = append(conds, fmt.Sprintf(f.cond, i))
+		args = append(args, v)
+		i++
+	}
+
+	if v := strings.TrimSpace(c.Query("open_next_steps")); v == "true" || v == "1" {
+		conds = append(conds, "a.next_step IS NOT NULL AND a.next_step_done_at IS NULL")
+	}
+
+	rows, err := database.Pool.Query(ctx, `SELECT `+activityColumns+activityJoins+`
+		WHERE `+strings.Join(conds, " AND ")+`
+		ORDER BY a.activity_date DESC, a.id DESC
+	`, args...)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	list := []models.Activity{}
+	for rows.Next() {
+		a, err := scanActivity(rows)
+		if err != nil {
+			rows.Close()
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		list = append(list, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := attachActivityParticipants(ctx, database.Pool, list); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, list)
+}
+
+// ======================================================
+// OVERDUE NEXT STEPS — GET /activities/overdue?user_id=
+// default: user login; admin boleh user_id=<id> | ALL
+// ======================================================
+
+func ListOverdueNextSteps(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	conds := []string{
+		"a.next_step_due IS NOT NULL",
+		"a.next_step_done_at IS NULL",
+		"a.next_step_due < $1",
+	}
+	args := []any{jakartaToday()}
+
+	v := strings.TrimSpace(c.Query("user_id"))
+	switch {
+	case c.GetString("role") != "admin" || v == "":
+		conds = append(conds, "a.next_step_owner_id = $2")
+		args = append(args, c.GetInt64("user_id"))
+	case strings.ToUpper(v) == "ALL":
+		// semua user
+	default:
+		uid, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid user_id"})
+			return
+		}
+		conds = append(conds, "a.next_step_owner_id = $2")
+		args = append(args, uid)
+	}
+
+	rows, err := database.Pool.Query(ctx, `SELECT `+activityColumns+`, ($1::date - a.next_step_due)::int
+		`+activityJoins+`
+		WHERE `+strings.Join(conds, " AND ")+`
+		ORDER BY nu.username ASC, a.next_step_due ASC, a.id ASC
+	`, args...)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	list := []models.Activity{}
+	for rows.Next() {
+		var days int
+		a, err := scanActivity(rows, &days)
+		if err != nil {
+			rows.Close()
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		a.DaysOverdue = &days
+		list = append(list, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := attachActivityParticipants(ctx, database.Pool, list); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, list)
+}
+
+// GET /activities/:id
+func GetActivity(c *gin.Context) {
+	id, ok := parseActivityID(c)
+	if !ok {
+		return
+	}
+
+	a, ok := checkActivityAccess(c, id)
+	if !ok {
+		return
+	}
+
+	c.JSON(200, a)
+}
+
+// ======================================================
+// CREATE — POST /activities
+// project_id → divisi project; customer_id → divisi user (admin: body.division)
+// next step owner default: pembuat activity
+// ======================================================
+
+func CreateActivity(c *gin.Context) {
+	var req models.ActivityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "type, activity_date and subject are required"})
+		return
+	}
+
+	in, msg := validateActivityRequest(&req)
+	if msg != "" {
+		c.JSON(400, gin.H{"error": msg})
+		return
+	}
+
+	var (
+		division          string
+		contactCustomerID *int64 // customer pemilik contact peserta
+		projectID         *int64
+		customerID        *int64
+	)
+
+	switch {
+	case req.ProjectID != nil && req.CustomerID != nil:
+		c.JSON(400, gin.H{"error": "use either project_id or customer_id"})
+		return
+
+	case req.ProjectID != nil:
+		p, ok := checkProjectAccess(c, *req.ProjectID)
+		if !ok {
+			return
+		}
+		division = p.Division
+		projectID = &p.ID
+		contactCustomerID = p.CustomerID
+
+	case req.CustomerID != nil:
+		if !checkCustomerVisible(c, *req.CustomerID) {
+			return
+		}
+		division = NormalizeDivision(req.Division)
+		if c.GetString("role") == "user" {
+			division = NormalizeDivision(c.GetString("division"))
+		}
+		if !isValidDivision(division) {
+			c.JSON(400, gin.H{"error": "invalid division"})
+			return
+		}
+		customerID = req.CustomerID
+		contactCustomerID = req.CustomerID
+
+	default:
+		c.JSON(400, gin.H{"error": "project_id or customer_id is required"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	nextOwner, msg := resolveNextStepOwnerTx(ctx, tx, in, req.NextStepOwnerID, userID, division)
+	if msg != "" {
+		c.JSON(400, gin.H{"error": msg})
+		return
+	}
+
+	var id int64
+	err = tx.QueryRow(ctx, `
+		INSERT INTO activities (
+			type, activity_date, project_id, customer_id, division,
+			subject, notes, outcome,
+			next_step, next_step_due, next_step_owner_id,
+			created_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NULLIF($12::bigint, 0))
+		RETURNING id
+	`,
+		in.Type, in.ActivityDate, projectID, customerID, division,
+		in.Subject, in.Notes, in.Outcome,
+		in.NextStep, in.NextStepDue, nextOwner,
+		userID,
+	).Scan(&id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if msg, err := replaceActivityParticipantsTx(ctx, tx, id, contactCustomerID, req.Participants); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	} else if msg != "" {
+		c.JSON(400, gin.H{"error": msg})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	c.JSON(201, gin.H{"id": id})
+}
+
+// resolveNextStepOwnerTx → owner next step (nil kalau tidak ada next step)
+func resolveNextStepOwnerTx(ctx context.Context, tx pgx.Tx, in activityInput, requested *int64, userID int64, division string) (*int64, string) {
+	if in.NextStep == nil {
+		return nil, ""
+	}
+
+	owner := requested
+	if owner == nil {
+		if userID <= 0 {
+			return nil, ""
+		}
+		owner = &userID
+	}
+
+	if msg := validateProjectOwnerTx(ctx, tx, *owner, division); msg != "" {
+		return nil, msg
+	}
+	return owner, ""
+}
+
+// ======================================================
+// UPDATE — PUT /activities/:id (project / customer tidak bisa dipindah)
+// ======================================================
+
+func UpdateActivity(c *gin.Context) {
+	id, ok := parseActivityID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ActivityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "type, activity_date and subject are required"})
+		return
+	}
+
+	in, msg := validateActivityRequest(&req)
+	if msg != "" {
+		c.JSON(400, gin.H{"error": msg})
+		return
+	}
+
+	a, ok := checkActivityAccess(c, id)
+	if !ok {
+		return
+	}
+
+	if (req.ProjectID != nil && (a.ProjectID == nil || *req.ProjectID != *a.ProjectID)) ||
+		(req.CustomerID != nil && a.ProjectID != nil) ||
+		(req.CustomerID != nil && (a.CustomerID == nil || *req.CustomerID != *a.CustomerID)) {
+		c.JSON(400, gin.H{"error": "activity cannot be moved to another project or customer"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// owner next step lama dipertahankan kalau tidak dikirim
+	requestedOwner := req.NextStepOwnerID
+	if requestedOwner == nil {
+		requestedOwner = a.NextStepOwnerID
+	}
+	nextOwner, msg := resolveNextStepOwnerTx(ctx, tx, in, requestedOwner, c.GetInt64("user_id"), a.Division)
+	if msg != "" {
+		c.JSON(400, gin.H{"error": msg})
+		return
+	}
+
+	// next step diganti → status done di-reset
+	if _, err := tx.Exec(ctx, `
+		UPDATE activities SET
+			type = $1,
+			activity_date = $2,
+			subject = $3,
+			notes = $4,
+			outcome = $5,
+			next_step_done_at = CASE
+				WHEN next_step IS NOT DISTINCT FROM $6 AND next_step_due IS NOT DISTINCT FROM $7::date
+				THEN next_step_done_at
+			END,
+			next_step = $6,
+			next_step_due = $7,
+			next_step_owner_id = $8,
+			updated_at = NOW()
+		WHERE id = $9
+	`,
+		in.Type, in.ActivityDate, in.Subject, in.Notes, in.Outcome,
+		in.NextStep, in.NextStepDue, nextOwner, id,
+	); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Participants != nil {
+		if msg, err := replaceActivityParticipantsTx(ctx, tx, id, a.CustomerID, req.Participants); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		} else if msg != "" {
+			c.JSON(400, gin.H{"error": msg})
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "updated"})
+}
+
+// PUT /activities/:id/next-step {done: true|false}
+func SetActivityNextStepDone(c *gin.Context) {
+	id, ok := parseActivityID(c)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Done *bool `json:"done" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "done is required"})
+		return
+	}
+
+	a, ok := checkActivityAccess(c, id)
+	if !ok {
+		return
+	}
+	if a.NextStep == nil {
+		c.JSON(400, gin.H{"error": "activity has no next step"})
+		return
+	}
+
+	if _, err := database.Pool.Exec(c.Request.Context(), `
+		UPDATE activities
+		SET next_step_done_at = CASE WHEN $1 THEN COALESCE(next_step_done_at, NOW()) END,
+		    updated_at = NOW()
+		WHERE id = $2
+	`, *body.Done, id); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "updated", "done": *body.Done})
+}
+
+// DELETE /activities/:id
+func DeleteActivity(c *gin.Context) {
+	id, ok := parseActivityID(c)
+	if !ok {
+		return
+	}
+
+	if _, ok := checkActivityAccess(c, id); !ok {
+		return
+	}
+
+	if _, err := database.Pool.Exec(c.Request.Context(), `DELETE FROM activities WHERE id = $1`, id); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "deleted"})
+}
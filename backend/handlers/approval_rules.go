@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"sales-system-backend/database"
+	"sales-system-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// normalizeOptionalDivision → nil untuk kosong / ALL; ok=false kalau divisi tidak dikenal
+func normalizeOptionalDivision(d *string) (*string, bool) {
+	if d == nil {
+		return nil, true
+	}
+	n := NormalizeDivision(*d)
+	if n == "" || strings.ToUpper(n) == "ALL" {
+		return nil, true
+	}
+	if !isValidDivision(n) {
+		return nil, false
+	}
+	return &n, true
+}
+
+// ======================================================
+// DISCOUNT APPROVAL RULES — /discount-rules
+// ======================================================
+
+func ListDiscountRules(c *gin.Context) {
+	rows, err := database.Pool.Query(c, `
+		SELECT id, division, min_discount_pct::float8, level, is_active, created_at, updated_at
+		FROM discount_approval_rules
+		ORDER BY division NULLS FIRST, min_discount_pct ASC
+	`)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	list := []models.DiscountApprovalRule{}
+	for rows.Next() {
+		var r models.DiscountApprovalRule
+		if err := rows.Scan(&r.ID, &r.Division, &r.MinDiscountPct, &r.Level, &r.IsActive, &r.CreatedAt, &r.UpdatedAt); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		list = append(list, r)
+	}
+
+	c.JSON(200, list)
+}
+
+func bindDiscountRule(c *gin.Context) (models.DiscountApprovalRuleRequest, bool) {
+	var req models.DiscountApprovalRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "level is required"})
+		return req, false
+	}
+
+	req.Level = strings.ToLower(strings.TrimSpace(req.Level))
+	if _, ok := approvalLevelRank[req.Level]; !ok {
+		c.JSON(400, gin.H{"error": "level must be manager or director"})
+		return req, false
+	}
+	if req.MinDiscountPct < 0 || req.MinDiscountPct >= 100 {
+		c.JSON(400, gin.H{"error": "min_discount_pct must be between 0 and 100"})
+		return req, false
+	}
+
+	div, ok := normalizeOptionalDivision(req.Division)
+	if !ok {
+		c.JSON(400, gin.H{"error": "invalid division"})
+		return req, false
+	}
+	req.Division = div
+
+	return req, true
+}
+
+func CreateDiscountRule(c *gin.Context) {
+	req, ok := bindDiscountRule(c)
+	if !ok {
+		return
+	}
+
+	active := true
+	if req.IsActive != nil {
+		active = *req.IsActive
+	}
+
+	var id int64
+	err := database.Pool.QueryRow(c, `
+		INSERT INTO discount_approval_rules (division, min_discount_pct, level, is_active)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, req.Division, req.MinDiscountPct, req.Level, active).Scan(&id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(201, gin.H{"id": id})
+}
+
+func UpdateDiscountRule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid rule id"})
+		return
+	}
+
+	req, ok := bindDiscountRule(c)
+	if !ok {
+		return
+	}
+
+	tag, err := database.Pool.Exec(c, `
+		UPDATE discount_approval_rules
+		SET division = $1, min_discount_pct = $2, level = $3,
+		    is_active = COALESCE($4, is_active), updated_at = NOW()
+		WHERE id = $5
+	`, req.Division, req.MinDiscountPct, req.Level, req.IsActive, id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(404, gin.H{"error": "rule not found"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "updated"})
+}
+
+func DeleteDiscountRule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid rule id"})
+		return
+	}
+
+	tag, err := database.Pool.Exec(c, `DELETE FROM discount_approval_rules WHERE id = $1`, id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(404, gin.H{"error": "rule not found"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "deleted"})
+}
+
+// ======================================================
+// APPROVERS — /approvers
+// ======================================================
+
+func ListApprovers(c *gin.Context) {
+	rows, err := database.Pool.Query(c, `
+		SELECT a.id, a.user_id, u.username, a.level, a.division, a.created_at
+		FROM approvers a
+		JOIN users u ON u.id = a.user_id
+		ORDER BY a.division NULLS FIRST, a.level DESC, u.username ASC
+	`)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	list := []models.Approver{}
+	for rows.Next() {
+		var a models.Approver
+		if err := rows.Scan(&a.ID, &a.UserID, &a.Username, &a.Level, &a.Division, &a.CreatedAt); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		list = append(list, a)
+	}
+
+	c.JSON(200, list)
+}
+
+func CreateApprover(c *gin.Context) {
+	var req models.ApproverRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "user_id and level are required"})
+		return
+	}
+
+	req.Level = strings.ToLower(strings.TrimSpace(req.Level))
+	if _, ok := approvalLevelRank[req.Level]; !ok {
+		c.JSON(400, gin.H{"error": "level must be manager or director"})
+		return
+	}
+
+	div, ok := normalizeOptionalDivision(req.Division)
+	if !ok {
+		c.JSON(400, gin.H{"error": "invalid division"})
+		return
+	}
+
+	var active bool
+	if err := database.Pool.QueryRow(c,
+		`SELECT is_active FROM users WHERE id = $1`, req.UserID,
+	).Scan(&active); err != nil {
+		c.JSON(404, gin.H{"error": "user not found"})
+		return
+	}
+	if !active {
+		c.JSON(400, gin.H{"error": "user is deactivated"})
+		return
+	}
+
+	var id int64
+	err := database.Pool.QueryRow(c, `
+		INSERT INTO approvers (user_id, level, division)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+		RETURNING id
+	`, req.UserID, req.Level, div).Scan(&id)
+	if err == pgx.ErrNoRows {
+		c.JSON(409, gin.H{"error": "approver already exists"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(201, gin.H{"id": id})
+}
+
+func DeleteApprover(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid approver id"})
+		return
+	}
+
+	tag, err := database.Pool.Exec(c, `DELETE FROM approvers WHERE id = $1`, id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(404, gin.H{"error": "approver not found"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "deleted"})
+}
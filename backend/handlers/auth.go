@@ -40,11 +40,11 @@ func Login(c *gin.Context) {
 
 	var user models.User
 	err := database.Pool.QueryRow(ctx,
-		`SELECT id, username, password_hash, role, division
+		`SELECT id, username, password_hash, role, division, is_active
          FROM users
          WHERE username = $1`,
 		req.Username,
-	).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Role, &user.Division)
+	).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Role, &user.Division, &user.IsActive)
 
 	if err != nil {
 		c.JSON(401, gin.H{"error": "invalid username/password"})
@@ -56,6 +56,11 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	if !user.IsActive {
+		c.JSON(403, gin.H{"error": "user is deactivated"})
+		return
+	}
+
 	user.Division = NormalizeDivision(user.Division)
 
 	claims := jwt.MapClaims{
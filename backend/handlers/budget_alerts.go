@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"sales-system-backend/database"
+	"sales-system-backend/models"
+	"sales-system-backend/notifier"
+
+	"github.com/gin-gonic/gin"
+)
+
+// dipakai kalau divisi belum punya konfigurasi threshold sendiri
+var defaultBudgetThresholds = []float64{80, 100, 120}
+
+// ======================================================
+// GET THRESHOLDS (ACL APPLIED)
+// ======================================================
+
+func GetBudgetThresholds(c *gin.Context) {
+	role := c.GetString("role")
+	userDiv := NormalizeDivision(c.GetString("division"))
+
+	division := NormalizeDivision(strings.TrimSpace(c.Query("division")))
+	if role == "user" {
+		division = userDiv
+	}
+	if strings.ToUpper(division) == "ALL" {
+		division = ""
+	}
+
+	divisions := canonicalDivisions
+	if division != "" {
+		if !isValidDivision(division) {
+			c.JSON(400, gin.H{"error": "invalid division"})
+			return
+		}
+		divisions = []string{division}
+	}
+
+	result := map[string][]models.BudgetAlertThreshold{}
+	for _, d := range divisions {
+		list, err := loadBudgetThresholds(c, d)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		result[d] = list
+	}
+
+	c.JSON(200, result)
+}
+
+// ======================================================
+// SET THRESHOLDS (ADMIN) — replace seluruh set per divisi
+// ======================================================
+
+func SetBudgetThresholds(c *gin.Context) {
+	var req models.SetBudgetThresholdsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "invalid body"})
+		return
+	}
+
+	req.Division = NormalizeDivision(req.Division)
+	if !isValidDivision(req.Division) {
+		c.JSON(400, gin.H{"error": "invalid division"})
+		return
+	}
+
+	seen := map[float64]bool{}
+	for _, t := range req.Thresholds {
+		if t.ThresholdPct <= 0 || t.ThresholdPct > 1000 {
+			c.JSON(400, gin.H{"error": "threshold_pct must be between 0 and 1000"})
+			return
+		}
+		if seen[t.ThresholdPct] {
+			c.JSON(400, gin.H{"error": fmt.Sprintf("duplicate threshold %.2f", t.ThresholdPct)})
+			return
+		}
+		seen[t.ThresholdPct] = true
+	}
+
+	tx, err := database.Pool.Begin(c)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(c)
+
+	if _, err := tx.Exec(c, `DELETE FROM budget_alert_thresholds WHERE division = $1`, req.Division); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, t := range req.Thresholds {
+		notifyEmail := true
+		if t.NotifyEmail != nil {
+			notifyEmail = *t.NotifyEmail
+		}
+
+		if _, err := tx.Exec(c, `
+			INSERT INTO budget_alert_thresholds (division, threshold_pct, notify_email)
+			VALUES ($1, $2, $3)
+		`, req.Division, t.ThresholdPct, notifyEmail); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "ok"})
+}
+
+func loadBudgetThresholds(ctx context.Context, division string) ([]models.BudgetAlertThreshold, error) {
+	rows, err := database.Pool.Query(ctx, `
+		SELECT id, division, threshold_pct, notify_email
+		FROM budget_alert_thresholds
+		WHERE division = $1
+		ORDER BY threshold_pct
+	`, division)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.BudgetAlertThreshold{}
+	for rows.Next() {
+		var t models.BudgetAlertThreshold
+		if err := rows.Scan(&t.ID, &t.Division, &t.ThresholdPct, &t.NotifyEmail); err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(list) == 0 {
+		for _, pct := range defaultBudgetThresholds {
+			list = append(list, models.BudgetAlertThreshold{
+				Division:     division,
+				ThresholdPct: pct,
+				NotifyEmail:  true,
+			})
+		}
+	}
+
+	return list, nil
+}
+
+// ======================================================
+// EVALUATE THRESHOLDS (dipanggil setelah realisasi berubah)
+// ======================================================
+
+// evaluateBudgetThresholds mengecek achievement budget terhadap threshold divisi.
+// Threshold yang baru terlewati dicatat di budget_alert_events lalu dikirim
+// sebagai notifikasi in-app (+ email jika diaktifkan). Threshold di atas
+// achievement saat ini di-reset supaya bisa terpicu lagi.
+func evaluateBudgetThresholds(ctx context.Context, budgetID int64) error {
+	var (
+		division     string
+		month        time.Time
+		budgetAmount float64
+	)
+	err := database.Pool.QueryRow(ctx, `
+		SELECT division, month, budget_amount
+		FROM budgets
+		WHERE id = $1
+	`, budgetID).Scan(&division, &month, &budgetAmount)
+	if err != nil {
+		return err
+	}
+
+	division = NormalizeDivision(division)
+	if budgetAmount <= 0 {
+		return nil
+	}
+
+	totalReal, err := GetTotalRealization(ctx, budgetID)
+	if err != nil {
+		return err
+	}
+	achievement := (totalReal / budgetAmount) * 100
+
+	thresholds, err := loadBudgetThresholds(ctx, division)
+	if err != nil {
+		return err
+	}
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// re-arm threshold yang sudah tidak terlewati (realisasi turun)
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM budget_alert_events
+		WHERE budget_id = $1 AND threshold_pct > $2
+	`, budgetID, achievement); err != nil {
+		return err
+	}
+
+	var crossed []models.BudgetAlertThreshold
+	for _, t := range thresholds {
+		if achievement < t.ThresholdPct {
+			continue
+		}
+
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO budget_alert_events (budget_id, threshold_pct, achievement)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (budget_id, threshold_pct) DO NOTHING
+		`, budgetID, t.ThresholdPct, achievement)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() > 0 {
+			crossed = append(crossed, t)
+		}
+	}
+
+	if len(crossed) == 0 {
+		return tx.Commit(ctx)
+	}
+
+	// cukup 1 notifikasi untuk threshold tertinggi yang baru terlewati
+	sort.Slice(crossed, func(i, j int) bool { return crossed[i].ThresholdPct > crossed[j].ThresholdPct })
+	top := crossed[0]
+
+	title := fmt.Sprintf("Budget %s %s reached %.0f%%", division, month.Format("2006-01"), top.ThresholdPct)
+	message := fmt.Sprintf(
+		"Realization %s of budget %s (%.1f%%) for %s, %s.",
+		formatRupiah(totalReal), formatRupiah(budgetAmount), achievement,
+		division, month.Format("January 2006"),
+	)
+
+	// penerima: user di divisi terkait + semua admin
+	rows, err := tx.Query(ctx, `
+		SELECT id, COALESCE(email, '')
+		FROM users
+		WHERE (division = $1 OR role = 'admin') AND is_active
+	`, division)
+	if err != nil {
+		return err
+	}
+
+	var (
+		userIDs []int64
+		emails  []string
+	)
+	for rows.Next() {
+		var id int64
+		var email string
+		if err := rows.Scan(&id, &email); err != nil {
+			rows.Close()
+			return err
+		}
+		userIDs = append(userIDs, id)
+		if email != "" {
+			emails = append(emails, email)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, uid := range userIDs {
+		if err := createNotification(ctx, tx, uid, "budget_threshold", title, message, "budget", budgetID); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	if top.NotifyEmail {
+		notifier.SendAsync(emails, notificationSubject(title), message)
+	}
+
+	return nil
+}
+
+// realisasi sudah tersimpan; kegagalan evaluasi alert cukup di-log
+func runBudgetThresholdCheck(ctx context.Context, budgetID int64) {
+	if err := evaluateBudgetThresholds(ctx, budgetID); err != nil {
+		log.Println("BUDGET ALERT ERROR:", err)
+	}
+}
@@ -0,0 +1,607 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"sales-system-backend/database"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuri/excelize/v2"
+)
+
+// kolom file import/export budget (urutan export)
+var budgetFileHeaders = []string{
+	"Division", "Month", "Budget Amount",
+	"Realization ID", "Category", "Amount", "Note",
+}
+
+// ======================================================
+// EXPORT BUDGETS + REALIZATION LINES
+// GET /api/budgets/export?division=&year=&format=csv|xlsx
+// filter + ACL sama dengan ListBudgets
+// ======================================================
+
+func ExportBudgets(c *gin.Context) {
+	division := NormalizeDivision(c.Query("division"))
+	year := c.Query("year")
+	format := strings.ToLower(c.DefaultQuery("format", "csv"))
+
+	if format != "csv" && format != "xlsx" {
+		c.JSON(400, gin.H{"error": "format must be csv or xlsx"})
+		return
+	}
+
+	role := c.GetString("role")
+	userDiv := NormalizeDivision(c.GetString("division"))
+
+	// user hanya bisa export division-nya sendiri
+	if role == "user" {
+		division = userDiv
+	}
+
+	rows, err := database.Pool.Query(
+		c,
+		`SELECT
+			b.division,
+			to_char(b.month, 'YYYY-MM'),
+			b.budget_amount::float8,
+			br.id,
+			COALESCE(br.category, ''),
+			br.amount::float8,
+			COALESCE(br.note, '')
+		 FROM budgets b
+		 LEFT JOIN budget_realization br ON br.budget_id = b.id
+		 WHERE ($1='' OR b.division=$1)
+		   AND ($2='' OR EXTRACT(YEAR FROM b.month)=CAST($2 AS INT))
+		 ORDER BY b.month, b.division, br.created_at`,
+		division, year,
+	)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	records := [][]string{budgetFileHeaders}
+	for rows.Next() {
+		var (
+			div, month, category, note string
+			budgetAmount               float64
+			realID                     *int64
+			amount                     *float64
+		)
+		if err := rows.Scan(&div, &month, &budgetAmount, &realID, &category, &amount, &note); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		rec := []string{
+			NormalizeDivision(div),
+			month,
+			strconv.FormatFloat(budgetAmount, 'f', -1, 64),
+			"", category, "", note,
+		}
+		if realID != nil {
+			rec[3] = strconv.FormatInt(*realID, 10)
+		}
+		if amount != nil {
+			rec[5] = strconv.FormatFloat(*amount, 'f', -1, 64)
+		}
+		records = append(records, rec)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	suffix := "all"
+	if year != "" {
+		suffix = year
+	}
+	filename := fmt.Sprintf("budgets_export_%s.%s", suffix, format)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	if format == "xlsx" {
+		f := excelize.NewFile()
+		defer f.Close()
+
+		sheet := f.GetSheetName(0)
+		for i, rec := range records {
+			cell, _ := excelize.CoordinatesToCellName(1, i+1)
+			row := make([]any, len(rec))
+			for j, v := range rec {
+				// kolom angka ditulis sebagai number supaya bisa dijumlah di Excel
+				if i > 0 && (j == 2 || j == 5) && v != "" {
+					if n, err := strconv.ParseFloat(v, 64); err == nil {
+						row[j] = n
+						continue
+					}
+				}
+				row[j] = v
+			}
+			_ = f.SetSheetRow(sheet, cell, &row)
+		}
+
+		c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		if err := f.Write(c.Writer); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	w := csv.NewWriter(c.Writer)
+	defer w.Flush()
+	_ = w.WriteAll(records)
+}
+
+// ======================================================
+// IMPORT BUDGETS + REALIZATION LINES
+// POST /api/budgets/import?dry_run=true  (multipart field: file)
+// - budget di-upsert per (division, month)
+// - line dengan Realization ID → update, tanpa ID → insert
+// - semua dalam 1 transaksi; dry_run selalu rollback
+// ======================================================
+
+type budgetImportError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+type budgetImportRow struct {
+	Row          int
+	Division     string
+	Month        time.Time
+	BudgetAmount *float64
+	RealID       *int64
+	Category     string
+	Amount       float64
+	Note         string
+}
+
+func ImportBudgets(c *gin.Context) {
+	dryRun := c.Query("dry_run") == "true"
+
+	fh, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(400, gin.H{"error": "file is required"})
+		return
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		c.JSON(400, gin.H{"error": "cannot open file"})
+		return
+	}
+	defer f.Close()
+
+	var records [][]string
+	switch strings.ToLower(filepath.Ext(fh.Filename)) {
+	case ".xlsx":
+		records, err = readXLSXRecords(f)
+	case ".csv":
+		records, err = readCSVRecords(f)
+	default:
+		c.JSON(400, gin.H{"error": "unsupported file type (use .csv or .xlsx)"})
+		return
+	}
+	if err != nil {
+		c.JSON(400, gin.H{"error": "failed to read file: " + err.Error()})
+		return
+	}
+	if len(records) < 2 {
+		c.JSON(400, gin.H{"error": "file has no data rows"})
+		return
+	}
+
+	role := c.GetString("role")
+	userDiv := NormalizeDivision(c.GetString("division"))
+
+	parsed, errs := parseBudgetImportRows(records, role, userDiv)
+
+	tx, err := database.Pool.Begin(c)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(c)
+
+	var (
+		budgetsCreated, budgetsUpdated int
+		linesCreated, linesUpdated     int
+	)
+
+	type budgetKey struct {
+		division string
+		month    time.Time
+	}
+	type budgetAmountSrc struct {
+		amount float64
+		row    int
+	}
+	budgetIDs := map[budgetKey]int64{}
+	firstRow := map[budgetKey]int{}
+	// Budget Amount yang sudah dipakai per key (row pertama yang mengisi)
+	appliedAmount := map[budgetKey]budgetAmountSrc{}
+
+	for _, r := range parsed {
+		key := budgetKey{r.Division, r.Month}
+
+		id, ok := budgetIDs[key]
+		if ok && r.BudgetAmount != nil {
+			// Budget Amount di row berikutnya harus sama; row pertama tanpa amount → amount ini dipakai
+			if prev, set := appliedAmount[key]; set {
+				if prev.amount != *r.BudgetAmount {
+					errs = append(errs, budgetImportError{r.Row, fmt.Sprintf(
+						"Budget Amount %.2f conflicts with %.2f on row %d for %s %s",
+						*r.BudgetAmount, prev.amount, prev.row, key.division, key.month.Format("2006-01"))})
+					continue
+				}
+			} else {
+				if _, err := tx.Exec(c,
+					`UPDATE budgets SET budget_amount=$1, updated_at=NOW() WHERE id=$2`,
+					*r.BudgetAmount, id,
+				); err != nil {
+					c.JSON(500, gin.H{"error": err.Error()})
+					return
+				}
+				budgetsUpdated++
+				appliedAmount[key] = budgetAmountSrc{*r.BudgetAmount, r.Row}
+			}
+		}
+		if !ok {
+			var existing bool
+			err := tx.QueryRow(c,
+				`SELECT id FROM budgets WHERE division=$1 AND month=$2`,
+				r.Division, r.Month,
+			).Scan(&id)
+			switch {
+			case err == nil:
+				existing = true
+			case err == pgx.ErrNoRows:
+				if r.BudgetAmount == nil {
+					errs = append(errs, budgetImportError{r.Row, "budget does not exist and Budget Amount is empty"})
+					continue
+				}
+			default:
+				c.JSON(500, gin.H{"error": err.Error()})
+				return
+			}
+
+			if existing && r.BudgetAmount != nil {
+				if _, err := tx.Exec(c,
+					`UPDATE budgets SET budget_amount=$1, updated_at=NOW() WHERE id=$2`,
+					*r.BudgetAmount, id,
+				); err != nil {
+					c.JSON(500, gin.H{"error": err.Error()})
+					return
+				}
+				budgetsUpdated++
+			} else if !existing {
+				if err := tx.QueryRow(c,
+					`INSERT INTO budgets (division, month, budget_amount)
+					 VALUES ($1, $2, $3) RETURNING id`,
+					r.Division, r.Month, *r.BudgetAmount,
+				).Scan(&id); err != nil {
+					c.JSON(500, gin.H{"error": err.Error()})
+					return
+				}
+				budgetsCreated++
+			}
+
+			budgetIDs[key] = id
+			firstRow[key] = r.Row
+			if r.BudgetAmount != nil {
+				appliedAmount[key] = budgetAmountSrc{*r.BudgetAmount, r.Row}
+			}
+		}
+
+		if r.Category == "" {
+			continue
+		}
+
+		if r.RealID != nil {
+			tag, err := tx.Exec(c, `
+				UPDATE budget_realization
+				   SET category=$1, amount=$2, note=$3, updated_at=NOW()
+				 WHERE id=$4 AND budget_id=$5
+			`, r.Category, r.Amount, r.Note, *r.RealID, id)
+			if err != nil {
+				c.JSON(500, gin.H{"error": err.Error()})
+				return
+			}
+			if tag.RowsAffected() == 0 {
+				errs = append(errs, budgetImportError{r.Row, "realization id not found for this budget"})
+				continue
+			}
+			linesUpdated++
+			continue
+		}
+
+		if _, err := tx.Exec(c, `
+			INSERT INTO budget_realization (budget_id, category, amount, note, created_by)
+			VALUES ($1, $2, $3, $4, NULLIF($5::bigint, 0))
+		`, id, r.Category, r.Amount, r.Note, c.GetInt64("user_id")); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		linesCreated++
+	}
+
+	// aturan yang sama dengan UpdateBudget: budget tidak boleh < realisasi
+	for key, id := range budgetIDs {
+		var amount, total float64
+		if err := tx.QueryRow(c, `
+			SELECT b.budget_amount::float8, COALESCE(SUM(br.amount), 0)::float8
+			FROM budgets b
+			LEFT JOIN budget_realization br ON br.budget_id = b.id
+			WHERE b.id = $1
+			GROUP BY b.id
+		`, id).Scan(&amount, &total); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		if amount < total {
+			errs = append(errs, budgetImportError{
+				firstRow[key],
+				fmt.Sprintf("budget %s %s: amount %.2f is less than total realization %.2f",
+					key.division, key.month.Format("2006-01"), amount, total),
+			})
+		}
+	}
+
+	resp := gin.H{
+		"dry_run":         dryRun,
+		"rows":            len(records) - 1,
+		"budgets_created": budgetsCreated,
+		"budgets_updated": budgetsUpdated,
+		"lines_created":   linesCreated,
+		"lines_updated":   linesUpdated,
+		"errors":          errs,
+	}
+
+	if len(errs) > 0 {
+		resp["status"] = "rejected"
+		c.JSON(400, resp)
+		return
+	}
+
+	if dryRun {
+		resp["status"] = "valid"
+		c.JSON(200, resp)
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	for _, id := range budgetIDs {
+		runBudgetThresholdCheck(c, id)
+	}
+
+	resp["status"] = "imported"
+	c.JSON(200, resp)
+}
+
+// parseBudgetImportRows memvalidasi division, month, category & angka per baris.
+// Baris yang gagal validasi tidak ikut diproses.
+func parseBudgetImportRows(records [][]string, role, userDiv string) ([]budgetImportRow, []budgetImportError) {
+	col := map[string]int{}
+	for i, h := range records[0] {
+		col[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+
+	get := func(rec []string, name string) string {
+		i, ok := col[strings.ToLower(name)]
+		if !ok || i >= len(rec) {
+			return ""
+		}
+		return strings.TrimSpace(rec[i])
+	}
+
+	var (
+		out  []budgetImportRow
+		errs []budgetImportError
+	)
+
+	for _, h := range []string{"Division", "Month"} {
+		if _, ok := col[strings.ToLower(h)]; !ok {
+			return nil, []budgetImportError{{1, "missing column: " + h}}
+		}
+	}
+
+	// budget amount harus konsisten antar baris untuk budget yang sama
+	amounts := map[string]float64{}
+
+	for i, rec := range records[1:] {
+		rowNo := i + 2 // header = baris 1
+
+		if isBlankRecord(rec) {
+			continue
+		}
+
+		r := budgetImportRow{Row: rowNo}
+
+		r.Division = NormalizeDivision(get(rec, "Division"))
+		if !isValidDivision(r.Division) {
+			errs = append(errs, budgetImportError{rowNo, "invalid division: " + get(rec, "Division")})
+			continue
+		}
+		if role == "user" && r.Division != userDiv {
+			errs = append(errs, budgetImportError{rowNo, "forbidden: division " + r.Division})
+			continue
+		}
+
+		m, err := parseImportMonth(get(rec, "Month"))
+		if err != nil {
+			errs = append(errs, budgetImportError{rowNo, "invalid month (use YYYY-MM): " + get(rec, "Month")})
+			continue
+		}
+		r.Month = m
+
+		if v := get(rec, "Budget Amount"); v != "" {
+			n, err := parseImportAmount(v)
+			if err != nil || n < 0 {
+				errs = append(errs, budgetImportError{rowNo, "invalid budget amount: " + v})
+				continue
+			}
+			key := r.Division + "|" + m.Format("2006-01")
+			if prev, ok := amounts[key]; ok && prev != n {
+				errs = append(errs, budgetImportError{rowNo, "conflicting budget amount for " + m.Format("2006-01")})
+				continue
+			}
+			amounts[key] = n
+			r.BudgetAmount = &n
+		}
+
+		if v := get(rec, "Realization ID"); v != "" {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				errs = append(errs, budgetImportError{rowNo, "invalid realization id: " + v})
+				continue
+			}
+			r.RealID = &id
+		}
+
+		if v := get(rec, "Category"); v != "" {
+			cat, ok := normalizeBudgetCategory(v)
+			if !ok {
+				errs = append(errs, budgetImportError{rowNo, "invalid category: " + v})
+				continue
+			}
+			r.Category = cat
+
+			n, err := parseImportAmount(get(rec, "Amount"))
+			if err != nil || n <= 0 {
+				errs = append(errs, budgetImportError{rowNo, "amount must be > 0"})
+				continue
+			}
+			r.Amount = n
+			r.Note = get(rec, "Note")
+		} else if get(rec, "Amount") != "" || r.RealID != nil {
+			errs = append(errs, budgetImportError{rowNo, "category is required for realization lines"})
+			continue
+		}
+
+		out = append(out, r)
+	}
+
+	return out, errs
+}
+
+func parseImportMonth(v string) (time.Time, error) {
+	if t, err := time.Parse("2006-01", v); err == nil {
+		return t, nil
+	}
+	// Excel kadang menyimpan sebagai tanggal penuh
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+}
+
+// parseImportAmount → nominal dari sel CSV / Excel.
+// Format Indonesia ("Rp 1.234.567,50", sama dengan formatRupiah) dan format
+// Inggris ("1,234,567.50") diterima. Separator yang muncul sekali dan diikuti
+// tepat 3 digit dianggap pemisah ribuan ("1.234" = 1234).
+func parseImportAmount(v string) (float64, error) {
+	v = strings.ReplaceAll(strings.TrimSpace(v), " ", "")
+	neg := strings.HasPrefix(v, "-")
+	v = strings.TrimPrefix(v, "-")
+	v = strings.TrimPrefix(v, "Rp")
+
+	decimalSep, thousandSep := "", ""
+	lastDot, lastComma := strings.LastIndex(v, "."), strings.LastIndex(v, ",")
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		if lastDot > lastComma {
+			decimalSep, thousandSep = ".", ","
+		} else {
+			decimalSep, thousandSep = ",", "."
+		}
+	case lastDot >= 0:
+		decimalSep, thousandSep = splitAmountSeparator(v, ".")
+	case lastComma >= 0:
+		decimalSep, thousandSep = splitAmountSeparator(v, ",")
+	}
+
+	intPart, frac := v, ""
+	if decimalSep != "" {
+		i := strings.LastIndex(v, decimalSep)
+		intPart, frac = v[:i], v[i+1:]
+		if frac == "" || strings.ContainsAny(frac, ".,") {
+			return 0, fmt.Errorf("invalid amount %q", v)
+		}
+	}
+
+	// grup ribuan: grup pertama 1-3 digit, sisanya tepat 3 digit
+	if thousandSep != "" && strings.Contains(intPart, thousandSep) {
+		groups := strings.Split(intPart, thousandSep)
+		for i, g := range groups {
+			if g == "" || len(g) > 3 || (i > 0 && len(g) != 3) {
+				return 0, fmt.Errorf("invalid thousands grouping in %q", v)
+			}
+		}
+		intPart = strings.Join(groups, "")
+	}
+
+	num := intPart
+	if frac != "" {
+		num += "." + frac
+	}
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, err
+	}
+	if neg {
+		n = -n
+	}
+	return n, nil
+}
+
+// splitAmountSeparator → (decimal, thousands) untuk angka yang hanya memakai satu jenis separator
+func splitAmountSeparator(v, sep string) (string, string) {
+	if strings.Count(v, sep) > 1 {
+		return "", sep
+	}
+	if len(v)-strings.LastIndex(v, sep)-1 == 3 {
+		return "", sep
+	}
+	return sep, ""
+}
+
+func isBlankRecord(rec []string) bool {
+	for _, v := range rec {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func readCSVRecords(r io.Reader) ([][]string, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	return cr.ReadAll()
+}
+
+func readXLSXRecords(r io.Reader) ([][]string, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return f.GetRows(f.GetSheetName(0))
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import "testing"
+
+func TestParseImportAmount(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{in: "1234567", want: 1234567},
+		{in: "1234567.5", want: 1234567.5},
+		{in: "Rp 1.234.567", want: 1234567},
+		{in: "Rp1.234.567,50", want: 1234567.5},
+		{in: "-Rp 2.500", want: -2500},
+		{in: "1.234", want: 1234},
+		{in: "1,234,567.89", want: 1234567.89},
+		{in: "1,234", want: 1234},
+		{in: "1234,5", want: 1234.5},
+		{in: "12.50", want: 12.5},
+		{in: " 750000 ", want: 750000},
+		{in: "1.23.456", wantErr: true},
+		{in: "1.2345.678", wantErr: true},
+		{in: "1,234.567.8", wantErr: true},
+		{in: "12,", wantErr: true},
+		{in: "abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseImportAmount(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseImportAmount(%q) = %v, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseImportAmount(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseImportAmount(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+// formatRupiah → parseImportAmount harus kembali ke nilai semula (export → import)
+func TestParseImportAmountRoundTrip(t *testing.T) {
+	for _, v := range []float64{0, 5, 999, 1000, 1234567, 1000000000, -45000} {
+		got, err := parseImportAmount(formatRupiah(v))
+		if err != nil {
+			t.Errorf("parseImportAmount(formatRupiah(%v)) error: %v", v, err)
+			continue
+		}
+		if got != v {
+			t.Errorf("parseImportAmount(formatRupiah(%v)) = %v", v, got)
+		}
+	}
+}
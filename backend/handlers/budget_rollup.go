@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"sales-system-backend/database"
+	"sales-system-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ======================================================
+// BUDGET ROLLUP (month / quarter / ytd)
+// GET /api/budgets/rollup?year=2025&granularity=quarter&division=
+// ======================================================
+
+func GetBudgetRollup(c *gin.Context) {
+	role := c.GetString("role")
+	userDiv := NormalizeDivision(c.GetString("division"))
+
+	yearStr := strings.TrimSpace(c.Query("year"))
+	if yearStr == "" {
+		c.JSON(400, gin.H{"error": "year is required"})
+		return
+	}
+	year, err := strconv.Atoi(yearStr)
+	if err != nil || year < 2000 || year > 2100 {
+		c.JSON(400, gin.H{"error": "invalid year"})
+		return
+	}
+
+	granularity := strings.ToLower(strings.TrimSpace(c.DefaultQuery("granularity", "month")))
+	if granularity != "month" && granularity != "quarter" && granularity != "ytd" {
+		c.JSON(400, gin.H{"error": "granularity must be month, quarter or ytd"})
+		return
+	}
+
+	division := NormalizeDivision(strings.TrimSpace(c.Query("division")))
+	if role == "user" {
+		division = userDiv
+	}
+	if strings.ToUpper(division) == "ALL" {
+		division = ""
+	}
+	if division != "" && !isValidDivision(division) {
+		c.JSON(400, gin.H{"error": "invalid division"})
+		return
+	}
+
+	// YTD: tahun berjalan dipotong sampai bulan ini, tahun lampau full 12 bulan
+	throughMonth := 12
+	now := time.Now().In(mustLoadLocation("Asia/Jakarta"))
+	if year == now.Year() {
+		throughMonth = int(now.Month())
+	} else if year > now.Year() {
+		throughMonth = 0
+	}
+
+	// ambil tahun ini + tahun sebelumnya sekaligus
+	start := time.Date(year-1, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(year+1, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	rows, err := database.Pool.Query(
+		c,
+		`
+		SELECT
+			b.division,
+			EXTRACT(YEAR FROM b.month)::int  AS y,
+			EXTRACT(MONTH FROM b.month)::int AS m,
+			COALESCE(SUM(b.budget_amount), 0)::float8 AS budget,
+			COALESCE(SUM(r.total), 0)::float8         AS realization
+		FROM budgets b
+		LEFT JOIN (
+			SELECT budget_id, SUM(amount) AS total
+			FROM budget_realization
+			GROUP BY budget_id
+		) r ON r.budget_id = b.id
+		WHERE b.month >= $1 AND b.month < $2
+		  AND ($3 = '' OR b.division = $3)
+		GROUP BY b.division, y, m
+		`,
+		start, end, division,
+	)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	// acc[division][period] = row
+	acc := map[string]map[string]*models.BudgetRollupRow{}
+
+	for rows.Next() {
+		var (
+			div          string
+			y, m         int
+			budget, real float64
+		)
+		if err := rows.Scan(&div, &y, &m, &budget, &real); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		period, ok := rollupPeriod(granularity, year, m, throughMonth)
+		if !ok {
+			continue
+		}
+
+		div = NormalizeDivision(div)
+		if acc[div] == nil {
+			acc[div] = map[string]*models.BudgetRollupRow{}
+		}
+		row := acc[div][period]
+		if row == nil {
+			row = &models.BudgetRollupRow{Period: period}
+			acc[div][period] = row
+		}
+
+		if y == year {
+			row.Budget += budget
+			row.Realization += real
+		} else {
+			row.PriorBudget += budget
+			row.PriorRealization += real
+		}
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	periods := rollupPeriods(granularity, year)
+
+	divisions := make([]string, 0, len(acc))
+	for d := range acc {
+		divisions = append(divisions, d)
+	}
+	sort.Strings(divisions)
+
+	resp := models.BudgetRollupResponse{
+		Year:         year,
+		Granularity:  granularity,
+		ThroughMonth: throughMonth,
+		Divisions:    []models.BudgetRollupDivision{},
+		GrandTotal:   models.BudgetRollupRow{Period: "TOTAL"},
+	}
+
+	totals := map[string]*models.BudgetRollupRow{}
+	for _, p := range periods {
+		totals[p] = &models.BudgetRollupRow{Period: p}
+	}
+
+	for _, d := range divisions {
+		item := models.BudgetRollupDivision{
+			Division: d,
+			Subtotal: models.BudgetRollupRow{Period: "SUBTOTAL"},
+		}
+
+		for _, p := range periods {
+			row := models.BudgetRollupRow{Period: p}
+			if r := acc[d][p]; r != nil {
+				row = *r
+			}
+			finalizeRollupRow(&row)
+			item.Periods = append(item.Periods, row)
+
+			addRollupRow(&item.Subtotal, row)
+			addRollupRow(totals[p], row)
+		}
+
+		finalizeRollupRow(&item.Subtotal)
+		addRollupRow(&resp.GrandTotal, item.Subtotal)
+		resp.Divisions = append(resp.Divisions, item)
+	}
+
+	for _, p := range periods {
+		finalizeRollupRow(totals[p])
+		resp.Totals = append(resp.Totals, *totals[p])
+	}
+	finalizeRollupRow(&resp.GrandTotal)
+
+	c.JSON(200, resp)
+}
+
+// rollupPeriod memetakan bulan (1..12) ke label periode.
+// ok=false kalau bulan tidak masuk periode (mis. setelah cutoff YTD).
+func rollupPeriod(granularity string, year, month, throughMonth int) (string, bool) {
+	switch granularity {
+	case "quarter":
+		return fmt.Sprintf("%d-Q%d", year, (month-1)/3+1), true
+	case "ytd":
+		if month > throughMonth {
+			return "", false
+		}
+		return fmt.Sprintf("%d-YTD", year), true
+	default:
+		return fmt.Sprintf("%d-%02d", year, month), true
+	}
+}
+
+func rollupPeriods(granularity string, year int) []string {
+	switch granularity {
+	case "quarter":
+		return []string{
+			fmt.Sprintf("%d-Q1", year), fmt.Sprintf("%d-Q2", year),
+			fmt.Sprintf("%d-Q3", year), fmt.Sprintf("%d-Q4", year),
+		}
+	case "ytd":
+		return []string{fmt.Sprintf("%d-YTD", year)}
+	default:
+		out := make([]string, 0, 12)
+		for m := 1; m <= 12; m++ {
+			out = append(out, fmt.Sprintf("%d-%02d", year, m))
+		}
+		return out
+	}
+}
+
+func addRollupRow(dst *models.BudgetRollupRow, src models.BudgetRollupRow) {
+	dst.Budget += src.Budget
+	dst.Realization += src.Realization
+	dst.PriorBudget += src.PriorBudget
+	dst.PriorRealization += src.PriorRealization
+}
+
+func finalizeRollupRow(r *models.BudgetRollupRow) {
+	r.Remaining = r.Budget - r.Realization
+	r.Achievement = pct(r.Realization, r.Budget)
+	r.PriorAchievement = pct(r.PriorRealization, r.PriorBudget)
+	if r.PriorRealization != 0 {
+		r.RealizationGrowth = ((r.Realization - r.PriorRealization) / r.PriorRealization) * 100
+	} else {
+		r.RealizationGrowth = 0
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"sales-system-backend/models"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 )
 
 // ======================================================
@@ -115,13 +116,14 @@ func AddRealization(c *gin.Context) {
 	_, err = database.Pool.Exec(
 		c,
 		`
-		INSERT INTO budget_realization (budget_id, category, amount, note)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO budget_realization (budget_id, category, amount, note, created_by)
+		VALUES ($1, $2, $3, $4, NULLIF($5::bigint, 0))
 		`,
 		budgetID,
 		req.Category,
 		req.Amount,
 		req.Note,
+		c.GetInt64("user_id"),
 	)
 
 	if err != nil {
@@ -129,6 +131,8 @@ func AddRealization(c *gin.Context) {
 		return
 	}
 
+	runBudgetThresholdCheck(c, budgetID)
+
 	c.JSON(201, gin.H{"status": "created"})
 }
 
@@ -137,7 +141,11 @@ func AddRealization(c *gin.Context) {
 // ======================================================
 
 func UpdateBudget(c *gin.Context) {
-	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	id, err := strconv.ParseInt(c.Param("budgetId"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid budget id"})
+		return
+	}
 
 	var req models.UpdateBudgetRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -145,6 +153,15 @@ func UpdateBudget(c *gin.Context) {
 		return
 	}
 
+	if req.BudgetAmount < 0 {
+		c.JSON(400, gin.H{"error": "budget_amount must be >= 0"})
+		return
+	}
+
+	if _, ok := checkBudgetAccess(c, id); !ok {
+		return
+	}
+
 	// cek kalau realisasi sudah lebih besar dari budget baru
 	totalReal, _ := GetTotalRealization(c, id)
 	if req.BudgetAmount < totalReal {
@@ -155,7 +172,7 @@ func UpdateBudget(c *gin.Context) {
 		return
 	}
 
-	_, err := database.Pool.Exec(
+	_, err = database.Pool.Exec(
 		c,
 		`UPDATE budgets
 		 SET budget_amount=$1, updated_at=NOW()
@@ -168,9 +185,361 @@ func UpdateBudget(c *gin.Context) {
 		return
 	}
 
+	// achievement berubah kalau amount berubah
+	runBudgetThresholdCheck(c, id)
+
 	c.JSON(200, gin.H{"status": "ok"})
 }
 
+// ======================================================
+// DELETE BUDGET
+// - ditolak kalau masih ada realisasi, kecuali ?force=true (admin)
+// ======================================================
+
+func DeleteBudget(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("budgetId"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid budget id"})
+		return
+	}
+
+	if _, ok := checkBudgetAccess(c, id); !ok {
+		return
+	}
+
+	force := c.Query("force") == "true"
+	if force && c.GetString("role") != "admin" {
+		c.JSON(403, gin.H{"error": "force delete is admin only"})
+		return
+	}
+
+	var (
+		realCount int64
+		realTotal float64
+	)
+	err = database.Pool.QueryRow(
+		c,
+		`SELECT COUNT(*), COALESCE(SUM(amount), 0)
+		 FROM budget_realization
+		 WHERE budget_id = $1`,
+		id,
+	).Scan(&realCount, &realTotal)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if realCount > 0 && !force {
+		c.JSON(409, gin.H{
+			"error":             "budget has realizations, use force=true to delete anyway",
+			"realization_count": realCount,
+			"total_realization": realTotal,
+		})
+		return
+	}
+
+	// budget_realization ikut terhapus (ON DELETE CASCADE)
+	tag, err := database.Pool.Exec(c, `DELETE FROM budgets WHERE id = $1`, id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if tag.RowsAffected() == 0 {
+		c.JSON(404, gin.H{"error": "budget not found"})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"status":               "deleted",
+		"realizations_deleted": realCount,
+	})
+}
+
+// ======================================================
+// COPY BUDGET KE RANGE BULAN
+// ======================================================
+
+func CopyBudget(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("budgetId"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid budget id"})
+		return
+	}
+
+	var req models.CopyBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "invalid body"})
+		return
+	}
+
+	startMonth, err := time.Parse("2006-01", req.StartMonth)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid start_month format"})
+		return
+	}
+	endMonth, err := time.Parse("2006-01", req.EndMonth)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid end_month format"})
+		return
+	}
+	if endMonth.Before(startMonth) {
+		c.JSON(400, gin.H{"error": "end_month must be >= start_month"})
+		return
+	}
+	if endMonth.After(startMonth.AddDate(0, 35, 0)) {
+		c.JSON(400, gin.H{"error": "range is limited to 36 months"})
+		return
+	}
+
+	src, ok := checkBudgetAccess(c, id)
+	if !ok {
+		return
+	}
+
+	tx, err := database.Pool.Begin(c)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(c)
+
+	type skipped struct {
+		Month  string `json:"month"`
+		Reason string `json:"reason"`
+	}
+
+	created := []string{}
+	updated := []string{}
+	skips := []skipped{}
+
+	for m := startMonth; !m.After(endMonth); m = m.AddDate(0, 1, 0) {
+		label := m.Format("2006-01")
+
+		if m.Equal(src.Month) {
+			skips = append(skips, skipped{Month: label, Reason: "source month"})
+			continue
+		}
+
+		var (
+			existingID int64
+			totalReal  float64
+		)
+		err := tx.QueryRow(c, `
+			SELECT b.id, COALESCE(SUM(br.amount), 0)
+			FROM budgets b
+			LEFT JOIN budget_realization br ON br.budget_id = b.id
+			WHERE b.division = $1 AND b.month = $2
+			GROUP BY b.id
+		`, src.Division, m).Scan(&existingID, &totalReal)
+
+		if err == nil {
+			if !req.Overwrite {
+				skips = append(skips, skipped{Month: label, Reason: "budget already exists"})
+				continue
+			}
+			if src.BudgetAmount < totalReal {
+				skips = append(skips, skipped{Month: label, Reason: "amount less than existing realization"})
+				continue
+			}
+			if _, err := tx.Exec(c, `
+				UPDATE budgets SET budget_amount = $1, updated_at = NOW() WHERE id = $2
+			`, src.BudgetAmount, existingID); err != nil {
+				c.JSON(500, gin.H{"error": err.Error()})
+				return
+			}
+			updated = append(updated, label)
+			continue
+		}
+		if err != pgx.ErrNoRows {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		if _, err := tx.Exec(c, `
+			INSERT INTO budgets (division, month, budget_amount)
+			VALUES ($1, $2, $3)
+		`, src.Division, m, src.BudgetAmount); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		created = append(created, label)
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"created": created,
+		"updated": updated,
+		"skipped": skips,
+	})
+}
+
+// ======================================================
+// BULK UPDATE AMOUNT (division + year, 1 transaksi)
+// ======================================================
+
+func BulkUpdateBudgets(c *gin.Context) {
+	var req models.BulkUpdateBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "invalid body"})
+		return
+	}
+
+	role := c.GetString("role")
+	userDiv := NormalizeDivision(c.GetString("division"))
+
+	if role == "user" {
+		req.Division = userDiv
+	}
+	req.Division = NormalizeDivision(req.Division)
+
+	if !isValidDivision(req.Division) {
+		c.JSON(400, gin.H{"error": "invalid division"})
+		return
+	}
+	if req.Year < 2000 || req.Year > 2100 {
+		c.JSON(400, gin.H{"error": "invalid year"})
+		return
+	}
+	if len(req.Items) == 0 {
+		c.JSON(400, gin.H{"error": "items is required"})
+		return
+	}
+
+	// validasi format dulu sebelum buka transaksi
+	months := make([]time.Time, len(req.Items))
+	seen := map[string]bool{}
+	for i, it := range req.Items {
+		m, err := time.Parse("2006-01", it.Month)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid month format: " + it.Month})
+			return
+		}
+		if m.Year() != req.Year {
+			c.JSON(400, gin.H{"error": "month " + it.Month + " is outside year"})
+			return
+		}
+		if seen[it.Month] {
+			c.JSON(400, gin.H{"error": "duplicate month: " + it.Month})
+			return
+		}
+		if it.BudgetAmount < 0 {
+			c.JSON(400, gin.H{"error": "budget_amount must be >= 0 (" + it.Month + ")"})
+			return
+		}
+		seen[it.Month] = true
+		months[i] = m
+	}
+
+	tx, err := database.Pool.Begin(c)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(c)
+
+	type itemError struct {
+		Month            string  `json:"month"`
+		Error            string  `json:"error"`
+		TotalRealization float64 `json:"total_realization"`
+	}
+	var errs []itemError
+
+	var budgetIDs []int64
+	for i, it := range req.Items {
+		var totalReal float64
+		err := tx.QueryRow(c, `
+			SELECT COALESCE(SUM(br.amount), 0)
+			FROM budgets b
+			JOIN budget_realization br ON br.budget_id = b.id
+			WHERE b.division = $1 AND b.month = $2
+		`, req.Division, months[i]).Scan(&totalReal)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		if it.BudgetAmount < totalReal {
+			errs = append(errs, itemError{
+				Month:            it.Month,
+				Error:            "Budget amount cannot be less than current total realization",
+				TotalRealization: totalReal,
+			})
+			continue
+		}
+
+		var id int64
+		err = tx.QueryRow(c, `
+			INSERT INTO budgets (division, month, budget_amount)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (division, month)
+			DO UPDATE SET budget_amount = EXCLUDED.budget_amount, updated_at = NOW()
+			RETURNING id
+		`, req.Division, months[i], it.BudgetAmount).Scan(&id)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		budgetIDs = append(budgetIDs, id)
+	}
+
+	// semua atau tidak sama sekali
+	if len(errs) > 0 {
+		c.JSON(400, gin.H{"error": "bulk update rejected", "items": errs})
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	for _, id := range budgetIDs {
+		runBudgetThresholdCheck(c, id)
+	}
+
+	c.JSON(200, gin.H{"status": "ok", "updated": len(budgetIDs)})
+}
+
+// ======================================================
+// ACL HELPER
+// ======================================================
+
+type budgetRef struct {
+	Division     string
+	Month        time.Time
+	BudgetAmount float64
+}
+
+// checkBudgetAccess load budget + cek divisi user.
+// Kalau gagal, response sudah dikirim dan ok=false.
+func checkBudgetAccess(c *gin.Context, budgetID int64) (budgetRef, bool) {
+	var b budgetRef
+	err := database.Pool.QueryRow(
+		c,
+		`SELECT division, month, budget_amount FROM budgets WHERE id=$1`,
+		budgetID,
+	).Scan(&b.Division, &b.Month, &b.BudgetAmount)
+
+	if err != nil {
+		c.JSON(404, gin.H{"error": "budget not found"})
+		return b, false
+	}
+
+	b.Division = NormalizeDivision(b.Division)
+
+	if c.GetString("role") == "user" && b.Division != NormalizeDivision(c.GetString("division")) {
+		c.JSON(403, gin.H{"error": "forbidden"})
+		return b, false
+	}
+
+	return b, true
+}
+
 // ======================================================
 // LIST BUDGETS (ACL APPLIED)
 // ======================================================
@@ -398,6 +767,8 @@ func UpdateRealization(c *gin.Context) {
 		return
 	}
 
+	runBudgetThresholdCheck(c, budgetID)
+
 	c.JSON(200, gin.H{"status": "ok"})
 }
 
@@ -447,6 +818,9 @@ func DeleteRealization(c *gin.Context) {
 		return
 	}
 
+	// re-arm threshold kalau achievement turun
+	runBudgetThresholdCheck(c, budgetID)
+
 	c.JSON(200, gin.H{"status": "deleted"})
 }
 
@@ -478,8 +852,11 @@ func GetBudgetTrend(c *gin.Context) {
 		division = userDiv
 	}
 
-	// Admin boleh ALL, tapi FE selalu kirim division spesifik
-	if division == "" || strings.ToUpper(division) == "ALL" {
+	// Admin boleh ALL → agregat semua divisi per bulan
+	if strings.ToUpper(division) == "ALL" {
+		division = ""
+	}
+	if role == "user" && division == "" {
 		c.JSON(400, gin.H{"error": "division is required"})
 		return
 	}
@@ -493,13 +870,17 @@ func GetBudgetTrend(c *gin.Context) {
 		`
 		SELECT
 			to_char(b.month, 'YYYY-MM') AS month,
-			b.budget_amount,
-			COALESCE(SUM(br.amount), 0) AS realization
+			COALESCE(SUM(b.budget_amount), 0) AS budget,
+			COALESCE(SUM(r.total), 0) AS realization
 		FROM budgets b
-		LEFT JOIN budget_realization br ON br.budget_id = b.id
-		WHERE b.division = $1
+		LEFT JOIN (
+			SELECT budget_id, SUM(amount) AS total
+			FROM budget_realization
+			GROUP BY budget_id
+		) r ON r.budget_id = b.id
+		WHERE ($1 = '' OR b.division = $1)
 		  AND b.month BETWEEN $2 AND $3
-		GROUP BY b.id, b.month, b.budget_amount
+		GROUP BY b.month
 		ORDER BY b.month ASC
 		`,
 		division, start, end,
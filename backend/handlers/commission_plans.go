@@ -0,0 +1,334 @@
+package handlers
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sales-system-backend/database"
+	"sales-system-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+func loadCommissionPlan(ctx context.Context, db dbtx, id int64) (models.CommissionPlan, error) {
+	var p models.CommissionPlan
+	err := db.QueryRow(ctx, `
+		SELECT id, name, period_type,
+		       rate_project_based::float8, rate_recurring::float8, rate_new_recurring::float8,
+		       accelerator_threshold::float8, accelerator_multiplier::float8,
+		       is_active, created_at, updated_at
+		FROM commission_plans WHERE id = $1
+	`, id).Scan(
+		&p.ID, &p.Name, &p.PeriodType,
+		&p.RateProjectBased, &p.RateRecurring, &p.RateNewRecurring,
+		&p.AcceleratorThreshold, &p.AcceleratorMultiplier,
+		&p.IsActive, &p.CreatedAt, &p.UpdatedAt,
+	)
+	if err != nil {
+		return p, err
+	}
+
+	p.Tiers, err = loadCommissionTiers(ctx, db, id)
+	return p, err
+}
+
+func loadCommissionTiers(ctx context.Context, db dbtx, planID int64) ([]models.CommissionTier, error) {
+	rows, err := db.Query(ctx, `
+		SELECT min_attainment_pct::float8, multiplier::float8
+		FROM commission_plan_tiers
+		WHERE plan_id = $1
+		ORDER BY min_attainment_pct ASC
+	`, planID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tiers := []models.CommissionTier{}
+	for rows.Next() {
+		var t models.CommissionTier
+		if err := rows.Scan(&t.MinAttainmentPct, &t.Multiplier); err != nil {
+			return nil, err
+		}
+		tiers = append(tiers, t)
+	}
+	return tiers, rows.Err()
+}
+
+// validateCommissionPlan → isi default + cek range; "" = valid
+func validateCommissionPlan(req *models.CommissionPlanRequest) string {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return "name is required"
+	}
+
+	req.PeriodType = strings.ToLower(strings.TrimSpace(req.PeriodType))
+	if req.PeriodType == "" {
+		req.PeriodType = "quarter"
+	}
+	if _, ok := periodRank[req.PeriodType]; !ok {
+		return "period_type must be month, quarter or year"
+	}
+
+	if req.RateProjectBased < 0 || req.RateRecurring < 0 || req.RateNewRecurring < 0 {
+		return "rates must be >= 0"
+	}
+
+	if req.AcceleratorThreshold == 0 {
+		req.AcceleratorThreshold = 100
+	}
+	if req.AcceleratorMultiplier == 0 {
+		req.AcceleratorMultiplier = 1
+	}
+	if req.AcceleratorThreshold < 0 || req.AcceleratorMultiplier < 1 {
+		return "accelerator_threshold must be > 0 and accelerator_multiplier >= 1"
+	}
+
+	seen := map[float64]bool{}
+	for _, t := range req.Tiers {
+		if t.MinAttainmentPct < 0 || t.Multiplier < 0 {
+			return "tier values must be >= 0"
+		}
+		if seen[t.MinAttainmentPct] {
+			return "duplicate tier min_attainment_pct"
+		}
+		seen[t.MinAttainmentPct] = true
+	}
+	sort.Slice(req.Tiers, func(i, j int) bool {
+		return req.Tiers[i].MinAttainmentPct < req.Tiers[j].MinAttainmentPct
+	})
+
+	return ""
+}
+
+func replaceCommissionTiersTx(ctx context.Context, tx pgx.Tx, planID int64, tiers []models.CommissionTier) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM commission_plan_tiers WHERE plan_id = $1`, planID); err != nil {
+		return err
+	}
+	for _, t := range tiers {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO commission_plan_tiers (plan_id, min_attainment_pct, multiplier)
+			VALUES ($1, $2, $3)
+		`, planID, t.MinAttainmentPct, t.Multiplier); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ======================================================
+// LIST PLANS — GET /commissions/plans
+// ======================================================
+
+func ListCommissionPlans(c *gin.Context) {
+	rows, err := database.Pool.Query(c, `SELECT id FROM commission_plans ORDER BY is_active DESC, name ASC`)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	list := []models.CommissionPlan{}
+	for _, id := range ids {
+		p, err := loadCommissionPlan(c, database.Pool, id)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		list = append(list, p)
+	}
+
+	c.JSON(200, list)
+}
+
+// ======================================================
+// CREATE PLAN (ADMIN) — POST /commissions/plans
+// ======================================================
+
+func CreateCommissionPlan(c *gin.Context) {
+	var req models.CommissionPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "invalid body"})
+		return
+	}
+	if msg := validateCommissionPlan(&req); msg != "" {
+		c.JSON(400, gin.H{"error": msg})
+		return
+	}
+
+	active := true
+	if req.IsActive != nil {
+		active = *req.IsActive
+	}
+
+	ctx := c.Request.Context()
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var id int64
+	err = tx.QueryRow(ctx, `
+		INSERT INTO commission_plans
+			(name, period_type, rate_project_based, rate_recurring, rate_new_recurring,
+			 accelerator_threshold, accelerator_multiplier, is_active, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9::bigint, 0))
+		ON CONFLICT (name) DO NOTHING
+		RETURNING id
+	`, req.Name, req.PeriodType, req.RateProjectBased, req.RateRecurring, req.RateNewRecurring,
+		req.AcceleratorThreshold, req.AcceleratorMultiplier, active, c.GetInt64("user_id"),
+	).Scan(&id)
+	if err == pgx.ErrNoRows {
+		c.JSON(409, gin.H{"error": "commission plan name already exists"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := replaceCommissionTiersTx(ctx, tx, id, req.Tiers); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	c.JSON(201, gin.H{"id": id})
+}
+
+// ======================================================
+// UPDATE PLAN (ADMIN) — PUT /commissions/plans/:id
+// tiers di-replace; run yang sudah ada tidak dihitung ulang otomatis
+// ======================================================
+
+func UpdateCommissionPlan(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid plan id"})
+		return
+	}
+
+	var req models.CommissionPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "invalid body"})
+		return
+	}
+	if msg := validateCommissionPlan(&req); msg != "" {
+		c.JSON(400, gin.H{"error": msg})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// period_type tidak boleh berubah kalau sudah ada run
+	var currentType string
+	var hasRuns bool
+	err = tx.QueryRow(ctx, `
+		SELECT period_type, EXISTS(SELECT 1 FROM commission_runs WHERE plan_id = $1)
+		FROM commission_plans WHERE id = $1
+	`, id).Scan(&currentType, &hasRuns)
+	if err == pgx.ErrNoRows {
+		c.JSON(404, gin.H{"error": "commission plan not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if hasRuns && currentType != req.PeriodType {
+		c.JSON(409, gin.H{"error": "cannot change period_type of a plan that already has runs"})
+		return
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE commission_plans SET
+			name = $1, period_type = $2,
+			rate_project_based = $3, rate_recurring = $4, rate_new_recurring = $5,
+			accelerator_threshold = $6, accelerator_multiplier = $7,
+			is_active = COALESCE($8, is_active),
+			updated_at = NOW()
+		WHERE id = $9
+	`, req.Name, req.PeriodType, req.RateProjectBased, req.RateRecurring, req.RateNewRecurring,
+		req.AcceleratorThreshold, req.AcceleratorMultiplier, req.IsActive, id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := replaceCommissionTiersTx(ctx, tx, id, req.Tiers); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "updated"})
+}
+
+// ======================================================
+// DELETE PLAN (ADMIN) — DELETE /commissions/plans/:id
+// plan yang sudah punya run → nonaktifkan saja
+// ======================================================
+
+func DeleteCommissionPlan(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid plan id"})
+		return
+	}
+
+	var hasRuns bool
+	if err := database.Pool.QueryRow(c,
+		`SELECT EXISTS(SELECT 1 FROM commission_runs WHERE plan_id = $1)`, id,
+	).Scan(&hasRuns); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if hasRuns {
+		c.JSON(409, gin.H{"error": "plan has commission runs; set is_active=false instead"})
+		return
+	}
+
+	tag, err := database.Pool.Exec(c, `DELETE FROM commission_plans WHERE id = $1`, id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(404, gin.H{"error": "commission plan not found"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "deleted"})
+}
@@ -0,0 +1,685 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"sales-system-backend/database"
+	"sales-system-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// rate plan (persen) berdasarkan project_type
+func commissionRate(p models.CommissionPlan, projectType string) float64 {
+	switch projectType {
+	case "Project Based":
+		return p.RateProjectBased
+	case "Recurring":
+		return p.RateRecurring
+	case "New Recurring":
+		return p.RateNewRecurring
+	default:
+		return 0
+	}
+}
+
+// tier tertinggi yang min_attainment_pct-nya terlewati; plan tanpa tier → 1
+func commissionTierMultiplier(tiers []models.CommissionTier, attainment float64) float64 {
+	if len(tiers) == 0 {
+		return 1
+	}
+	mult := 0.0
+	for _, t := range tiers { // sudah urut ASC
+		if attainment >= t.MinAttainmentPct {
+			mult = t.Multiplier
+		}
+	}
+	return mult
+}
+
+// commissionLineAmount → bagian realisasi yang kena accelerator (porsi kumulatif di atas
+// accelFrom) + komisi line (dibulatkan 2 desimal). cumulative = realisasi sebelum line ini.
+func commissionLineAmount(realization, cumulative, accelFrom, rate, tierMult, accelMult float64) (float64, float64) {
+	accelerated := math.Max(0, math.Min(realization, cumulative+realization-accelFrom))
+	base := (realization - accelerated) + accelerated*accelMult
+	return accelerated, round2(base * rate / 100 * tierMult)
+}
+
+type commissionLineSrc struct {
+	ProjectID   int64
+	Month       time.Time
+	ProjectType string
+	Realization float64
+}
+
+// calculateCommissionRunTx → hitung ulang seluruh statement run dari realisasi saat ini.
+// Realisasi dikredit ke projects.owner_user_id. User tanpa quota user pada period
+// tidak kena tier / accelerator (multiplier 1).
+// Return: user yang nominal komisinya berubah (old → new).
+func calculateCommissionRunTx(ctx context.Context, tx pgx.Tx, runID int64) (map[int64][2]float64, error) {
+	var planID int64
+	var periodType, status string
+	var start, end time.Time
+	err := tx.QueryRow(ctx, `
+		SELECT plan_id, period_type, period_start, period_end, status
+		FROM commission_runs WHERE id = $1 FOR UPDATE
+	`, runID).Scan(&planID, &periodType, &start, &end, &status)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := loadCommissionPlan(ctx, tx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	quotas, err := loadQuotaTotals(ctx, tx, periodType, start, end, "all")
+	if err != nil {
+		return nil, err
+	}
+
+	// nominal sebelumnya (closed_amount dipertahankan lintas recalculation)
+	prevAmount := map[int64]float64{}
+	closedAmount := map[int64]*float64{}
+	rows, err := tx.Query(ctx, `
+		SELECT user_id, commission_amount::float8, closed_amount::float8
+		FROM commission_statements WHERE run_id = $1
+	`, runID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var uid int64
+		var amount float64
+		var closed *float64
+		if err := rows.Scan(&uid, &amount, &closed); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		prevAmount[uid] = amount
+		closedAmount[uid] = closed
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// realisasi per owner, urut kronologis (untuk accelerator kumulatif)
+	rows, err = tx.Query(ctx, `
+		SELECT p.owner_user_id, p.id, rp.month, p.project_type, rp.target_realization::float8
+		FROM project_revenue_plan rp
+		JOIN projects p ON p.id = rp.project_id
+		WHERE rp.month >= $1 AND rp.month < $2
+		  AND rp.target_realization > 0
+		  AND p.owner_user_id IS NOT NULL
+		ORDER BY p.owner_user_id, rp.month, p.id
+	`, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	owners := []int64{}
+	linesByOwner := map[int64][]commissionLineSrc{}
+	for rows.Next() {
+		var uid int64
+		var l commissionLineSrc
+		if err := rows.Scan(&uid, &l.ProjectID, &l.Month, &l.ProjectType, &l.Realization); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if _, ok := linesByOwner[uid]; !ok {
+			owners = append(owners, uid)
+		}
+		linesByOwner[uid] = append(linesByOwner[uid], l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM commission_statements WHERE run_id = $1`, runID); err != nil {
+		return nil, err
+	}
+
+	changed := map[int64][2]float64{}
+	total := 0.0
+
+	for _, uid := range owners {
+		lines := linesByOwner[uid]
+
+		realization := 0.0
+		for _, l := range lines {
+			realization += l.Realization
+		}
+
+		quota, hasQuota := resolveQuota(quotas, "u:"+strconv.FormatInt(uid, 10), periodType)
+		attainment := 0.0
+		tierMult := 1.0
+		accelFrom := math.Inf(1) // realisasi kumulatif di atas ini kena accelerator
+		if hasQuota && quota > 0 {
+			attainment = pct(realization, quota)
+			tierMult = commissionTierMultiplier(plan.Tiers, attainment)
+			accelFrom = quota * plan.AcceleratorThreshold / 100
+		}
+
+		// owner baru setelah run di-close → adjustment dihitung dari 0
+		closed := closedAmount[uid]
+		if closed == nil && status == "closed" {
+			zero := 0.0
+			closed = &zero
+		}
+
+		var statementID int64
+		if err := tx.QueryRow(ctx, `
+			INSERT INTO commission_statements
+				(run_id, user_id, quota, realization, attainment_pct, tier_multiplier, closed_amount)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id
+		`, runID, uid, quota, realization, round2(attainment), tierMult, closed,
+		).Scan(&statementID); err != nil {
+			return nil, err
+		}
+
+		cumulative := 0.0
+		amount := 0.0
+		for _, l := range lines {
+			rate := commissionRate(plan, l.ProjectType)
+			accelerated, commission := commissionLineAmount(
+				l.Realization, cumulative, accelFrom, rate, tierMult, plan.AcceleratorMultiplier)
+			cumulative += l.Realization
+			amount += commission
+
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO commission_lines
+					(statement_id, project_id, month, revenue_type, realization, base_rate, accelerated_amount, commission)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			`, statementID, l.ProjectID, l.Month, l.ProjectType, l.Realization, rate, round2(accelerated), commission); err != nil {
+				return nil, err
+			}
+		}
+
+		amount = round2(amount)
+		if _, err := tx.Exec(ctx,
+			`UPDATE commission_statements SET commission_amount = $1 WHERE id = $2`, amount, statementID,
+		); err != nil {
+			return nil, err
+		}
+		total += amount
+
+		if prevAmount[uid] != amount {
+			changed[uid] = [2]float64{prevAmount[uid], amount}
+		}
+		delete(prevAmount, uid)
+	}
+
+	// owner yang sebelumnya dapat komisi tapi sekarang tidak ada realisasi
+	for uid, old := range prevAmount {
+		if old != 0 {
+			changed[uid] = [2]float64{old, 0}
+		}
+		if closedAmount[uid] != nil {
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO commission_statements (run_id, user_id, closed_amount)
+				VALUES ($1, $2, $3)
+			`, runID, uid, closedAmount[uid]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	recalcCol := "calculated_at"
+	if status == "closed" {
+		recalcCol = "recalculated_at"
+	}
+	if _, err := tx.Exec(ctx, fmt.Sprintf(
+		`UPDATE commission_runs SET total_commission = $1, %s = NOW() WHERE id = $2`, recalcCol,
+	), round2(total), runID); err != nil {
+		return nil, err
+	}
+
+	return changed, nil
+}
+
+// projectCommissionMonthsTx → bulan (YYYY-MM) yang memengaruhi komisi project:
+// bulan dengan realisasi + bulan yang sudah tercatat di commission_lines
+func projectCommissionMonthsTx(ctx context.Context, tx pgx.Tx, projectID int64) ([]string, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT to_char(month, 'YYYY-MM') FROM project_revenue_plan
+		WHERE project_id = $1 AND target_realization > 0
+		UNION
+		SELECT to_char(month, 'YYYY-MM') FROM commission_lines
+		WHERE project_id = $1
+	`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	months := []string{}
+	for rows.Next() {
+		var m string
+		if err := rows.Scan(&m); err != nil {
+			return nil, err
+		}
+		months = append(months, m)
+	}
+	return months, rows.Err()
+}
+
+// recalcClosedCommissionRunsTx dipanggil saat input komisi berubah (realisasi, owner,
+// project_type, hapus project); run closed yang mencakup salah satu bulan (YYYY-MM)
+// dihitung ulang dan owner yang berubah dinotifikasi. reason masuk ke pesan notifikasi.
+func recalcClosedCommissionRunsTx(ctx context.Context, tx pgx.Tx, reason string, months ...string) error {
+	rows, err := tx.Query(ctx, `
+		SELECT DISTINCT r.id, r.period_type, r.period_start
+		FROM commission_runs r
+		JOIN unnest($1::text[]) m ON to_date(m || '-01', 'YYYY-MM-DD') >= r.period_start
+		                         AND to_date(m || '-01', 'YYYY-MM-DD') < r.period_end
+		WHERE r.status = 'closed'
+		ORDER BY r.id
+	`, months)
+	if err != nil {
+		return err
+	}
+
+	type runRef struct {
+		ID     int64
+		Period string
+	}
+	runs := []runRef{}
+	for rows.Next() {
+		var r runRef
+		var pt string
+		var start time.Time
+		if err := rows.Scan(&r.ID, &pt, &start); err != nil {
+			rows.Close()
+			return err
+		}
+		r.Period = formatPeriod(pt, start)
+		runs = append(runs, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, r := range runs {
+		changed, err := calculateCommissionRunTx(ctx, tx, r.ID)
+		if err != nil {
+			return err
+		}
+		for uid, amounts := range changed {
+			if err := createNotification(ctx, tx, uid,
+				"commission_adjusted",
+				"Commission adjusted: "+r.Period,
+				fmt.Sprintf("Your commission for %s changed from %s to %s after %s.",
+					r.Period, formatRupiah(amounts[0]), formatRupiah(amounts[1]), reason),
+				"commission_run", r.ID,
+			); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ======================================================
+// RUN CALCULATION (ADMIN) — POST /commissions/runs {plan_id, period}
+// run draft untuk period yang sama dihitung ulang; run closed ditolak
+// ======================================================
+
+func RunCommissionCalculation(c *gin.Context) {
+	var req models.CommissionRunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "plan_id and period are required"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	var periodType string
+	var active bool
+	err := database.Pool.QueryRow(ctx,
+		`SELECT period_type, is_active FROM commission_plans WHERE id = $1`, req.PlanID,
+	).Scan(&periodType, &active)
+	if err != nil {
+		c.JSON(404, gin.H{"error": "commission plan not found"})
+		return
+	}
+	if !active {
+		c.JSON(400, gin.H{"error": "commission plan is inactive"})
+		return
+	}
+
+	start, end, err := parsePeriod(periodType, req.Period)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var runID int64
+	var status string
+	err = tx.QueryRow(ctx, `
+		INSERT INTO commission_runs (plan_id, period_type, period_start, period_end, calculated_by)
+		VALUES ($1, $2, $3, $4, NULLIF($5::bigint, 0))
+		ON CONFLICT (plan_id, period_type, period_start)
+		DO UPDATE SET calculated_by = EXCLUDED.calculated_by
+		RETURNING id, status
+	`, req.PlanID, periodType, start, end, c.GetInt64("user_id")).Scan(&runID, &status)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if status == "closed" {
+		c.JSON(409, gin.H{"error": "commission run is closed; it is recalculated automatically on realization changes", "run_id": runID})
+		return
+	}
+
+	if _, err := calculateCommissionRunTx(ctx, tx, runID); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	c.JSON(200, gin.H{"run_id": runID, "status": "calculated"})
+}
+
+// ======================================================
+// CLOSE RUN (ADMIN) — POST /commissions/runs/:id/close
+// ======================================================
+
+func CloseCommissionRun(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid run id"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	if err := tx.QueryRow(ctx,
+		`SELECT status FROM commission_runs WHERE id = $1 FOR UPDATE`, id,
+	).Scan(&status); err != nil {
+		c.JSON(404, gin.H{"error": "commission run not found"})
+		return
+	}
+	if status == "closed" {
+		c.JSON(409, gin.H{"error": "commission run already closed"})
+		return
+	}
+
+	// hitung ulang sekali lagi supaya snapshot = realisasi terbaru
+	if _, err := calculateCommissionRunTx(ctx, tx, id); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE commission_statements SET closed_amount = commission_amount WHERE run_id = $1
+	`, id); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE commission_runs
+		SET status = 'closed', closed_at = NOW(), closed_by = NULLIF($2::bigint, 0)
+		WHERE id = $1
+	`, id, c.GetInt64("user_id")); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "closed"})
+}
+
+// ======================================================
+// LIST RUNS — GET /commissions/runs?year=&plan_id=
+// ======================================================
+
+func ListCommissionRuns(c *gin.Context) {
+	conds := []string{"1=1"}
+	args := []any{}
+	i := 1
+
+	if y := strings.TrimSpace(c.Query("year")); y != "" {
+		year, err := strconv.Atoi(y)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid year"})
+			return
+		}
+		conds = append(conds, fmt.Sprintf("EXTRACT(YEAR FROM r.period_start)::int = $%d", i))
+		args = append(args, year)
+		i++
+	}
+
+	if v := strings.TrimSpace(c.Query("plan_id")); v != "" {
+		planID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid plan_id"})
+			return
+		}
+		conds = append(conds, fmt.Sprintf("r.plan_id = $%d", i))
+		args = append(args, planID)
+		i++
+	}
+
+	rows, err := database.Pool.Query(c, fmt.Sprintf(`
+		SELECT r.id, r.plan_id, pl.name, r.period_type, r.period_start, r.status,
+		       r.total_commission::float8, r.calculated_at, r.closed_at, r.recalculated_at
+		FROM commission_runs r
+		JOIN commission_plans pl ON pl.id = r.plan_id
+		WHERE %s
+		ORDER BY r.period_start DESC, pl.name ASC
+	`, strings.Join(conds, " AND ")), args...)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	list := []models.CommissionRun{}
+	for rows.Next() {
+		var r models.CommissionRun
+		var start time.Time
+		if err := rows.Scan(
+			&r.ID, &r.PlanID, &r.PlanName, &r.PeriodType, &start, &r.Status,
+			&r.TotalCommission, &r.CalculatedAt, &r.ClosedAt, &r.RecalculatedAt,
+		); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		r.Period = formatPeriod(r.PeriodType, start)
+		list = append(list, r)
+	}
+
+	c.JSON(200, list)
+}
+
+// ======================================================
+// RUN DETAIL — GET /commissions/runs/:id
+// role user hanya melihat statement miliknya sendiri
+// ======================================================
+
+func GetCommissionRun(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid run id"})
+		return
+	}
+
+	var r models.CommissionRun
+	var start time.Time
+	err = database.Pool.QueryRow(c, `
+		SELECT r.id, r.plan_id, pl.name, r.period_type, r.period_start, r.status,
+		       r.total_commission::float8, r.calculated_at, r.closed_at, r.recalculated_at
+		FROM commission_runs r
+		JOIN commission_plans pl ON pl.id = r.plan_id
+		WHERE r.id = $1
+	`, id).Scan(
+		&r.ID, &r.PlanID, &r.PlanName, &r.PeriodType, &start, &r.Status,
+		&r.TotalCommission, &r.CalculatedAt, &r.ClosedAt, &r.RecalculatedAt,
+	)
+	if err != nil {
+		c.JSON(404, gin.H{"error": "commission run not found"})
+		return
+	}
+	r.Period = formatPeriod(r.PeriodType, start)
+
+	var onlyUser int64
+	if c.GetString("role") == "user" {
+		onlyUser = c.GetInt64("user_id")
+	}
+
+	r.Statements, err = loadCommissionStatements(c, id, onlyUser, false)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, r)
+}
+
+// ======================================================
+// STATEMENT DETAIL (+ lines) — GET /commissions/runs/:id/statements/:userId
+// ======================================================
+
+func GetCommissionStatement(c *gin.Context) {
+	runID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid run id"})
+		return
+	}
+	userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	if c.GetString("role") == "user" && userID != c.GetInt64("user_id") {
+		c.JSON(403, gin.H{"error": "forbidden: cannot view another user's statement"})
+		return
+	}
+
+	list, err := loadCommissionStatements(c, runID, userID, true)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if len(list) == 0 {
+		c.JSON(404, gin.H{"error": "commission statement not found"})
+		return
+	}
+
+	c.JSON(200, list[0])
+}
+
+// loadCommissionStatements → statement run; userID > 0 = filter satu user
+func loadCommissionStatements(ctx context.Context, runID, userID int64, withLines bool) ([]models.CommissionStatement, error) {
+	rows, err := database.Pool.Query(ctx, `
+		SELECT s.id, s.run_id, s.user_id, u.username, u.division,
+		       s.quota::float8, s.realization::float8, s.attainment_pct::float8,
+		       s.tier_multiplier::float8, s.commission_amount::float8, s.closed_amount::float8
+		FROM commission_statements s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.run_id = $1 AND ($2::bigint = 0 OR s.user_id = $2)
+		ORDER BY s.commission_amount DESC, u.username ASC
+	`, runID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	list := []models.CommissionStatement{}
+	for rows.Next() {
+		var s models.CommissionStatement
+		if err := rows.Scan(
+			&s.ID, &s.RunID, &s.UserID, &s.Username, &s.Division,
+			&s.Quota, &s.Realization, &s.AttainmentPct,
+			&s.TierMultiplier, &s.CommissionAmount, &s.ClosedAmount,
+		); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		s.Division = NormalizeDivision(s.Division)
+		if s.ClosedAmount != nil {
+			s.Adjustment = round2(s.CommissionAmount - *s.ClosedAmount)
+		}
+		list = append(list, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if !withLines {
+		return list, nil
+	}
+
+	for i := range list {
+		lrows, err := database.Pool.Query(ctx, `
+			SELECT l.project_id, p.project_code, to_char(l.month, 'YYYY-MM'), l.revenue_type,
+			       l.realization::float8, l.base_rate::float8, l.accelerated_amount::float8, l.commission::float8
+			FROM commission_lines l
+			JOIN projects p ON p.id = l.project_id
+			WHERE l.statement_id = $1
+			ORDER BY l.month ASC, p.project_code ASC
+		`, list[i].ID)
+		if err != nil {
+			return nil, err
+		}
+
+		list[i].Lines = []models.CommissionLine{}
+		for lrows.Next() {
+			var l models.CommissionLine
+			if err := lrows.Scan(
+				&l.ProjectID, &l.ProjectCode, &l.Month, &l.RevenueType,
+				&l.Realization, &l.BaseRate, &l.AcceleratedAmount, &l.Commission,
+			); err != nil {
+				lrows.Close()
+				return nil, err
+			}
+			list[i].Lines = append(list[i].Lines, l)
+		}
+		lrows.Close()
+		if err := lrows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return list, nil
+}
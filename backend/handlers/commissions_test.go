@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"math"
+	"testing"
+
+	"sales-system-backend/models"
+)
+
+func TestCommissionTierMultiplier(t *testing.T) {
+	tiers := []models.CommissionTier{
+		{MinAttainmentPct: 50, Multiplier: 0.5},
+		{MinAttainmentPct: 80, Multiplier: 1},
+		{MinAttainmentPct: 100, Multiplier: 1.25},
+	}
+
+	tests := []struct {
+		name       string
+		tiers      []models.CommissionTier
+		attainment float64
+		want       float64
+	}{
+		{"no tiers", nil, 10, 1},
+		{"below first tier", tiers, 49.99, 0},
+		{"exactly first tier", tiers, 50, 0.5},
+		{"between tiers", tiers, 79.5, 0.5},
+		{"exactly middle tier", tiers, 80, 1},
+		{"top tier", tiers, 100, 1.25},
+		{"above top tier", tiers, 180, 1.25},
+		{"zero attainment", tiers, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commissionTierMultiplier(tt.tiers, tt.attainment); got != tt.want {
+				t.Errorf("commissionTierMultiplier(%v) = %v, want %v", tt.attainment, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommissionLineAmount(t *testing.T) {
+	tests := []struct {
+		name            string
+		realization     float64
+		cumulative      float64
+		accelFrom       float64
+		rate            float64
+		tierMult        float64
+		accelMult       float64
+		wantAccelerated float64
+		wantCommission  float64
+	}{
+		{
+			name:        "no quota, never accelerated",
+			realization: 1000, cumulative: 0, accelFrom: math.Inf(1),
+			rate: 2, tierMult: 1, accelMult: 1.5,
+			wantAccelerated: 0, wantCommission: 20,
+		},
+		{
+			name:        "fully below threshold",
+			realization: 400, cumulative: 100, accelFrom: 1000,
+			rate: 5, tierMult: 1, accelMult: 2,
+			wantAccelerated: 0, wantCommission: 20,
+		},
+		{
+			name:        "line crosses threshold",
+			realization: 500, cumulative: 800, accelFrom: 1000,
+			rate: 10, tierMult: 1, accelMult: 2,
+			// 200 normal + 300 * 2 = 800 → 10%
+			wantAccelerated: 300, wantCommission: 80,
+		},
+		{
+			name:        "line already above threshold",
+			realization: 250, cumulative: 1200, accelFrom: 1000,
+			rate: 10, tierMult: 1, accelMult: 2,
+			wantAccelerated: 250, wantCommission: 50,
+		},
+		{
+			name:        "line ends exactly at threshold",
+			realization: 200, cumulative: 800, accelFrom: 1000,
+			rate: 10, tierMult: 1, accelMult: 2,
+			wantAccelerated: 0, wantCommission: 20,
+		},
+		{
+			name:        "tier multiplier applied after accelerator",
+			realization: 500, cumulative: 800, accelFrom: 1000,
+			rate: 10, tierMult: 1.25, accelMult: 2,
+			wantAccelerated: 300, wantCommission: 100,
+		},
+		{
+			name:        "zero tier multiplier",
+			realization: 500, cumulative: 0, accelFrom: 1000,
+			rate: 10, tierMult: 0, accelMult: 2,
+			wantAccelerated: 0, wantCommission: 0,
+		},
+		{
+			name:        "commission rounded to 2 decimals",
+			realization: 333.33, cumulative: 0, accelFrom: math.Inf(1),
+			rate: 1.5, tierMult: 1, accelMult: 1,
+			// 4.99995 → 5.00
+			wantAccelerated: 0, wantCommission: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accelerated, commission := commissionLineAmount(
+				tt.realization, tt.cumulative, tt.accelFrom, tt.rate, tt.tierMult, tt.accelMult)
+			if math.Abs(accelerated-tt.wantAccelerated) > 1e-9 {
+				t.Errorf("accelerated = %v, want %v", accelerated, tt.wantAccelerated)
+			}
+			if commission != tt.wantCommission {
+				t.Errorf("commission = %v, want %v", commission, tt.wantCommission)
+			}
+		})
+	}
+}
+
+// total statement = jumlah komisi line yang sudah dibulatkan, lalu dibulatkan lagi
+func TestCommissionStatementTotal(t *testing.T) {
+	accelFrom := 1000.0
+	lines := []float64{333.33, 333.33, 333.34, 500}
+
+	cumulative, amount := 0.0, 0.0
+	for _, r := range lines {
+		_, commission := commissionLineAmount(r, cumulative, accelFrom, 3, 1, 1.5)
+		cumulative += r
+		amount += commission
+	}
+	amount = round2(amount)
+
+	// 10.00 + 10.00 + 10.00 + (500 * 1.5 * 3%) 22.50
+	if want := 52.5; amount != want {
+		t.Errorf("statement total = %v, want %v", amount, want)
+	}
+}
+
+func TestRound2(t *testing.T) {
+	tests := []struct {
+		in, want float64
+	}{
+		{0, 0},
+		{1.005, 1},
+		{1.235, 1.24},
+		{-2.345, -2.35},
+		{0.1 + 0.2, 0.3},
+		{123456789.129, 123456789.13},
+	}
+
+	for _, tt := range tests {
+		if got := round2(tt.in); got != tt.want {
+			t.Errorf("round2(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,591 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sales-system-backend/database"
+	"sales-system-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// record yang menunjuk ke kompetitor (hapus ditolak; nonaktifkan saja)
+var competitorDependents = []dependentRef{
+	{Type: "project_competitors", Table: "project_competitors", Column: "competitor_id", Label: "'project #' || project_id::text"},
+	{Type: "won_projects", Table: "projects", Column: "winning_competitor_id", Label: "project_code"},
+}
+
+// competitorUsable → kompetitor ada; yang nonaktif hanya boleh kalau sudah tercatat
+// di project ini (projectID 0 = project baru)
+func competitorUsable(ctx context.Context, db dbtx, projectID, competitorID int64) (bool, bool, error) {
+	var active, linked bool
+	err := db.QueryRow(ctx, `
+		SELECT co.is_active,
+		       EXISTS(SELECT 1 FROM project_competitors pc
+		              WHERE pc.project_id = $1 AND pc.competitor_id = co.id)
+		FROM competitors co
+		WHERE co.id = $2
+	`, projectID, competitorID).Scan(&active, &linked)
+	if err == pgx.ErrNoRows {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+	return true, active || linked, nil
+}
+
+// checkWinningCompetitorTx → "" = valid
+func checkWinningCompetitorTx(ctx context.Context, db dbtx, projectID, competitorID int64) (string, error) {
+	found, usable, err := competitorUsable(ctx, db, projectID, competitorID)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "winning competitor not found", nil
+	}
+	if !usable {
+		return "winning competitor is inactive", nil
+	}
+	return "", nil
+}
+
+// linkWinningCompetitorTx → pemenang otomatis masuk daftar kompetitor project
+func linkWinningCompetitorTx(ctx context.Context, db dbtx, projectID, competitorID int64) error {
+	_, err := db.Exec(ctx, `
+		INSERT INTO project_competitors (project_id, competitor_id)
+		VALUES ($1, $2)
+		ON CONFLICT (project_id, competitor_id) DO NOTHING
+	`, projectID, competitorID)
+	return err
+}
+
+func loadProjectCompetitors(ctx context.Context, db dbtx, projectID int64) ([]models.ProjectCompetitor, error) {
+	rows, err := db.Query(ctx, `
+		SELECT pc.competitor_id, co.name, pc.competitor_price::float8, pc.is_incumbent,
+		       COALESCE(p.winning_competitor_id = pc.competitor_id, false),
+		       pc.notes, pc.updated_at
+		FROM project_competitors pc
+		JOIN competitors co ON co.id = pc.competitor_id
+		JOIN projects p ON p.id = pc.project_id
+		WHERE pc.project_id = $1
+		ORDER BY pc.is_incumbent DESC, co.name ASC
+	`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.ProjectCompetitor{}
+	for rows.Next() {
+		var pc models.ProjectCompetitor
+		if err := rows.Scan(
+			&pc.CompetitorID, &pc.Name, &pc.CompetitorPrice, &pc.IsIncumbent,
+			&pc.IsWinner, &pc.Notes, &pc.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, pc)
+	}
+	return list, rows.Err()
+}
+
+// ======================================================
+// COMPETITORS — GET /competitors?q=&active=
+// ======================================================
+
+func ListCompetitors(c *gin.Context) {
+	conds := []string{"1=1"}
+	args := []any{}
+	i := 1
+
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		conds = append(conds, fmt.Sprintf("name ILIKE $%d", i))
+		args = append(args, "%"+q+"%")
+		i++
+	}
+	if v := strings.TrimSpace(c.Query("active")); v == "true" || v == "1" {
+		conds = append(conds, "is_active")
+	}
+
+	rows, err := database.Pool.Query(c, fmt.Sprintf(`
+		SELECT id, name, website, notes, is_active, created_at, updated_at
+		FROM competitors
+		WHERE %s
+		ORDER BY name ASC
+	`, strings.Join(conds, " AND ")), args...)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	list := []models.Competitor{}
+	for rows.Next() {
+		var co models.Competitor
+		if err := rows.Scan(
+			&co.ID, &co.Name, &co.Website, &co.Notes, &co.IsActive, &co.CreatedAt, &co.UpdatedAt,
+		); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		list = append(list, co)
+	}
+
+	c.JSON(200, list)
+}
+
+// validateCompetitor → trim; "" = valid
+func validateCompetitor(req *models.CompetitorRequest) string {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return "name is required"
+	}
+	for _, p := range []**string{&req.Website, &req.Notes} {
+		if *p != nil {
+			v := strings.TrimSpace(**p)
+			if v == "" {
+				*p = nil
+			} else {
+				*p = &v
+			}
+		}
+	}
+	return ""
+}
+
+// POST /competitors (ADMIN)
+func CreateCompetitor(c *gin.Context) {
+	var req models.CompetitorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "name is required"})
+		return
+	}
+	if msg := validateCompetitor(&req); msg != "" {
+		c.JSON(400, gin.H{"error": msg})
+		return
+	}
+
+	active := true
+	if req.IsActive != nil {
+		active = *req.IsActive
+	}
+
+	var id int64
+	err := database.Pool.QueryRow(c, `
+		INSERT INTO competitors (name, website, notes, is_active)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT ((lower(name))) DO NOTHING
+		RETURNING id
+	`, req.Name, req.Website, req.Notes, active).Scan(&id)
+	if err == pgx.ErrNoRows {
+		c.JSON(409, gin.H{"error": "competitor already exists"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(201, gin.H{"id": id, "name": req.Name})
+}
+
+// PUT /competitors/:id (ADMIN)
+func UpdateCompetitor(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid competitor id"})
+		return
+	}
+
+	var req models.CompetitorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "name is required"})
+		return
+	}
+	if msg := validateCompetitor(&req); msg != "" {
+		c.JSON(400, gin.H{"error": msg})
+		return
+	}
+
+	var taken bool
+	if err := database.Pool.QueryRow(c,
+		`SELECT EXISTS(SELECT 1 FROM competitors WHERE lower(name) = lower($1) AND id <> $2)`, req.Name, id,
+	).Scan(&taken); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if taken {
+		c.JSON(409, gin.H{"error": "competitor already exists"})
+		return
+	}
+
+	tag, err := database.Pool.Exec(c, `
+		UPDATE competitors SET
+			name = $1, website = $2, notes = $3,
+			is_active = COALESCE($4, is_active),
+			updated_at = NOW()
+		WHERE id = $5
+	`, req.Name, req.Website, req.Notes, req.IsActive, id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(404, gin.H{"error": "competitor not found"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "updated"})
+}
+
+// DELETE /competitors/:id (ADMIN) — kompetitor yang sudah dipakai → 409 + dependents
+func DeleteCompetitor(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid competitor id"})
+		return
+	}
+
+	deps, err := loadDependents(c, database.Pool, competitorDependents, id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if len(deps) > 0 {
+		c.JSON(409, gin.H{
+			"error":      "competitor is in use; set is_active=false instead",
+			"dependents": deps,
+		})
+		return
+	}
+
+	tag, err := database.Pool.Exec(c, `DELETE FROM competitors WHERE id = $1`, id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(404, gin.H{"error": "competitor not found"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "deleted"})
+}
+
+// ======================================================
+// PROJECT COMPETITORS — /projects/:id/competitors
+// ======================================================
+
+func ListProjectCompetitors(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+	if _, ok := checkProjectAccess(c, projectID); !ok {
+		return
+	}
+
+	list, err := loadProjectCompetitors(c, database.Pool, projectID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, list)
+}
+
+// PUT /projects/:id/competitors/:competitorId → tambah / update (upsert)
+func SetProjectCompetitor(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+	competitorID, err := strconv.ParseInt(c.Param("competitorId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid competitor id"})
+		return
+	}
+
+	var req models.ProjectCompetitorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "invalid body"})
+		return
+	}
+	if req.CompetitorPrice != nil && *req.CompetitorPrice < 0 {
+		c.JSON(400, gin.H{"error": "competitor_price must be >= 0"})
+		return
+	}
+	if req.Notes != nil {
+		v := strings.TrimSpace(*req.Notes)
+		if v == "" {
+			req.Notes = nil
+		} else {
+			req.Notes = &v
+		}
+	}
+	incumbent := req.IsIncumbent != nil && *req.IsIncumbent
+
+	if _, ok := checkProjectAccess(c, projectID); !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// kompetitor nonaktif tidak bisa ditambahkan baru, tapi yang sudah ada tetap bisa di-update
+	found, usable, err := competitorUsable(ctx, database.Pool, projectID, competitorID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if !found {
+		c.JSON(404, gin.H{"error": "competitor not found"})
+		return
+	}
+	if !usable {
+		c.JSON(400, gin.H{"error": "competitor is inactive"})
+		return
+	}
+
+	_, err = database.Pool.Exec(ctx, `
+		INSERT INTO project_competitors (project_id, competitor_id, competitor_price, is_incumbent, notes)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (project_id, competitor_id) DO UPDATE SET
+			competitor_price = EXCLUDED.competitor_price,
+			is_incumbent     = EXCLUDED.is_incumbent,
+			notes            = EXCLUDED.notes,
+			updated_at       = NOW()
+	`, projectID, competitorID, req.CompetitorPrice, incumbent, req.Notes)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	list, err := loadProjectCompetitors(ctx, database.Pool, projectID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, list)
+}
+
+// DELETE /projects/:id/competitors/:competitorId — pemenang (Loss) tidak bisa dilepas
+func RemoveProjectCompetitor(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+	competitorID, err := strconv.ParseInt(c.Param("competitorId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid competitor id"})
+		return
+	}
+
+	if _, ok := checkProjectAccess(c, projectID); !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	tag, err := database.Pool.Exec(ctx, `
+		DELETE FROM project_competitors pc
+		WHERE pc.project_id = $1 AND pc.competitor_id = $2
+		  AND NOT EXISTS (
+			SELECT 1 FROM projects p
+			WHERE p.id = pc.project_id AND p.winning_competitor_id = pc.competitor_id
+		  )
+	`, projectID, competitorID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		var winner bool
+		if err := database.Pool.QueryRow(ctx,
+			`SELECT EXISTS(SELECT 1 FROM projects WHERE id = $1 AND winning_competitor_id = $2)`,
+			projectID, competitorID,
+		).Scan(&winner); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		if winner {
+			c.JSON(409, gin.H{"error": "competitor is the winning competitor of this project"})
+			return
+		}
+		c.JSON(404, gin.H{"error": "competitor not linked to project"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "deleted"})
+}
+
+// ======================================================
+// COMPETITOR REPORT — GET /api/reports/competitors
+//   ?division=&from=YYYY-MM-DD&to=YYYY-MM-DD&project_type=
+// head-to-head = Win vs Loss yang dimenangkan kompetitor tsb
+// periode berlaku untuk tanggal keputusan (sph_status_changed_at); Open/Hold apa adanya
+// value = total target revenue project
+// ======================================================
+
+// competitorAcc = akumulator per kompetitor + total harga / delta untuk rata-rata
+type competitorAcc struct {
+	models.CompetitorReportRow
+	priceTotal float64
+	priceCount int
+	deltaTotal float64
+	deltaCount int
+}
+
+func GetCompetitorReport(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	role := c.GetString("role")
+	userDiv := NormalizeDivision(c.GetString("division"))
+
+	var resp models.CompetitorReportResponse
+
+	conds := []string{"1=1"}
+	args := []any{}
+	i := 1
+
+	division := NormalizeDivision(strings.TrimSpace(c.Query("division")))
+	if role == "user" {
+		division = userDiv
+	}
+	if strings.ToUpper(division) == "ALL" {
+		division = ""
+	}
+	if division != "" {
+		conds = append(conds, fmt.Sprintf("p.division = $%d", i))
+		args = append(args, division)
+		i++
+	}
+	resp.Division = division
+
+	if v := strings.TrimSpace(c.Query("project_type")); v != "" && strings.ToUpper(v) != "ALL" {
+		conds = append(conds, fmt.Sprintf("p.project_type = $%d", i))
+		args = append(args, v)
+		i++
+	}
+
+	// periode = tanggal keputusan, sama dengan report win/loss
+	decision, args, ok := parseDecisionFilter(c, args, i)
+	if !ok {
+		return
+	}
+	resp.From, resp.To = decision.From, decision.To
+
+	rows, err := database.Pool.Query(ctx, fmt.Sprintf(`
+		SELECT
+			co.id,
+			co.name,
+			pc.is_incumbent,
+			pc.competitor_price::float8,
+			p.sph_status,
+			p.winning_competitor_id,
+			%s
+		FROM project_competitors pc
+		JOIN competitors co ON co.id = pc.competitor_id
+		JOIN projects p ON p.id = pc.project_id
+		%s
+		WHERE %s AND %s
+	`, projectValueExpr, projectValueJoin, strings.Join(conds, " AND "), decision.Cond), args...)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	byCompetitor := map[int64]*competitorAcc{}
+	for rows.Next() {
+		var (
+			competitorID int64
+			name, status string
+			incumbent    bool
+			price        *float64
+			winnerID     *int64
+			value        float64
+		)
+		if err := rows.Scan(&competitorID, &name, &incumbent, &price, &status, &winnerID, &value); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		a := byCompetitor[competitorID]
+		if a == nil {
+			a = &competitorAcc{CompetitorReportRow: models.CompetitorReportRow{CompetitorID: competitorID, Name: name}}
+			byCompetitor[competitorID] = a
+		}
+
+		a.Engaged++
+		if incumbent {
+			a.Incumbent++
+		}
+		if price != nil {
+			a.priceTotal += *price
+			a.priceCount++
+			if value > 0 {
+				a.deltaTotal += (*price - value) / value * 100
+				a.deltaCount++
+			}
+		}
+
+		switch status {
+		case "Win":
+			a.Won++
+			a.WonValue += value
+		case "Loss":
+			if winnerID != nil && *winnerID == competitorID {
+				a.LostToThem++
+				a.LostToThemValue += value
+			} else {
+				a.LostToOthers++
+			}
+		case "Drop":
+			a.Dropped++
+		default:
+			a.Open++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	list := make([]models.CompetitorReportRow, 0, len(byCompetitor))
+	for _, a := range byCompetitor {
+		r := a.CompetitorReportRow
+		r.HeadToHeadWinRate = round2(pct(float64(r.Won), float64(r.Won+r.LostToThem)))
+		r.WinRate = round2(pct(float64(r.Won), float64(r.Won+r.LostToThem+r.LostToOthers+r.Dropped)))
+		r.WonValue = round2(r.WonValue)
+		r.LostToThemValue = round2(r.LostToThemValue)
+		if a.priceCount > 0 {
+			avg := round2(a.priceTotal / float64(a.priceCount))
+			r.AvgCompetitorPrice = &avg
+		}
+		if a.deltaCount > 0 {
+			avg := round2(a.deltaTotal / float64(a.deltaCount))
+			r.AvgPriceDeltaPct = &avg
+		}
+		list = append(list, r)
+	}
+	sort.Slice(list, func(a, b int) bool {
+		if list[a].Engaged != list[b].Engaged {
+			return list[a].Engaged > list[b].Engaged
+		}
+		return list[a].Name < list[b].Name
+	})
+
+	resp.Competitors = list
+	c.JSON(200, resp)
+}
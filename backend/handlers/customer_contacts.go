@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"sales-system-backend/database"
+	"sales-system-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+var buyingRoles = []string{
+	"Decision Maker", "Economic Buyer", "Technical Buyer",
+	"Influencer", "Champion", "User", "Gatekeeper",
+}
+
+func normalizeBuyingRole(r string) (string, bool) {
+	r = strings.TrimSpace(r)
+	for _, v := range buyingRoles {
+		if strings.EqualFold(v, r) {
+			return v, true
+		}
+	}
+	return r, false
+}
+
+// validateContactRequest normalisasi + validasi field opsional.
+// requireName = true untuk create.
+func validateContactRequest(req *models.CustomerContactRequest, requireName bool) string {
+	if req.Name != nil {
+		n := strings.TrimSpace(*req.Name)
+		req.Name = &n
+	}
+	if requireName && (req.Name == nil || *req.Name == "") {
+		return "name is required"
+	}
+	if !requireName && req.Name != nil && *req.Name == "" {
+		return "name cannot be empty"
+	}
+
+	if req.Email != nil {
+		e := strings.TrimSpace(*req.Email)
+		if e != "" && !strings.Contains(e, "@") {
+			return "invalid email"
+		}
+		req.Email = &e
+	}
+
+	if req.BuyingRole != nil && strings.TrimSpace(*req.BuyingRole) != "" {
+		role, ok := normalizeBuyingRole(*req.BuyingRole)
+		if !ok {
+			return "invalid buying_role"
+		}
+		req.BuyingRole = &role
+	}
+
+	return ""
+}
+
+// ======================================================
+// LIST CONTACTS
+// ======================================================
+
+func ListCustomerContacts(c *gin.Context) {
+	customerID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid customer id"})
+		return
+	}
+
+	if !checkCustomerVisible(c, customerID) {
+		return
+	}
+
+	rows, err := database.Pool.Query(c, `
+		SELECT id, customer_id, name, title, email, phone, buying_role, is_primary, notes, created_at, updated_at
+		FROM customer_contacts
+		WHERE customer_id = $1
+		ORDER BY is_primary DESC, name ASC
+	`, customerID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	list := []models.CustomerContact{}
+	for rows.Next() {
+		var ct models.CustomerContact
+		if err := rows.Scan(
+			&ct.ID, &ct.CustomerID, &ct.Name, &ct.Title, &ct.Email, &ct.Phone,
+			&ct.BuyingRole, &ct.IsPrimary, &ct.Notes, &ct.CreatedAt, &ct.UpdatedAt,
+		); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		list = append(list, ct)
+	}
+
+	c.JSON(200, list)
+}
+
+// ======================================================
+// CREATE CONTACT
+// ======================================================
+
+func CreateCustomerContact(c *gin.Context) {
+	customerID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid customer id"})
+		return
+	}
+
+	var req models.CustomerContactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "invalid body"})
+		return
+	}
+	if msg := validateContactRequest(&req, true); msg != "" {
+		c.JSON(400, gin.H{"error": msg})
+		return
+	}
+
+	if !checkCustomerVisible(c, customerID) {
+		return
+	}
+
+	isPrimary := req.IsPrimary != nil && *req.IsPrimary
+
+	tx, err := database.Pool.Begin(c)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(c)
+
+	// primary baru menggantikan primary lama
+	if isPrimary {
+		if _, err := tx.Exec(c, `
+			UPDATE customer_contacts SET is_primary = false, updated_at = NOW()
+			WHERE customer_id = $1 AND is_primary
+		`, customerID); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var id int64
+	err = tx.QueryRow(c, `
+		INSERT INTO customer_contacts (customer_id, name, title, email, phone, buying_role, is_primary, notes)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8)
+		RETURNING id
+	`, customerID, *req.Name, req.Title, req.Email, req.Phone, req.BuyingRole, isPrimary, req.Notes).Scan(&id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	c.JSON(201, gin.H{"id": id})
+}
+
+// ======================================================
+// UPDATE CONTACT (partial)
+// ======================================================
+
+func UpdateCustomerContact(c *gin.Context) {
+	customerID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid customer id"})
+		return
+	}
+	contactID, err := strconv.ParseInt(c.Param("contactId"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid contact id"})
+		return
+	}
+
+	var req models.CustomerContactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "invalid body"})
+		return
+	}
+	if msg := validateContactRequest(&req, false); msg != "" {
+		c.JSON(400, gin.H{"error": msg})
+		return
+	}
+
+	if !checkCustomerVisible(c, customerID) {
+		return
+	}
+
+	tx, err := database.Pool.Begin(c)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(c)
+
+	if req.IsPrimary != nil && *req.IsPrimary {
+		if _, err := tx.Exec(c, `
+			UPDATE customer_contacts SET is_primary = false, updated_at = NOW()
+			WHERE customer_id = $1 AND is_primary AND id <> $2
+		`, customerID, contactID); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	tag, err := tx.Exec(c, `
+		UPDATE customer_contacts
+		SET
+			name        = COALESCE($1, name),
+			title       = COALESCE($2, title),
+			email       = COALESCE($3, email),
+			phone       = COALESCE($4, phone),
+			buying_role = CASE WHEN $5::text IS NULL THEN buying_role ELSE NULLIF($5, '') END,
+			is_primary  = COALESCE($6, is_primary),
+			notes       = COALESCE($7, notes),
+			updated_at  = NOW()
+		WHERE id = $8 AND customer_id = $9
+	`, req.Name, req.Title, req.Email, req.Phone, req.BuyingRole, req.IsPrimary, req.Notes, contactID, customerID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if tag.RowsAffected() == 0 {
+		c.JSON(404, gin.H{"error": "contact not found"})
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "updated"})
+}
+
+// ======================================================
+// DELETE CONTACT (link stakeholder ikut terhapus)
+// ======================================================
+
+func DeleteCustomerContact(c *gin.Context) {
+	customerID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid customer id"})
+		return
+	}
+	contactID, err := strconv.ParseInt(c.Param("contactId"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid contact id"})
+		return
+	}
+
+	if !checkCustomerVisible(c, customerID) {
+		return
+	}
+
+	tag, err := database.Pool.Exec(c,
+		`DELETE FROM customer_contacts WHERE id = $1 AND customer_id = $2`,
+		contactID, customerID,
+	)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if tag.RowsAffected() == 0 {
+		c.JSON(404, gin.H{"error": "contact not found"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "deleted"})
+}
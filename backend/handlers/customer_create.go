@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"strings"
+
 	"sales-system-backend/database"
 
 	"github.com/gin-gonic/gin"
@@ -18,17 +20,103 @@ func CreateCustomer(c *gin.Context) {
 		return
 	}
 
+	body.Name = strings.TrimSpace(body.Name)
+	if body.Name == "" {
+		c.JSON(400, gin.H{"error": "name is required"})
+		return
+	}
+
+	// cegah duplikat "PT Pertamina" vs "Pertamina (Persero)"
+	dupID, dupName, err := findCustomerByNormalizedName(c, database.Pool, body.Name, 0)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if dupID != 0 {
+		respondCustomerDuplicate(c, dupID, dupName)
+		return
+	}
+
+	// industry / region wajib dari reference data (simpan nama kanonik)
+	industry, ok, err := resolveIndustry(c, body.Industry)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if !ok {
+		c.JSON(400, gin.H{"error": "unknown industry: " + body.Industry})
+		return
+	}
+
+	region, ok, err := resolveRegion(c, body.Region)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if !ok {
+		c.JSON(400, gin.H{"error": "unknown region: " + body.Region})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
 	var id int64
-	err := database.Pool.QueryRow(c, `
+	err = tx.QueryRow(ctx, `
 		INSERT INTO customers (name, industry, region)
-		VALUES ($1, $2, $3)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''))
 		RETURNING id
-	`, body.Name, body.Industry, body.Region).Scan(&id)
+	`, body.Name, industry, region).Scan(&id)
 
 	if err != nil {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
 
+	// customer dibuat user divisi → otomatis dimiliki divisinya
+	// (harus satu tx: customer tanpa row customer_divisions terlihat semua divisi)
+	if c.GetString("role") == "user" {
+		if div := NormalizeDivision(c.GetString("division")); div != "" {
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO customer_divisions (customer_id, division)
+				VALUES ($1, $2)
+				ON CONFLICT DO NOTHING
+			`, id, div); err != nil {
+				c.JSON(500, gin.H{"error": err.Error()})
+				return
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
 	c.JSON(201, gin.H{"id": id})
 }
+
+// respondCustomerDuplicate → 409 nama duplikat; customer yang tidak terlihat oleh
+// user → jangan bocorkan id / nama
+func respondCustomerDuplicate(c *gin.Context, dupID int64, dupName string) {
+	visible, err := customerVisible(c, dupID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if !visible {
+		c.JSON(409, gin.H{"error": "customer with a similar name already exists"})
+		return
+	}
+	c.JSON(409, gin.H{
+		"error":       "customer with a similar name already exists",
+		"existing_id": dupID,
+		"existing":    dupName,
+	})
+}
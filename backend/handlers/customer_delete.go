@@ -1,20 +1,117 @@
 package handlers
 
 import (
+	"strconv"
+
 	"sales-system-backend/database"
 
 	"github.com/gin-gonic/gin"
 )
 
+// record yang menunjuk ke customer (ikut dipindah oleh repointCustomerTx)
+var customerDependents = []dependentRef{
+	{Type: "projects", Table: "projects", Column: "customer_id", Label: "project_code"},
+	{Type: "contacts", Table: "customer_contacts", Column: "customer_id", Label: "name"},
+	{Type: "child_customers", Table: "customers", Column: "parent_id", Label: "name"},
+	{Type: "leads", Table: "leads", Column: "customer_id", Label: "name"},
+	{Type: "activities", Table: "activities", Column: "customer_id", Label: "subject"},
+}
+
+// DELETE /customers/:id[?reassign_to=<customerId>]
+// - masih punya dependent & tanpa reassign_to → 409 + daftar dependent
+// - reassign_to → dependent dipindah lalu customer dihapus (1 transaksi)
 func DeleteCustomer(c *gin.Context) {
-	id := c.Param("id")
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid customer id"})
+		return
+	}
 
-	_, err := database.Pool.Exec(c, `DELETE FROM customers WHERE id=$1`, id)
+	var reassignTo int64
+	if s := c.Query("reassign_to"); s != "" {
+		reassignTo, err = strconv.ParseInt(s, 10, 64)
+		if err != nil || reassignTo <= 0 {
+			c.JSON(400, gin.H{"error": "invalid reassign_to"})
+			return
+		}
+		if reassignTo == id {
+			c.JSON(400, gin.H{"error": "reassign_to must be a different customer"})
+			return
+		}
+	}
 
+	if !checkCustomerVisible(c, id) {
+		return
+	}
+	if reassignTo != 0 && !checkCustomerVisible(c, reassignTo) {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	tx, err := database.Pool.Begin(ctx)
 	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var locked int64
+	if err := tx.QueryRow(ctx, `SELECT id FROM customers WHERE id=$1 FOR UPDATE`, id).Scan(&locked); err != nil {
+		c.JSON(404, gin.H{"error": "customer not found"})
+		return
+	}
+
+	deps, err := loadDependents(ctx, tx, customerDependents, id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(deps) > 0 {
+		if reassignTo == 0 {
+			c.JSON(409, gin.H{
+				"error":      "customer has dependent records; pass reassign_to to move them",
+				"dependents": deps,
+			})
+			return
+		}
+
+		if err := tx.QueryRow(ctx, `SELECT id FROM customers WHERE id=$1 FOR UPDATE`, reassignTo).Scan(&locked); err != nil {
+			c.JSON(404, gin.H{"error": "reassign_to customer not found"})
+			return
+		}
+
+		cycle, err := customerHasAncestorTx(ctx, tx, reassignTo, []int64{id})
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		if cycle {
+			c.JSON(409, gin.H{"error": "reassign_to is a descendant of this customer; detach it first"})
+			return
+		}
+
+		if _, err := repointCustomerTx(ctx, tx, id, reassignTo); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM customers WHERE id=$1`, id); err != nil {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(200, gin.H{"status": "deleted"})
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	resp := gin.H{"status": "deleted"}
+	if len(deps) > 0 {
+		resp["reassigned_to"] = reassignTo
+		resp["reassigned"] = deps
+	}
+	c.JSON(200, resp)
 }
@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
+
 	"sales-system-backend/database"
 	"sales-system-backend/models"
 
@@ -9,13 +11,21 @@ import (
 )
 
 func GetCustomer(c *gin.Context) {
-	id := c.Param("id")
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid customer id"})
+		return
+	}
 	ctx := c.Request.Context()
 
+	if !checkCustomerVisible(c, id) {
+		return
+	}
+
 	var cust models.Customer
 
-	err := database.Pool.QueryRow(ctx,
-		`SELECT id, name, industry, region, created_at, updated_at
+	err = database.Pool.QueryRow(ctx,
+		`SELECT id, name, COALESCE(industry, ''), COALESCE(region, ''), parent_id, created_at, updated_at
 		 FROM customers WHERE id=$1`,
 		id,
 	).Scan(
@@ -23,6 +33,7 @@ func GetCustomer(c *gin.Context) {
 		&cust.Name,
 		&cust.Industry,
 		&cust.Region,
+		&cust.ParentID,
 		&cust.CreatedAt,
 		&cust.UpdatedAt,
 	)
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+
+	"sales-system-backend/database"
+	"sales-system-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ======================================================
+// LIST CHILDREN (anak langsung dari customer group)
+// ======================================================
+
+func GetCustomerChildren(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid customer id"})
+		return
+	}
+
+	if !checkCustomerVisible(c, id) {
+		return
+	}
+
+	// anak yang tidak terlihat oleh divisi user ikut disembunyikan
+	cond := "TRUE"
+	args := []any{id}
+	if c.GetString("role") == "user" {
+		cond = customerVisibleCond("cu", 2)
+		args = append(args, NormalizeDivision(c.GetString("division")))
+	}
+
+	rows, err := database.Pool.Query(c, fmt.Sprintf(`
+		SELECT cu.id, cu.name, COALESCE(cu.industry, ''), COALESCE(cu.region, ''), cu.parent_id, cu.created_at, cu.updated_at
+		FROM customers cu
+		WHERE cu.parent_id = $1 AND %s
+		ORDER BY cu.name ASC
+	`, cond), args...)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	list := []models.Customer{}
+	for rows.Next() {
+		var cust models.Customer
+		if err := rows.Scan(
+			&cust.ID,
+			&cust.Name,
+			&cust.Industry,
+			&cust.Region,
+			&cust.ParentID,
+			&cust.CreatedAt,
+			&cust.UpdatedAt,
+		); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		list = append(list, cust)
+	}
+
+	c.JSON(200, list)
+}
+
+// ======================================================
+// ATTACH CHILD → parent_id child = :id
+// ======================================================
+
+func AttachCustomerChild(c *gin.Context) {
+	parentID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid customer id"})
+		return
+	}
+
+	var body struct {
+		ChildID int64 `json:"child_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "invalid body"})
+		return
+	}
+
+	if body.ChildID == parentID {
+		c.JSON(400, gin.H{"error": "customer cannot be its own parent"})
+		return
+	}
+
+	if !checkCustomerVisible(c, parentID) || !checkCustomerVisible(c, body.ChildID) {
+		return
+	}
+
+	// tolak cycle: child tidak boleh jadi ancestor dari parent baru
+	var cycle bool
+	err = database.Pool.QueryRow(c, `
+		WITH RECURSIVE anc AS (
+			SELECT id, parent_id FROM customers WHERE id = $1
+			UNION
+			SELECT cu.id, cu.parent_id
+			FROM customers cu
+			JOIN anc ON cu.id = anc.parent_id
+		)
+		SELECT EXISTS(SELECT 1 FROM anc WHERE id = $2)
+	`, parentID, body.ChildID).Scan(&cycle)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if cycle {
+		c.JSON(409, gin.H{"error": "attaching this customer would create a cycle"})
+		return
+	}
+
+	_, err = database.Pool.Exec(c, `
+		UPDATE customers SET parent_id = $1, updated_at = NOW() WHERE id = $2
+	`, parentID, body.ChildID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "attached"})
+}
+
+// ======================================================
+// DETACH CHILD
+// ======================================================
+
+func DetachCustomerChild(c *gin.Context) {
+	parentID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid customer id"})
+		return
+	}
+	childID, err := strconv.ParseInt(c.Param("childId"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid child id"})
+		return
+	}
+
+	if !checkCustomerVisible(c, parentID) || !checkCustomerVisible(c, childID) {
+		return
+	}
+
+	tag, err := database.Pool.Exec(c, `
+		UPDATE customers SET parent_id = NULL, updated_at = NOW()
+		WHERE id = $1 AND parent_id = $2
+	`, childID, parentID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if tag.RowsAffected() == 0 {
+		c.JSON(404, gin.H{"error": "child not found under this customer"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "detached"})
+}
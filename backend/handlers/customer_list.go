@@ -1,40 +1,302 @@
 package handlers
 
 import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
 	"sales-system-backend/database"
 	"sales-system-backend/models"
 
 	"github.com/gin-gonic/gin"
 )
 
+// customerVisibleCond → customer terlihat oleh divisi $argIdx jika:
+// - belum punya ownership sama sekali, atau
+// - dimiliki divisi tsb (customer_divisions), atau
+// - divisi tsb sudah punya project dengan customer ini
+func customerVisibleCond(alias string, argIdx int) string {
+	return fmt.Sprintf(`(
+		NOT EXISTS (SELECT 1 FROM customer_divisions cd WHERE cd.customer_id = %[1]s.id)
+		OR EXISTS (SELECT 1 FROM customer_divisions cd WHERE cd.customer_id = %[1]s.id AND cd.division = $%[2]d)
+		OR EXISTS (SELECT 1 FROM projects px WHERE px.customer_id = %[1]s.id AND px.division = $%[2]d)
+	)`, alias, argIdx)
+}
+
+// customerVisible → customer ada dan terlihat oleh user (tanpa menulis response)
+func customerVisible(c *gin.Context, customerID int64) (bool, error) {
+	q := `SELECT EXISTS(SELECT 1 FROM customers cu WHERE cu.id = $1`
+	args := []any{customerID}
+
+	if c.GetString("role") == "user" {
+		q += " AND " + customerVisibleCond("cu", 2)
+		args = append(args, NormalizeDivision(c.GetString("division")))
+	}
+	q += ")"
+
+	var ok bool
+	err := database.Pool.QueryRow(c.Request.Context(), q, args...).Scan(&ok)
+	return ok, err
+}
+
+// checkCustomerVisible → 404 kalau customer tidak ada / tidak terlihat oleh user
+func checkCustomerVisible(c *gin.Context, customerID int64) bool {
+	ok, err := customerVisible(c, customerID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return false
+	}
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "customer not found"})
+		return false
+	}
+	return true
+}
+
+// ======================================================
+// LIST CUSTOMERS
+// ?q=&industry=&region=&division=&page=&page_size=&sort_by=&sort_dir=
+// body tetap array; total → header X-Total-Count
+// tanpa page/page_size → semua row (dipakai dropdown FE)
+// ======================================================
+
 func GetCustomers(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	rows, err := database.Pool.Query(ctx,
-		`SELECT id, name, industry, region, created_at, updated_at
-		 FROM customers
-		 ORDER BY name ASC`,
-	)
+	// --- Sorting ---
+	sortBy := c.DefaultQuery("sort_by", "name")
+	sortDir := strings.ToLower(c.DefaultQuery("sort_dir", "asc"))
+
+	allowed := map[string]string{
+		"name":          "cu.name",
+		"industry":      "cu.industry",
+		"region":        "cu.region",
+		"project_count": "project_count",
+		"total_revenue": "total_revenue",
+		"created_at":    "cu.created_at",
+	}
+	col, ok := allowed[sortBy]
+	if !ok {
+		col = "cu.name"
+	}
+	if sortDir != "asc" && sortDir != "desc" {
+		sortDir = "asc"
+	}
+
+	// --- ACL + FILTERS ---
+	role := c.GetString("role")
+	userDiv := NormalizeDivision(c.GetString("division"))
+
+	whereParts := []string{}
+	args := []any{}
+	i := 1
+
+	// divisi untuk visibility + agregat project
+	scopeDiv := ""
+	if role == "user" {
+		scopeDiv = userDiv
+	} else {
+		divQ := NormalizeDivision(strings.TrimSpace(c.Query("division")))
+		if divQ != "" && strings.ToUpper(divQ) != "ALL" {
+			scopeDiv = divQ
+		}
+	}
+
+	divArg := 0
+	if scopeDiv != "" {
+		whereParts = append(whereParts, customerVisibleCond("cu", i))
+		args = append(args, scopeDiv)
+		divArg = i
+		i++
+	}
+
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		whereParts = append(whereParts, fmt.Sprintf(
+			"(cu.name ILIKE $%[1]d OR cu.industry ILIKE $%[1]d OR cu.region ILIKE $%[1]d)", i))
+		args = append(args, "%"+q+"%")
+		i++
+	}
+
+	if ind := strings.TrimSpace(c.Query("industry")); ind != "" {
+		whereParts = append(whereParts, fmt.Sprintf("LOWER(cu.industry) = LOWER($%d)", i))
+		args = append(args, ind)
+		i++
+	}
+
+	if reg := strings.TrimSpace(c.Query("region")); reg != "" {
+		whereParts = append(whereParts, fmt.Sprintf("LOWER(cu.region) = LOWER($%d)", i))
+		args = append(args, reg)
+		i++
+	}
+
+	whereClause := ""
+	if len(whereParts) > 0 {
+		whereClause = "WHERE " + strings.Join(whereParts, " AND ")
+	}
+
+	// --- Total ---
+	var total int64
+	if err := database.Pool.QueryRow(ctx,
+		"SELECT COUNT(*) FROM customers cu "+whereClause, args...,
+	).Scan(&total); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	// --- Pagination ---
+	limitClause := ""
+	if c.Query("page") != "" || c.Query("page_size") != "" {
+		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+		pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+		if page < 1 {
+			page = 1
+		}
+		if pageSize < 1 || pageSize > 200 {
+			pageSize = 20
+		}
+		limitClause = fmt.Sprintf("LIMIT %d OFFSET %d", pageSize, (page-1)*pageSize)
+	}
+
+	// agregat project mengikuti divisi scope (user hanya lihat angka divisinya)
+	projDivCond := ""
+	if divArg > 0 {
+		projDivCond = fmt.Sprintf("AND p.division = $%d", divArg)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			cu.id, cu.name, COALESCE(cu.industry, ''), COALESCE(cu.region, ''),
+			cu.parent_id, cu.created_at, cu.updated_at,
+			COALESCE(own.divisions, '{}') AS divisions,
+			COALESCE(ps.project_count, 0) AS project_count,
+			COALESCE(ps.total_revenue, 0)::float8 AS total_revenue
+		FROM customers cu
+		LEFT JOIN LATERAL (
+			SELECT array_agg(cd.division ORDER BY cd.division) AS divisions
+			FROM customer_divisions cd
+			WHERE cd.customer_id = cu.id
+		) own ON true
+		LEFT JOIN LATERAL (
+			SELECT
+				COUNT(DISTINCT p.id) AS project_count,
+				SUM(rp.target_revenue) AS total_revenue
+			FROM projects p
+			LEFT JOIN project_revenue_plan rp ON rp.project_id = p.id
+			WHERE p.customer_id = cu.id %s
+		) ps ON true
+		%s
+		ORDER BY %s %s, cu.id ASC
+		%s
+	`, projDivCond, whereClause, col, sortDir, limitClause)
+
+	rows, err := database.Pool.Query(ctx, query, args...)
 	if err != nil {
 		c.JSON(500, gin.H{"error": "query error"})
 		return
 	}
 	defer rows.Close()
 
-	var list []models.Customer
+	list := []models.CustomerListItem{}
 
 	for rows.Next() {
-		var cust models.Customer
-		rows.Scan(
+		var cust models.CustomerListItem
+		if err := rows.Scan(
 			&cust.ID,
 			&cust.Name,
 			&cust.Industry,
 			&cust.Region,
+			&cust.ParentID,
 			&cust.CreatedAt,
 			&cust.UpdatedAt,
-		)
+			&cust.Divisions,
+			&cust.ProjectCount,
+			&cust.TotalRevenue,
+		); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
 		list = append(list, cust)
 	}
 
+	if err := rows.Err(); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
 	c.JSON(200, list)
 }
+
+// ======================================================
+// SET OWNERSHIP DIVISI (ADMIN) — PUT /customers/:id/divisions
+// divisions kosong → customer terlihat semua divisi
+// ======================================================
+
+func SetCustomerDivisions(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid customer id"})
+		return
+	}
+
+	var req models.SetCustomerDivisionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "invalid body"})
+		return
+	}
+
+	divs := []string{}
+	seen := map[string]bool{}
+	for _, d := range req.Divisions {
+		nd := NormalizeDivision(strings.TrimSpace(d))
+		if !isValidDivision(nd) {
+			c.JSON(400, gin.H{"error": "invalid division: " + d})
+			return
+		}
+		if !seen[nd] {
+			seen[nd] = true
+			divs = append(divs, nd)
+		}
+	}
+
+	ctx := c.Request.Context()
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM customers WHERE id=$1)`, id).Scan(&exists); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if !exists {
+		c.JSON(404, gin.H{"error": "customer not found"})
+		return
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM customer_divisions WHERE customer_id = $1`, id); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, d := range divs {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO customer_divisions (customer_id, division) VALUES ($1, $2)`, id, d,
+		); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "updated", "divisions": divs})
+}
@@ -0,0 +1,404 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+
+	"sales-system-backend/database"
+	"sales-system-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// ======================================================
+// DUPLICATE DETECTION
+// GET /customers/duplicates?threshold=0.5
+// ======================================================
+
+func GetCustomerDuplicates(c *gin.Context) {
+	threshold := 0.5
+	if s := c.Query("threshold"); s != "" {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil || v <= 0 || v > 1 {
+			c.JSON(400, gin.H{"error": "threshold must be between 0 and 1"})
+			return
+		}
+		threshold = v
+	}
+
+	ctx := c.Request.Context()
+
+	// user hanya melihat pasangan yang kedua customer-nya terlihat oleh divisinya
+	visibleA, visibleB := "TRUE", "TRUE"
+	args := []any{}
+	if c.GetString("role") == "user" {
+		visibleA, visibleB = customerVisibleCond("a", 1), customerVisibleCond("b", 1)
+		args = append(args, NormalizeDivision(c.GetString("division")))
+	}
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// operator % (pakai idx_customers_name_norm_trgm) membaca threshold dari setting,
+	// similarity() hanya untuk kolom output
+	if _, err := tx.Exec(ctx,
+		`SELECT set_config('pg_trgm.similarity_threshold', $1, true)`,
+		strconv.FormatFloat(threshold, 'f', -1, 64),
+	); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT
+			a.id, a.name, COALESCE(a.industry, ''), COALESCE(a.region, ''), a.parent_id, a.created_at, a.updated_at,
+			b.id, b.name, b.industry, b.region, b.parent_id, b.created_at, b.updated_at,
+			normalize_customer_name(a.name),
+			similarity(normalize_customer_name(a.name), b.norm)::float8 AS sim,
+			(normalize_customer_name(a.name) = b.norm) AS exact
+		FROM customers a
+		JOIN LATERAL (
+			SELECT b.id, b.name, COALESCE(b.industry, '') AS industry, COALESCE(b.region, '') AS region,
+			       b.parent_id, b.created_at, b.updated_at,
+			       normalize_customer_name(b.name) AS norm
+			FROM customers b
+			WHERE normalize_customer_name(b.name) % normalize_customer_name(a.name)
+			  AND b.id > a.id
+			  AND `+visibleB+`
+		) b ON TRUE
+		WHERE `+visibleA+`
+		ORDER BY exact DESC, sim DESC, a.name ASC
+	`, args...)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	list := []models.CustomerDuplicate{}
+	for rows.Next() {
+		var d models.CustomerDuplicate
+		if err := rows.Scan(
+			&d.CustomerA.ID, &d.CustomerA.Name, &d.CustomerA.Industry, &d.CustomerA.Region,
+			&d.CustomerA.ParentID, &d.CustomerA.CreatedAt, &d.CustomerA.UpdatedAt,
+			&d.CustomerB.ID, &d.CustomerB.Name, &d.CustomerB.Industry, &d.CustomerB.Region,
+			&d.CustomerB.ParentID, &d.CustomerB.CreatedAt, &d.CustomerB.UpdatedAt,
+			&d.Normalized, &d.Similarity, &d.Exact,
+		); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		list = append(list, d)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"threshold":  threshold,
+		"duplicates": list,
+	})
+}
+
+// findCustomerByNormalizedName → id customer lain dengan nama ter-normalisasi sama (0 jika tidak ada)
+func findCustomerByNormalizedName(ctx context.Context, db dbtx, name string, excludeID int64) (int64, string, error) {
+	var id int64
+	var existing string
+	err := db.QueryRow(ctx, `
+		SELECT id, name FROM customers
+		WHERE normalize_customer_name(name) = normalize_customer_name($1)
+		  AND id <> $2
+		ORDER BY id
+		LIMIT 1
+	`, name, excludeID).Scan(&id, &existing)
+	if err == pgx.ErrNoRows {
+		return 0, "", nil
+	}
+	return id, existing, err
+}
+
+// ======================================================
+// REPOINT (dipakai merge; semua referensi customer pindah ke target)
+// ======================================================
+
+type customerRepointResult struct {
+	Projects int
+	Contacts int
+	Children int
+}
+
+func repointCustomerTx(ctx context.Context, tx pgx.Tx, fromID, toID int64) (customerRepointResult, error) {
+	var res customerRepointResult
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE projects SET customer_id = $2, updated_at = NOW() WHERE customer_id = $1
+	`, fromID, toID)
+	if err != nil {
+		return res, err
+	}
+	res.Projects = int(tag.RowsAffected())
+
+	// primary contact source di-demote kalau target sudah punya primary
+	if _, err := tx.Exec(ctx, `
+		UPDATE customer_contacts SET is_primary = false, updated_at = NOW()
+		WHERE customer_id = $1 AND is_primary
+		  AND EXISTS (SELECT 1 FROM customer_contacts WHERE customer_id = $2 AND is_primary)
+	`, fromID, toID); err != nil {
+		return res, err
+	}
+
+	tag, err = tx.Exec(ctx, `
+		UPDATE customer_contacts SET customer_id = $2, updated_at = NOW() WHERE customer_id = $1
+	`, fromID, toID)
+	if err != nil {
+		return res, err
+	}
+	res.Contacts = int(tag.RowsAffected())
+
+	// lead yang sudah di-link ke customer ikut pindah
+	if _, err := tx.Exec(ctx, `
+		UPDATE leads SET customer_id = $2, updated_at = NOW() WHERE customer_id = $1
+	`, fromID, toID); err != nil {
+		return res, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE activities SET customer_id = $2, updated_at = NOW() WHERE customer_id = $1
+	`, fromID, toID); err != nil {
+		return res, err
+	}
+
+	// ownership divisi ikut digabung ke target
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO customer_divisions (customer_id, division)
+		SELECT $2, division FROM customer_divisions WHERE customer_id = $1
+		ON CONFLICT DO NOTHING
+	`, fromID, toID); err != nil {
+		return res, err
+	}
+
+	// riwayat merge customer lama ikut pindah ke target
+	if _, err := tx.Exec(ctx, `
+		UPDATE customer_merge_history SET target_id = $2 WHERE target_id = $1
+	`, fromID, toID); err != nil {
+		return res, err
+	}
+
+	tag, err = tx.Exec(ctx, `
+		UPDATE customers SET parent_id = $2, updated_at = NOW()
+		WHERE parent_id = $1 AND id <> $2
+	`, fromID, toID)
+	if err != nil {
+		return res, err
+	}
+	res.Children = int(tag.RowsAffected())
+
+	return res, nil
+}
+
+// customerHasAncestorTx → true jika salah satu ancestorIDs ada di rantai parent id
+// (memindah children ancestor ke id akan membentuk cycle)
+func customerHasAncestorTx(ctx context.Context, tx pgx.Tx, id int64, ancestorIDs []int64) (bool, error) {
+	var found bool
+	err := tx.QueryRow(ctx, `
+		WITH RECURSIVE anc AS (
+			SELECT id, parent_id FROM customers WHERE id = $1
+			UNION
+			SELECT cu.id, cu.parent_id
+			FROM customers cu
+			JOIN anc ON cu.id = anc.parent_id
+		)
+		SELECT EXISTS(SELECT 1 FROM anc WHERE id = ANY($2))
+	`, id, ancestorIDs).Scan(&found)
+	return found, err
+}
+
+// ======================================================
+// MERGE (ADMIN) — POST /customers/:id/merge {source_ids}
+// :id = customer yang dipertahankan
+// ======================================================
+
+func MergeCustomers(c *gin.Context) {
+	targetID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid customer id"})
+		return
+	}
+
+	var req models.MergeCustomersRequest
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.SourceIDs) == 0 {
+		c.JSON(400, gin.H{"error": "source_ids is required"})
+		return
+	}
+
+	seen := map[int64]bool{}
+	sources := []int64{}
+	for _, id := range req.SourceIDs {
+		if id == targetID {
+			c.JSON(400, gin.H{"error": "target customer cannot be a merge source"})
+			return
+		}
+		if !seen[id] {
+			seen[id] = true
+			sources = append(sources, id)
+		}
+	}
+
+	ctx := c.Request.Context()
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// lock target + sources
+	var locked int
+	err = tx.QueryRow(ctx, `
+		SELECT COUNT(*) FROM (
+			SELECT id FROM customers WHERE id = $1 OR id = ANY($2) FOR UPDATE
+		) x
+	`, targetID, sources).Scan(&locked)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if locked != len(sources)+1 {
+		c.JSON(404, gin.H{"error": "customer not found"})
+		return
+	}
+
+	// source yang merupakan ancestor target → children pindah ke target = cycle
+	ancestorSource, err := customerHasAncestorTx(ctx, tx, targetID, sources)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if ancestorSource {
+		c.JSON(409, gin.H{"error": "a source customer is an ancestor of the target; detach it first"})
+		return
+	}
+
+	userID := c.GetInt64("user_id")
+	var mergedBy *int64
+	if userID > 0 {
+		mergedBy = &userID
+	}
+
+	history := []models.CustomerMergeHistory{}
+
+	for _, sid := range sources {
+		var h models.CustomerMergeHistory
+		h.TargetID = targetID
+		h.SourceID = sid
+
+		if err := tx.QueryRow(ctx,
+			`SELECT name, industry, region FROM customers WHERE id = $1`, sid,
+		).Scan(&h.SourceName, &h.SourceIndustry, &h.SourceRegion); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		res, err := repointCustomerTx(ctx, tx, sid, targetID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		h.ProjectsMoved = res.Projects
+		h.ContactsMoved = res.Contacts
+		h.ChildrenMoved = res.Children
+		h.MergedBy = mergedBy
+
+		if _, err := tx.Exec(ctx, `DELETE FROM customers WHERE id = $1`, sid); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		err = tx.QueryRow(ctx, `
+			INSERT INTO customer_merge_history
+				(target_id, source_id, source_name, source_industry, source_region,
+				 projects_moved, contacts_moved, children_moved, merged_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			RETURNING id, merged_at
+		`, targetID, sid, h.SourceName, h.SourceIndustry, h.SourceRegion,
+			h.ProjectsMoved, h.ContactsMoved, h.ChildrenMoved, mergedBy,
+		).Scan(&h.ID, &h.MergedAt)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		history = append(history, h)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE customers SET updated_at = NOW() WHERE id = $1`, targetID); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"status":    "merged",
+		"target_id": targetID,
+		"merged":    history,
+	})
+}
+
+// ======================================================
+// MERGE HISTORY — GET /customers/:id/merge-history
+// ======================================================
+
+func GetCustomerMergeHistory(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid customer id"})
+		return
+	}
+
+	if !checkCustomerVisible(c, id) {
+		return
+	}
+
+	rows, err := database.Pool.Query(c, `
+		SELECT
+			h.id, h.target_id, h.source_id, h.source_name, h.source_industry, h.source_region,
+			h.projects_moved, h.contacts_moved, h.children_moved,
+			h.merged_by, u.username, h.merged_at
+		FROM customer_merge_history h
+		LEFT JOIN users u ON u.id = h.merged_by
+		WHERE h.target_id = $1
+		ORDER BY h.merged_at DESC, h.id DESC
+	`, id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	list := []models.CustomerMergeHistory{}
+	for rows.Next() {
+		var h models.CustomerMergeHistory
+		if err := rows.Scan(
+			&h.ID, &h.TargetID, &h.SourceID, &h.SourceName, &h.SourceIndustry, &h.SourceRegion,
+			&h.ProjectsMoved, &h.ContactsMoved, &h.ChildrenMoved,
+			&h.MergedBy, &h.MergedByName, &h.MergedAt,
+		); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		list = append(list, h)
+	}
+
+	c.JSON(200, list)
+}
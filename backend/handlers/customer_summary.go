@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"sales-system-backend/database"
+	"sales-system-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ======================================================
+// CUSTOMER 360 — GET /customers/:id/summary
+// user: hanya project divisinya; admin: semua / ?division=
+// ======================================================
+
+func GetCustomerSummary(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid customer id"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	if !checkCustomerVisible(c, id) {
+		return
+	}
+
+	var res models.CustomerSummaryResponse
+	err = database.Pool.QueryRow(ctx, `
+		SELECT id, name, COALESCE(industry, ''), COALESCE(region, ''), parent_id, created_at, updated_at
+		FROM customers WHERE id = $1
+	`, id).Scan(
+		&res.Customer.ID,
+		&res.Customer.Name,
+		&res.Customer.Industry,
+		&res.Customer.Region,
+		&res.Customer.ParentID,
+		&res.Customer.CreatedAt,
+		&res.Customer.UpdatedAt,
+	)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "customer not found"})
+		return
+	}
+
+	// --- ACL ---
+	role := c.GetString("role")
+	userDiv := NormalizeDivision(c.GetString("division"))
+
+	where := "p.customer_id = $1"
+	args := []any{id}
+
+	if role == "user" {
+		where += " AND p.division = $2"
+		args = append(args, userDiv)
+		res.Division = userDiv
+	} else {
+		divQ := NormalizeDivision(strings.TrimSpace(c.Query("division")))
+		if divQ != "" && strings.ToUpper(divQ) != "ALL" {
+			where += " AND p.division = $2"
+			args = append(args, divQ)
+			res.Division = divQ
+		}
+	}
+
+	// --- Projects (+ total plan) ---
+	rows, err := database.Pool.Query(ctx, fmt.Sprintf(`
+		SELECT
+			p.id, p.project_code, COALESCE(p.description, ''), p.division,
+			p.status, p.project_type, p.sales_stage,
+			p.sph_status, p.sph_status_reason_category,
+			COALESCE(SUM(rp.target_revenue), 0)::float8,
+			COALESCE(SUM(rp.target_realization), 0)::float8
+		FROM projects p
+		LEFT JOIN project_revenue_plan rp ON rp.project_id = p.id
+		WHERE %s
+		GROUP BY p.id
+		ORDER BY p.id DESC
+	`, where), args...)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	projects := []models.CustomerSummaryProject{}
+	for rows.Next() {
+		var p models.CustomerSummaryProject
+		if err := rows.Scan(
+			&p.ID, &p.ProjectCode, &p.Description, &p.Division,
+			&p.Status, &p.ProjectType, &p.SalesStage,
+			&p.SPHStatus, &p.SPHStatusReasonCategory,
+			&p.TargetRevenue, &p.Realization,
+		); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		projects = append(projects, p)
+	}
+	rows.Close()
+
+	// --- Group by status / stage + win-loss ---
+	byStatus := map[string]*models.CustomerSummaryGroup{}
+	byStage := map[string]*models.CustomerSummaryGroup{}
+	reasons := map[[2]string]int{}
+
+	addTo := func(m map[string]*models.CustomerSummaryGroup, key string, p models.CustomerSummaryProject) {
+		g, ok := m[key]
+		if !ok {
+			g = &models.CustomerSummaryGroup{Key: key, Projects: []models.CustomerSummaryProject{}}
+			m[key] = g
+		}
+		g.Count++
+		g.TargetRevenue += p.TargetRevenue
+		g.Realization += p.Realization
+		g.Projects = append(g.Projects, p)
+	}
+
+	for _, p := range projects {
+		addTo(byStatus, p.Status, p)
+		addTo(byStage, strconv.Itoa(p.SalesStage), p)
+
+		res.Lifetime.TargetRevenue += p.TargetRevenue
+		res.Lifetime.Realization += p.Realization
+
+		st := "Open"
+		if p.SPHStatus != nil && strings.TrimSpace(*p.SPHStatus) != "" {
+			st = strings.TrimSpace(*p.SPHStatus)
+		}
+		switch strings.ToLower(st) {
+		case "win":
+			res.WinLoss.Win++
+		case "hold":
+			res.WinLoss.Hold++
+		case "loss":
+			res.WinLoss.Loss++
+		case "drop":
+			res.WinLoss.Drop++
+		default:
+			res.WinLoss.Open++
+		}
+
+		if p.SPHStatusReasonCategory != nil {
+			switch strings.ToLower(st) {
+			case "loss":
+				reasons[[2]string{"Loss", *p.SPHStatusReasonCategory}]++
+			case "drop":
+				reasons[[2]string{"Drop", *p.SPHStatusReasonCategory}]++
+			}
+		}
+	}
+
+	res.Lifetime.AchievementPct = pct(res.Lifetime.Realization, res.Lifetime.TargetRevenue)
+	res.WinLoss.WinRate = pct(
+		float64(res.WinLoss.Win),
+		float64(res.WinLoss.Win+res.WinLoss.Loss+res.WinLoss.Drop),
+	)
+
+	res.ByStatus = sortedSummaryGroups(byStatus)
+	res.ByStage = sortedSummaryGroups(byStage)
+
+	res.WinLoss.Reasons = []models.CustomerWinLossReason{}
+	for k, n := range reasons {
+		res.WinLoss.Reasons = append(res.WinLoss.Reasons, models.CustomerWinLossReason{
+			SPHStatus: k[0], Category: k[1], Count: n,
+		})
+	}
+	sort.Slice(res.WinLoss.Reasons, func(i, j int) bool {
+		a, b := res.WinLoss.Reasons[i], res.WinLoss.Reasons[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.SPHStatus+a.Category < b.SPHStatus+b.Category
+	})
+
+	// --- Timeline bulanan + YTD ---
+	loc := mustLoadLocation("Asia/Jakarta")
+	res.Year = time.Now().In(loc).Year()
+
+	tRows, err := database.Pool.Query(ctx, fmt.Sprintf(`
+		SELECT
+			to_char(rp.month, 'YYYY-MM') AS ym,
+			COALESCE(SUM(rp.target_revenue), 0)::float8,
+			COALESCE(SUM(rp.target_realization), 0)::float8
+		FROM project_revenue_plan rp
+		JOIN projects p ON p.id = rp.project_id
+		WHERE %s
+		GROUP BY ym
+		ORDER BY ym ASC
+	`, where), args...)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	defer tRows.Close()
+
+	ytdPrefix := fmt.Sprintf("%04d-", res.Year)
+	res.Timeline = []models.CustomerTimelinePoint{}
+	for tRows.Next() {
+		var pt models.CustomerTimelinePoint
+		if err := tRows.Scan(&pt.Month, &pt.TargetRevenue, &pt.Realization); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		res.Timeline = append(res.Timeline, pt)
+
+		if strings.HasPrefix(pt.Month, ytdPrefix) {
+			res.YTD.TargetRevenue += pt.TargetRevenue
+			res.YTD.Realization += pt.Realization
+		}
+	}
+	tRows.Close()
+	res.YTD.AchievementPct = pct(res.YTD.Realization, res.YTD.TargetRevenue)
+
+	// --- Post-PO stage yang belum Done (project sudah Closing) ---
+	pRows, err := database.Pool.Query(ctx, fmt.Sprintf(`
+		SELECT p.id, p.project_code, s.stage, COALESCE(s.status, 'Not Started'), s.date, s.note
+		FROM projects p
+		JOIN project_postpo_monitoring m ON m.project_id = p.id
+		CROSS JOIN LATERAL (VALUES
+			(1, m.stage1_status, m.stage1_date, m.stage1_note),
+			(2, m.stage2_status, m.stage2_date, m.stage2_note),
+			(3, m.stage3_status, m.stage3_date, m.stage3_note),
+			(4, m.stage4_status, m.stage4_date, m.stage4_note),
+			(5, m.stage5_status, m.stage5_date, m.stage5_note)
+		) AS s(stage, status, date, note)
+		WHERE %s
+		  AND p.sales_stage >= 6
+		  AND COALESCE(s.status, 'Not Started') <> 'Done'
+		ORDER BY p.id DESC, s.stage ASC
+	`, where), args...)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	defer pRows.Close()
+
+	res.OpenPostPO = []models.CustomerOpenPostPO{}
+	for pRows.Next() {
+		var o models.CustomerOpenPostPO
+		if err := pRows.Scan(&o.ProjectID, &o.ProjectCode, &o.Stage, &o.Status, &o.Date, &o.Note); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		res.OpenPostPO = append(res.OpenPostPO, o)
+	}
+
+	c.JSON(200, res)
+}
+
+func sortedSummaryGroups(m map[string]*models.CustomerSummaryGroup) []models.CustomerSummaryGroup {
+	out := make([]models.CustomerSummaryGroup, 0, len(m))
+	for _, g := range m {
+		out = append(out, *g)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
@@ -1,13 +1,20 @@
 package handlers
 
 import (
+	"strconv"
+	"strings"
+
 	"sales-system-backend/database"
 
 	"github.com/gin-gonic/gin"
 )
 
 func UpdateCustomer(c *gin.Context) {
-	id := c.Param("id")
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid customer id"})
+		return
+	}
 
 	var body struct {
 		Name     *string `json:"name"`
@@ -20,12 +27,63 @@ func UpdateCustomer(c *gin.Context) {
 		return
 	}
 
-	_, err := database.Pool.Exec(c, `
+	if !checkCustomerVisible(c, id) {
+		return
+	}
+
+	// rename → cek duplikat sama seperti CreateCustomer (kecuali customer ini sendiri)
+	if body.Name != nil {
+		name := strings.TrimSpace(*body.Name)
+		if name == "" {
+			c.JSON(400, gin.H{"error": "name cannot be empty"})
+			return
+		}
+		body.Name = &name
+
+		dupID, dupName, err := findCustomerByNormalizedName(c, database.Pool, name, id)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		if dupID != 0 {
+			respondCustomerDuplicate(c, dupID, dupName)
+			return
+		}
+	}
+
+	// industry / region: nil = tidak diubah, "" = dikosongkan, selain itu harus dikenal
+	if body.Industry != nil {
+		industry, ok, err := resolveIndustry(c, *body.Industry)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		if !ok {
+			c.JSON(400, gin.H{"error": "unknown industry: " + *body.Industry})
+			return
+		}
+		body.Industry = &industry
+	}
+
+	if body.Region != nil {
+		region, ok, err := resolveRegion(c, *body.Region)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		if !ok {
+			c.JSON(400, gin.H{"error": "unknown region: " + *body.Region})
+			return
+		}
+		body.Region = &region
+	}
+
+	_, err = database.Pool.Exec(c, `
 		UPDATE customers
 		SET 
 			name = COALESCE($1, name),
-			industry = COALESCE($2, industry),
-			region = COALESCE($3, region),
+			industry = CASE WHEN $2::text IS NULL THEN industry ELSE NULLIF($2, '') END,
+			region = CASE WHEN $3::text IS NULL THEN region ELSE NULLIF($3, '') END,
 			updated_at = NOW()
 		WHERE id=$4
 	`, body.Name, body.Industry, body.Region, id)
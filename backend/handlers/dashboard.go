@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -99,6 +100,10 @@ type DashboardResponse struct {
 	DivisionBreakdown    []DashboardBreakdownItem `json:"division_breakdown"`
 	TypeBreakdown        []DashboardBreakdownItem `json:"type_breakdown"`
 	CustomerContribution []DashboardBreakdownItem `json:"customer_contribution"`
+	IndustryBreakdown    []DashboardBreakdownItem `json:"industry_breakdown"`
+	RegionBreakdown      []DashboardBreakdownItem `json:"region_breakdown"`
+	ProductLineBreakdown []DashboardBreakdownItem `json:"product_line_breakdown"`
+	ProductBreakdown     []DashboardBreakdownItem `json:"product_breakdown"`
 	StatusBreakdown      []DashboardBreakdownItem `json:"status_breakdown"`
 	Budget               DashboardBudget          `json:"budget"`
 	Forecast             []DashboardForecastPoint `json:"forecast"`
@@ -156,6 +161,12 @@ func conv(real, target float64) float64 {
 	return real / target
 }
 
+// ?customer_group=true → customer di-rollup ke group paling atas (lihat view customer_group_roots)
+func rollupCustomerGroups(c *gin.Context) bool {
+	v := strings.ToLower(strings.TrimSpace(c.Query("customer_group")))
+	return v == "true" || v == "1"
+}
+
 func mustLoadLocation(name string) *time.Location {
 	loc, err := time.LoadLocation(name)
 	if err != nil {
@@ -471,21 +482,65 @@ func GetDashboard(c *gin.Context) {
 		types = append(types, DashboardBreakdownItem{Label: label, Value: val})
 	}
 
+	// ============================================
+	// INDUSTRY + REGION BREAKDOWN (PROJECT)
+	// nilai kanonik dari reference data; kosong / "-" → Unknown
+	// ============================================
+	industries, err := loadCustomerAttrBreakdown(ctx, "c.industry", projectWhere, projectArgs)
+	if err != nil {
+		log.Println("INDUSTRY BREAKDOWN ERROR:", err)
+		c.JSON(500, gin.H{"error": "failed to load industry breakdown"})
+		return
+	}
+
+	regions, err := loadCustomerAttrBreakdown(ctx, "c.region", projectWhere, projectArgs)
+	if err != nil {
+		log.Println("REGION BREAKDOWN ERROR:", err)
+		c.JSON(500, gin.H{"error": "failed to load region breakdown"})
+		return
+	}
+
+	// ============================================
+	// PRODUCT LINE + PRODUCT BREAKDOWN (project_line_items)
+	// ============================================
+	productLines, err := loadProductBreakdown(ctx, "pr.category", projectWhere, projectArgs, 0)
+	if err != nil {
+		log.Println("PRODUCT LINE BREAKDOWN ERROR:", err)
+		c.JSON(500, gin.H{"error": "failed to load product line breakdown"})
+		return
+	}
+
+	products, err := loadProductBreakdown(ctx, "pr.name", projectWhere, projectArgs, 10)
+	if err != nil {
+		log.Println("PRODUCT BREAKDOWN ERROR:", err)
+		c.JSON(500, gin.H{"error": "failed to load product breakdown"})
+		return
+	}
+
 	// ============================================
 	// CUSTOMER CONTRIBUTION (PROJECT)
+	// customer_group=true → rollup ke group customer
 	// ============================================
+	customerLabel := "COALESCE(c.name,'Unknown')"
+	customerGroupJoin := ""
+	if rollupCustomerGroups(c) {
+		customerLabel = "COALESCE(cg.root_name, c.name, 'Unknown')"
+		customerGroupJoin = "LEFT JOIN customer_group_roots cg ON cg.customer_id = p.customer_id"
+	}
+
 	custQuery := fmt.Sprintf(`
 		SELECT 
-			COALESCE(c.name,'Unknown'),
+			%s,
 			COALESCE(SUM(COALESCE(r.target_realization,0)),0)
 		FROM projects p
 		LEFT JOIN customers c ON c.id = p.customer_id
+		%s
 		LEFT JOIN project_revenue_plan r ON r.project_id = p.id
 		WHERE %s
-		GROUP BY c.name
+		GROUP BY 1
 		ORDER BY SUM(COALESCE(r.target_realization,0)) DESC
 		LIMIT 6
-	`, projectWhere)
+	`, customerLabel, customerGroupJoin, projectWhere)
 
 	rowsCust, err := database.Pool.Query(ctx, custQuery, projectArgs...)
 	if err != nil {
@@ -605,16 +660,17 @@ func GetDashboard(c *gin.Context) {
 	// ============================================
 	customerTableQuery := fmt.Sprintf(`
 		SELECT 
-			COALESCE(c.name, 'Unknown') AS customer,
+			%s AS customer,
 			COALESCE(SUM(COALESCE(r.target_revenue,0)), 0) AS total_target,
 			COALESCE(SUM(COALESCE(r.target_realization,0)), 0) AS total_real
 		FROM projects p
 		LEFT JOIN customers c ON c.id = p.customer_id
+		%s
 		LEFT JOIN project_revenue_plan r ON r.project_id = p.id
 		WHERE %s
-		GROUP BY c.name
+		GROUP BY 1
 		ORDER BY total_real DESC
-	`, projectWhere)
+	`, customerLabel, customerGroupJoin, projectWhere)
 
 	rowsCT, err := database.Pool.Query(ctx, customerTableQuery, projectArgs...)
 	if err != nil {
@@ -690,6 +746,10 @@ func GetDashboard(c *gin.Context) {
 		DivisionBreakdown:    divisions,
 		TypeBreakdown:        types,
 		CustomerContribution: customers,
+		IndustryBreakdown:    industries,
+		RegionBreakdown:      regions,
+		ProductLineBreakdown: productLines,
+		ProductBreakdown:     products,
 		StatusBreakdown:      statuses,
 		Budget: DashboardBudget{
 			TotalBudget:      totalBudget,
@@ -704,6 +764,77 @@ func GetDashboard(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// breakdown target revenue per atribut customer (c.industry / c.region)
+func loadCustomerAttrBreakdown(ctx context.Context, col, projectWhere string, projectArgs []any) ([]DashboardBreakdownItem, error) {
+	q := fmt.Sprintf(`
+		SELECT 
+			COALESCE(NULLIF(NULLIF(btrim(%s), ''), '-'), 'Unknown') AS label,
+			COALESCE(SUM(COALESCE(r.target_revenue,0)),0)
+		FROM projects p
+		LEFT JOIN customers c ON c.id = p.customer_id
+		LEFT JOIN project_revenue_plan r ON r.project_id = p.id
+		WHERE %s
+		GROUP BY 1
+		ORDER BY 2 DESC
+	`, col, projectWhere)
+
+	rows, err := database.Pool.Query(ctx, q, projectArgs...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []DashboardBreakdownItem{}
+	for rows.Next() {
+		var it DashboardBreakdownItem
+		if err := rows.Scan(&it.Label, &it.Value); err != nil {
+			return nil, err
+		}
+		items = append(items, it)
+	}
+
+	return items, rows.Err()
+}
+
+// loadProductBreakdown → SUM(line item amount) per kolom produk untuk project yang lolos filter.
+// Filter project memakai alias r (revenue plan), jadi project di-dedup lewat subquery.
+func loadProductBreakdown(ctx context.Context, col, projectWhere string, projectArgs []any, limit int) ([]DashboardBreakdownItem, error) {
+	q := fmt.Sprintf(`
+		SELECT %s AS label, COALESCE(SUM(li.amount), 0)::float8
+		FROM project_line_items li
+		JOIN products pr ON pr.id = li.product_id
+		WHERE li.project_id IN (
+			SELECT p.id
+			FROM projects p
+			LEFT JOIN customers c ON c.id = p.customer_id
+			LEFT JOIN project_revenue_plan r ON r.project_id = p.id
+			WHERE %s
+		)
+		GROUP BY 1
+		ORDER BY 2 DESC
+	`, col, projectWhere)
+	if limit > 0 {
+		q += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := database.Pool.Query(ctx, q, projectArgs...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []DashboardBreakdownItem{}
+	for rows.Next() {
+		var it DashboardBreakdownItem
+		if err := rows.Scan(&it.Label, &it.Value); err != nil {
+			return nil, err
+		}
+		items = append(items, it)
+	}
+
+	return items, rows.Err()
+}
+
 // ======================================================================
 // FILTER: PROJECT DASHBOARD (alias: p, r, c)
 // - support multi status/stage/type
@@ -811,11 +942,17 @@ func buildProjectDashboardFilter(
 	}
 
 	if customer != "" && strings.ToUpper(customer) != "ALL" {
-		conds = append(conds, fmt.Sprintf("COALESCE(c.name,'') = $%d", i))
+		conds = append(conds, customerFilterCond(c, i))
 		args = append(args, customer)
 		i++
 	}
 
+	if cond, arg, ok := ownerFilterCond(c, i); ok {
+		conds = append(conds, cond)
+		args = append(args, arg)
+		i++
+	}
+
 	// DATE FILTER
 	if !fromDate.IsZero() {
 		if applyRevenueDate {
@@ -927,6 +1064,18 @@ func buildBudgetDashboardFilter(c *gin.Context, role, userDiv string) (string, [
 	return strings.Join(conds, " AND "), args, nil
 }
 
+// filter customer by nama; kalau rollup group aktif, nama group juga match
+// semua project milik anak-anaknya
+func customerFilterCond(c *gin.Context, i int) string {
+	if rollupCustomerGroups(c) {
+		return fmt.Sprintf(`(
+			COALESCE(c.name,'') = $%d
+			OR p.customer_id IN (SELECT customer_id FROM customer_group_roots WHERE root_name = $%d)
+		)`, i, i)
+	}
+	return fmt.Sprintf("COALESCE(c.name,'') = $%d", i)
+}
+
 func buildPipelineFilter(
 	c *gin.Context,
 	role, userDiv string,
@@ -1025,11 +1174,17 @@ func buildPipelineFilter(
 	}
 
 	if customer != "" && strings.ToUpper(customer) != "ALL" {
-		conds = append(conds, fmt.Sprintf("COALESCE(c.name,'') = $%d", i))
+		conds = append(conds, customerFilterCond(c, i))
 		args = append(args, customer)
 		i++
 	}
 
+	if cond, arg, ok := ownerFilterCond(c, i); ok {
+		conds = append(conds, cond)
+		args = append(args, arg)
+		i++
+	}
+
 	// ✅ FILTER WAKTU TETAP ADA, tapi 1 project = 1 pipeline:
 	// pakai EXISTS ke revenue_plan (overlap range)
 	if !fromDate.IsZero() && !toDate.IsZero() {
@@ -3,11 +3,14 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"sales-system-backend/database"
 
+	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5"
 )
 
@@ -51,6 +54,9 @@ func NormalizeDivision(d string) string {
 	return strings.Title(d)
 }
 
+// urutan tampil di report / leaderboard
+var canonicalDivisions = []string{"NetCo", "Oil Mining & Goverments", "IT Solutions"}
+
 func isValidDivision(d string) bool {
 	switch d {
 	case "NetCo", "Oil Mining & Goverments", "IT Solutions":
@@ -127,10 +133,183 @@ func generateProjectCodeTx(ctx context.Context, tx pgx.Tx, division string) (str
 	return fmt.Sprintf("PRJ-%s-%d-%04d", code, year, seq), nil
 }
 
+// =====================================================
+//  SPH NUMBER GENERATOR
+// =====================================================
+
+const defaultSPHNumberFormat = "{seq}/SPH/{divcode}/{roman_month}/{year}"
+
+var romanMonths = []string{"I", "II", "III", "IV", "V", "VI", "VII", "VIII", "IX", "X", "XI", "XII"}
+
+// formatSPHNumber mengisi token format; dipakai generator + preview
+func formatSPHNumber(format string, padding int, division string, seq int, at time.Time) string {
+	return strings.NewReplacer(
+		"{seq}", fmt.Sprintf("%0*d", padding, seq),
+		"{divcode}", divisionCode(division),
+		"{roman_month}", romanMonths[at.Month()-1],
+		"{month}", fmt.Sprintf("%02d", int(at.Month())),
+		"{year}", strconv.Itoa(at.Year()),
+		"{yy}", fmt.Sprintf("%02d", at.Year()%100),
+	).Replace(format)
+}
+
+// generateSPHNumberTx → nomor SPH berikutnya untuk divisi (counter per tahun).
+// Nomor yang sudah dipakai (mis. input manual lama) dilewati.
+func generateSPHNumberTx(ctx context.Context, tx pgx.Tx, division string, at time.Time) (string, error) {
+	division = NormalizeDivision(division)
+	code := divisionCode(division)
+
+	format := defaultSPHNumberFormat
+	padding := 3
+	err := tx.QueryRow(ctx,
+		`SELECT format, seq_padding FROM sph_number_formats WHERE division = $1`, division,
+	).Scan(&format, &padding)
+	if err != nil && err != pgx.ErrNoRows {
+		return "", err
+	}
+
+	for attempt := 0; attempt < 100; attempt++ {
+		var seq int
+		err := tx.QueryRow(ctx, `
+			INSERT INTO sph_number_counters (year, division_code, last_seq)
+			VALUES ($1, $2, 1)
+			ON CONFLICT (year, division_code)
+			DO UPDATE SET last_seq = sph_number_counters.last_seq + 1
+			RETURNING last_seq
+		`, at.Year(), code).Scan(&seq)
+		if err != nil {
+			return "", err
+		}
+
+		number := formatSPHNumber(format, padding, division, seq, at)
+
+		taken, err := sphNumberTakenTx(ctx, tx, number, 0)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return number, nil
+		}
+	}
+
+	return "", fmt.Errorf("unable to generate a free SPH number for %s", division)
+}
+
+// sphNumberTakenTx → true jika nomor sudah dipakai project lain
+func sphNumberTakenTx(ctx context.Context, tx pgx.Tx, number string, excludeProjectID int64) (bool, error) {
+	var taken bool
+	err := tx.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM projects WHERE sph_number = $1 AND id <> $2)
+	`, number, excludeProjectID).Scan(&taken)
+	return taken, err
+}
+
+// resolveSPHNumberTx dipakai create / update project:
+//   - nomor diisi manual → harus unik
+//   - kosong + release status "Yes" → nomor lama dipertahankan, kalau belum ada digenerate
+//   - selain itu kosong (nil)
+//
+// conflict != "" → nomor manual sudah dipakai project lain.
+func resolveSPHNumberTx(
+	ctx context.Context,
+	tx pgx.Tx,
+	projectID int64,
+	division string,
+	requested, existing *string,
+	releaseStatus string,
+	at time.Time,
+) (number *string, conflict string, err error) {
+	if requested != nil {
+		if n := strings.TrimSpace(*requested); n != "" {
+			taken, err := sphNumberTakenTx(ctx, tx, n, projectID)
+			if err != nil {
+				return nil, "", err
+			}
+			if taken {
+				return nil, fmt.Sprintf("sph_number %s is already used by another project", n), nil
+			}
+			return &n, "", nil
+		}
+	}
+
+	if releaseStatus != "Yes" {
+		return nil, "", nil
+	}
+
+	if existing != nil && strings.TrimSpace(*existing) != "" {
+		return existing, "", nil
+	}
+
+	n, err := generateSPHNumberTx(ctx, tx, division, at)
+	if err != nil {
+		return nil, "", err
+	}
+	return &n, "", nil
+}
+
+// =====================================================
+//  PROJECT ACL
+// =====================================================
+
+type projectRef struct {
+	ID         int64
+	Division   string
+	CustomerID *int64
+}
+
+// checkProjectAccess load project + cek divisi user (sama seperti GetProject).
+// Kalau gagal, response sudah dikirim dan ok=false.
+func checkProjectAccess(c *gin.Context, projectID int64) (projectRef, bool) {
+	p := projectRef{ID: projectID}
+
+	err := database.Pool.QueryRow(c.Request.Context(),
+		`SELECT division, customer_id FROM projects WHERE id = $1`,
+		projectID,
+	).Scan(&p.Division, &p.CustomerID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+		return p, false
+	}
+
+	p.Division = NormalizeDivision(p.Division)
+
+	role := c.GetString("role")
+	userDivision := NormalizeDivision(c.GetString("division"))
+	if role == "user" && (userDivision == "" || userDivision != p.Division) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "forbidden: cannot access project in another division",
+		})
+		return p, false
+	}
+
+	return p, true
+}
+
 // =====================================================
 //  BUDGET HELPERS
 // =====================================================
 
+// kategori realisasi budget (sama dengan pilihan di FE)
+var budgetCategories = []string{
+	"AKOMODASI PERDIN MARKETING",
+	"ENT & REP",
+	"OPERASIONAL MARKETING",
+	"AKOMODASI TENDER",
+	"PURCHASE ORDER",
+	"FIELD TRIAL LITBANG",
+}
+
+// normalizeBudgetCategory mengembalikan nama kategori kanonik (case-insensitive)
+func normalizeBudgetCategory(cat string) (string, bool) {
+	cat = strings.TrimSpace(cat)
+	for _, c := range budgetCategories {
+		if strings.EqualFold(c, cat) {
+			return c, true
+		}
+	}
+	return cat, false
+}
+
 func GetTotalRealization(ctx context.Context, budgetID int64) (float64, error) {
 	var total float64
 
@@ -160,3 +339,94 @@ func BudgetExists(ctx context.Context, division string, month time.Time) (bool,
 
 	return exists, err
 }
+
+// =====================================================
+// PROJECT OWNER FILTER (alias: p)
+// ?mine=true → project milik user token; ?owner_id= → project milik user tsb
+// owner utama maupun co-owner dihitung
+// =====================================================
+
+func ownerFilterCond(c *gin.Context, i int) (string, any, bool) {
+	var ownerID int64
+
+	if v := strings.TrimSpace(c.Query("mine")); v == "true" || v == "1" {
+		ownerID = c.GetInt64("user_id")
+	} else if v := strings.TrimSpace(c.Query("owner_id")); v != "" && strings.ToUpper(v) != "ALL" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return "", nil, false
+		}
+		ownerID = id
+	}
+
+	if ownerID <= 0 {
+		return "", nil, false
+	}
+
+	return fmt.Sprintf(`(
+		p.owner_user_id = $%[1]d
+		OR EXISTS (SELECT 1 FROM project_co_owners po WHERE po.project_id = p.id AND po.user_id = $%[1]d)
+	)`, i), ownerID, true
+}
+
+// =====================================================
+// DEPENDENTS (cek sebelum hard delete)
+// =====================================================
+
+// dependentRef = satu jenis record yang menunjuk ke row yang mau dihapus
+type dependentRef struct {
+	Type   string // key di response conflict
+	Table  string
+	Column string // kolom FK ke row yang dihapus
+	Label  string // ekspresi SQL untuk label item
+}
+
+type DependentItem struct {
+	ID    int64  `json:"id"`
+	Label string `json:"label"`
+}
+
+type DependentGroup struct {
+	Type  string          `json:"type"`
+	Count int64           `json:"count"`
+	Items []DependentItem `json:"items"` // maksimal 20 sample
+}
+
+// loadDependents → hanya group dengan count > 0
+func loadDependents(ctx context.Context, db dbtx, refs []dependentRef, id int64) ([]DependentGroup, error) {
+	out := []DependentGroup{}
+
+	for _, ref := range refs {
+		g := DependentGroup{Type: ref.Type, Items: []DependentItem{}}
+
+		if err := db.QueryRow(ctx,
+			fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s = $1`, ref.Table, ref.Column), id,
+		).Scan(&g.Count); err != nil {
+			return nil, err
+		}
+		if g.Count == 0 {
+			continue
+		}
+
+		rows, err := db.Query(ctx, fmt.Sprintf(
+			`SELECT id, COALESCE((%s)::text, '') FROM %s WHERE %s = $1 ORDER BY id LIMIT 20`,
+			ref.Label, ref.Table, ref.Column,
+		), id)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var it DependentItem
+			if err := rows.Scan(&it.ID, &it.Label); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			g.Items = append(g.Items, it)
+		}
+		rows.Close()
+
+		out = append(out, g)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,937 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"sales-system-backend/database"
+	"sales-system-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// sumber lead (dipakai juga untuk projects.lead_source)
+var leadSources = []string{
+	"Event",
+	"Inbound",
+	"Referral",
+	"Website",
+	"Cold Call",
+	"Partner",
+	"Existing Customer",
+	"Other",
+}
+
+// normalizeLeadSource mengembalikan nama source kanonik (case-insensitive)
+func normalizeLeadSource(src string) (string, bool) {
+	src = strings.TrimSpace(src)
+	for _, s := range leadSources {
+		if strings.EqualFold(s, src) {
+			return s, true
+		}
+	}
+	return src, false
+}
+
+const leadColumns = `
+	l.id, l.name, l.company_name, l.email, l.phone, l.title,
+	l.source, l.source_detail, l.division, l.owner_user_id, ow.username, l.status,
+	l.has_budget, l.has_authority, l.has_need, l.timeline,
+	l.estimated_value::float8, l.qualification_note, l.qualified_at,
+	l.customer_id, cu.name, l.converted_project_id, l.converted_at,
+	l.created_by, l.created_at, l.updated_at
+`
+
+const leadJoins = `
+	FROM leads l
+	LEFT JOIN users ow ON ow.id = l.owner_user_id
+	LEFT JOIN customers cu ON cu.id = l.customer_id
+`
+
+func scanLead(row pgx.Row) (models.Lead, error) {
+	var l models.Lead
+	err := row.Scan(
+		&l.ID, &l.Name, &l.CompanyName, &l.Email, &l.Phone, &l.Title,
+		&l.Source, &l.SourceDetail, &l.Division, &l.OwnerUserID, &l.OwnerName, &l.Status,
+		&l.HasBudget, &l.HasAuthority, &l.HasNeed, &l.Timeline,
+		&l.EstimatedValue, &l.QualificationNote, &l.QualifiedAt,
+		&l.CustomerID, &l.CustomerName, &l.ConvertedProjectID, &l.ConvertedAt,
+		&l.CreatedBy, &l.CreatedAt, &l.UpdatedAt,
+	)
+	return l, err
+}
+
+func loadLead(ctx context.Context, db dbtx, id int64, forUpdate bool) (models.Lead, error) {
+	q := `SELECT ` + leadColumns + leadJoins + ` WHERE l.id = $1`
+	if forUpdate {
+		q += ` FOR UPDATE OF l`
+	}
+	return scanLead(db.QueryRow(ctx, q, id))
+}
+
+// checkLeadAccess → 404 / 403 (divisi) sama seperti checkProjectAccess.
+// Kalau gagal, response sudah dikirim dan ok=false.
+func checkLeadAccess(c *gin.Context, db dbtx, id int64, forUpdate bool) (models.Lead, bool) {
+	l, err := loadLead(c.Request.Context(), db, id, forUpdate)
+	if err == pgx.ErrNoRows {
+		c.JSON(404, gin.H{"error": "lead not found"})
+		return l, false
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return l, false
+	}
+
+	l.Division = NormalizeDivision(l.Division)
+
+	userDivision := NormalizeDivision(c.GetString("division"))
+	if c.GetString("role") == "user" && (userDivision == "" || userDivision != l.Division) {
+		c.JSON(403, gin.H{"error": "forbidden: cannot access lead in another division"})
+		return l, false
+	}
+
+	return l, true
+}
+
+func parseLeadID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid lead id"})
+		return 0, false
+	}
+	return id, true
+}
+
+// validateLeadRequest → normalisasi source/status/divisi; return pesan error
+func validateLeadRequest(c *gin.Context, req *models.LeadRequest) string {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return "name is required"
+	}
+
+	src, ok := normalizeLeadSource(req.Source)
+	if !ok {
+		return "invalid source"
+	}
+	req.Source = src
+
+	if req.Status != nil {
+		st := strings.ToLower(strings.TrimSpace(*req.Status))
+		if st != "new" && st != "contacted" {
+			return "status must be new or contacted (use /qualify or /convert)"
+		}
+		req.Status = &st
+	}
+
+	req.Division = NormalizeDivision(req.Division)
+	if c.GetString("role") == "user" {
+		req.Division = NormalizeDivision(c.GetString("division"))
+	}
+	if !isValidDivision(req.Division) {
+		return "invalid division"
+	}
+
+	if req.EstimatedValue != nil && *req.EstimatedValue < 0 {
+		return "estimated_value must be >= 0"
+	}
+
+	return ""
+}
+
+// ======================================================
+// LIST — GET /leads?division=&status=&source=&q=&mine=&owner_id=&unassigned=
+// ======================================================
+
+func ListLeads(c *gin.Context) {
+	role := c.GetString("role")
+	userDiv := NormalizeDivision(c.GetString("division"))
+
+	conds := []string{"1=1"}
+	args := []any{}
+	i := 1
+
+	division := NormalizeDivision(strings.TrimSpace(c.Query("division")))
+	if role == "user" {
+		division = userDiv
+	}
+	if division != "" && strings.ToUpper(division) != "ALL" {
+		conds = append(conds, fmt.Sprintf("l.division = $%d", i))
+		args = append(args, division)
+		i++
+	}
+
+	if v := strings.TrimSpace(c.Query("status")); v != "" && strings.ToUpper(v) != "ALL" {
+		conds = append(conds, fmt.Sprintf("l.status = $%d", i))
+		args = append(args, strings.ToLower(v))
+		i++
+	}
+
+	if v := strings.TrimSpace(c.Query("source")); v != "" && strings.ToUpper(v) != "ALL" {
+		src, _ := normalizeLeadSource(v)
+		conds = append(conds, fmt.Sprintf("l.source = $%d", i))
+		args = append(args, src)
+		i++
+	}
+
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		conds = append(conds, fmt.Sprintf(
+			"(l.name ILIKE $%[1]d OR l.company_name ILIKE $%[1]d OR l.email ILIKE $%[1]d)", i))
+		args = append(args, "%"+q+"%")
+		i++
+	}
+
+	if v := strings.TrimSpace(c.Query("unassigned")); v == "true" || v == "1" {
+		conds = append(conds, "l.owner_user_id IS NULL")
+	} else if v := strings.TrimSpace(c.Query("mine")); v == "true" || v == "1" {
+		conds = append(conds, fmt.Sprintf("l.owner_user_id = $%d", i))
+		args = append(args, c.GetInt64("user_id"))
+		i++
+	} else if v := strings.TrimSpace(c.Query("owner_id")); v != "" && strings.ToUpper(v) != "ALL" {
+		ownerID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid owner_id"})
+			return
+		}
+		conds = append(conds, fmt.Sprintf("l.owner_user_id = $%d", i))
+		args = append(args, ownerID)
+		i++
+	}
+
+	rows, err := database.Pool.Query(c, `SELECT `+leadColumns+leadJoins+`
+		WHERE `+strings.Join(conds, " AND ")+`
+		ORDER BY l.created_at DESC, l.id DESC
+	`, args...)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	list := []models.Lead{}
+	for rows.Next() {
+		l, err := scanLead(rows)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		list = append(list, l)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, list)
+}
+
+// GET /leads/sources → daftar source untuk dropdown FE
+func ListLeadSources(c *gin.Context) {
+	c.JSON(200, leadSources)
+}
+
+// GET /leads/:id
+func GetLead(c *gin.Context) {
+	id, ok := parseLeadID(c)
+	if !ok {
+		return
+	}
+
+	l, ok := checkLeadAccess(c, database.Pool, id, false)
+	if !ok {
+		return
+	}
+
+	c.JSON(200, l)
+}
+
+// ======================================================
+// CREATE — POST /leads
+// owner default: pembuat (role user); admin boleh kosong (belum di-assign)
+// ======================================================
+
+func CreateLead(c *gin.Context) {
+	var req models.LeadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "name and source are required"})
+		return
+	}
+
+	if msg := validateLeadRequest(c, &req); msg != "" {
+		c.JSON(400, gin.H{"error": msg})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	ownerID := req.OwnerUserID
+	if ownerID == nil && c.GetString("role") == "user" && userID > 0 {
+		ownerID = &userID
+	}
+
+	status := "new"
+	if req.Status != nil {
+		status = *req.Status
+	}
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	if ownerID != nil {
+		if msg := validateProjectOwnerTx(ctx, tx, *ownerID, req.Division); msg != "" {
+			c.JSON(400, gin.H{"error": msg})
+			return
+		}
+	}
+
+	var id int64
+	err = tx.QueryRow(ctx, `
+		INSERT INTO leads (
+			name, company_name, email, phone, title,
+			source, source_detail, division, owner_user_id, status,
+			estimated_value, created_by
+		)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''),
+		        $6, NULLIF($7, ''), $8, $9, $10, $11, NULLIF($12::bigint, 0))
+		RETURNING id
+	`,
+		req.Name, req.CompanyName, req.Email, req.Phone, req.Title,
+		req.Source, req.SourceDetail, req.Division, ownerID, status,
+		req.EstimatedValue, userID,
+	).Scan(&id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if ownerID != nil && *ownerID != userID {
+		if err := notifyLeadOwner(ctx, tx, *ownerID, id, req.Name); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	c.JSON(201, gin.H{"id": id})
+}
+
+// ======================================================
+// UPDATE — PUT /leads/:id (owner lewat /assign, kualifikasi lewat /qualify)
+// ======================================================
+
+func UpdateLead(c *gin.Context) {
+	id, ok := parseLeadID(c)
+	if !ok {
+		return
+	}
+
+	var req models.LeadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "name and source are required"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	l, ok := checkLeadAccess(c, tx, id, true)
+	if !ok {
+		return
+	}
+	if l.Status == "converted" {
+		c.JSON(409, gin.H{"error": "converted lead cannot be edited"})
+		return
+	}
+
+	if req.Division == "" {
+		req.Division = l.Division
+	}
+	if msg := validateLeadRequest(c, &req); msg != "" {
+		c.JSON(400, gin.H{"error": msg})
+		return
+	}
+
+	// pindah divisi → owner lama harus tetap valid
+	if req.Division != l.Division && l.OwnerUserID != nil {
+		if msg := validateProjectOwnerTx(ctx, tx, *l.OwnerUserID, req.Division); msg != "" {
+			c.JSON(400, gin.H{"error": msg + "; reassign the lead first"})
+			return
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE leads SET
+			name = $1,
+			company_name = NULLIF($2, ''),
+			email = NULLIF($3, ''),
+			phone = NULLIF($4, ''),
+			title = NULLIF($5, ''),
+			source = $6,
+			source_detail = NULLIF($7, ''),
+			division = $8,
+			status = COALESCE($9, status),
+			estimated_value = $10,
+			updated_at = NOW()
+		WHERE id = $11
+	`,
+		req.Name, req.CompanyName, req.Email, req.Phone, req.Title,
+		req.Source, req.SourceDetail, req.Division, req.Status,
+		req.EstimatedValue, id,
+	); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "updated"})
+}
+
+// DELETE /leads/:id — lead yang sudah convert tetap disimpan (atribusi)
+func DeleteLead(c *gin.Context) {
+	id, ok := parseLeadID(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	l, ok := checkLeadAccess(c, database.Pool, id, false)
+	if !ok {
+		return
+	}
+	if l.Status == "converted" {
+		c.JSON(409, gin.H{
+			"error":      "converted lead cannot be deleted",
+			"project_id": l.ConvertedProjectID,
+		})
+		return
+	}
+
+	if _, err := database.Pool.Exec(ctx, `DELETE FROM leads WHERE id = $1 AND status <> 'converted'`, id); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "deleted"})
+}
+
+// ======================================================
+// ASSIGN — PUT /leads/:id/assign {owner_user_id}
+// ======================================================
+
+func AssignLead(c *gin.Context) {
+	id, ok := parseLeadID(c)
+	if !ok {
+		return
+	}
+
+	var req models.AssignLeadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "owner_user_id is required"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	l, ok := checkLeadAccess(c, tx, id, true)
+	if !ok {
+		return
+	}
+	if l.Status == "converted" {
+		c.JSON(409, gin.H{"error": "converted lead cannot be reassigned; reassign the project instead"})
+		return
+	}
+
+	if msg := validateProjectOwnerTx(ctx, tx, req.OwnerUserID, l.Division); msg != "" {
+		c.JSON(400, gin.H{"error": msg})
+		return
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE leads SET owner_user_id = $1, updated_at = NOW() WHERE id = $2
+	`, req.OwnerUserID, id); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	// notif ke owner baru (kecuali assign ke diri sendiri)
+	changed := l.OwnerUserID == nil || *l.OwnerUserID != req.OwnerUserID
+	if changed && req.OwnerUserID != c.GetInt64("user_id") {
+		if err := notifyLeadOwner(ctx, tx, req.OwnerUserID, id, l.Name); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "assigned", "owner_user_id": req.OwnerUserID})
+}
+
+func notifyLeadOwner(ctx context.Context, db dbtx, ownerID, leadID int64, name string) error {
+	return createNotification(ctx, db, ownerID,
+		"lead_assigned",
+		"Lead assigned: "+name,
+		fmt.Sprintf("You are now the owner of lead %s.", name),
+		"lead", leadID,
+	)
+}
+
+// ======================================================
+// QUALIFY — POST /leads/:id/qualify
+// {qualified, has_budget?, has_authority?, has_need?, timeline?, estimated_value?, note?}
+// ======================================================
+
+func QualifyLead(c *gin.Context) {
+	id, ok := parseLeadID(c)
+	if !ok {
+		return
+	}
+
+	var req models.QualifyLeadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "qualified is required"})
+		return
+	}
+
+	if req.Note != nil {
+		note := strings.TrimSpace(*req.Note)
+		req.Note = &note
+	}
+	if !*req.Qualified && (req.Note == nil || *req.Note == "") {
+		c.JSON(400, gin.H{"error": "note is required when lead is unqualified"})
+		return
+	}
+	if req.EstimatedValue != nil && *req.EstimatedValue < 0 {
+		c.JSON(400, gin.H{"error": "estimated_value must be >= 0"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	l, ok := checkLeadAccess(c, tx, id, true)
+	if !ok {
+		return
+	}
+	if l.Status == "converted" {
+		c.JSON(409, gin.H{"error": "lead already converted"})
+		return
+	}
+
+	status := "unqualified"
+	if *req.Qualified {
+		status = "qualified"
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE leads SET
+			status = $1,
+			has_budget = COALESCE($2, has_budget),
+			has_authority = COALESCE($3, has_authority),
+			has_need = COALESCE($4, has_need),
+			timeline = COALESCE(NULLIF($5, ''), timeline),
+			estimated_value = COALESCE($6, estimated_value),
+			qualification_note = COALESCE(NULLIF($7, ''), qualification_note),
+			qualified_at = NOW(),
+			updated_at = NOW()
+		WHERE id = $8
+	`,
+		status, req.HasBudget, req.HasAuthority, req.HasNeed,
+		req.Timeline, req.EstimatedValue, req.Note, id,
+	); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": status})
+}
+
+// ======================================================
+// CONVERT — POST /leads/:id/convert
+// customer: customer_id → link; tanpa id → cari nama (normalized) / buat baru
+// project: body sama dengan POST /projects, lewat createProjectTx
+// ======================================================
+
+func ConvertLead(c *gin.Context) {
+	id, ok := parseLeadID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ConvertLeadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	if !validateCreateProjectRequest(c, &req.Project) {
+		return
+	}
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	l, ok := checkLeadAccess(c, tx, id, true)
+	if !ok {
+		return
+	}
+	if l.Status == "converted" {
+		c.JSON(409, gin.H{"error": "lead already converted", "project_id": l.ConvertedProjectID})
+		return
+	}
+	if l.Status != "qualified" {
+		c.JSON(409, gin.H{"error": "lead must be qualified before conversion"})
+		return
+	}
+	if req.Project.Division != l.Division {
+		c.JSON(400, gin.H{"error": "project division must match lead division"})
+		return
+	}
+
+	// --- customer: link / dedup / buat baru ---
+	res := models.ConvertLeadResponse{LeadID: id}
+
+	switch {
+	case req.CustomerID != nil:
+		if !checkCustomerVisible(c, *req.CustomerID) {
+			return
+		}
+		res.CustomerID = *req.CustomerID
+
+	case l.CustomerID != nil:
+		res.CustomerID = *l.CustomerID
+
+	default:
+		name := ""
+		if req.CustomerName != nil {
+			name = strings.TrimSpace(*req.CustomerName)
+		}
+		if name == "" && l.CompanyName != nil {
+			name = strings.TrimSpace(*l.CompanyName)
+		}
+		if name == "" {
+			c.JSON(400, gin.H{"error": "customer_id or customer_name is required (lead has no company name)"})
+			return
+		}
+
+		customerID, created, status, err := findOrCreateLeadCustomerTx(c, tx, name, req.Industry, req.Region, l.Division)
+		if err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		res.CustomerID = customerID
+		res.CustomerCreated = created
+	}
+
+	req.Project.CustomerID = &res.CustomerID
+
+	// --- project: jalur yang sama dengan CreateProject ---
+	ownerID := c.GetInt64("user_id")
+	if l.OwnerUserID != nil {
+		ownerID = *l.OwnerUserID
+	}
+
+	source := l.Source
+	created, status, err := createProjectTx(ctx, tx, req.Project, ownerID, projectAttribution{
+		LeadID:     &l.ID,
+		LeadSource: &source,
+	})
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE leads SET
+			status = 'converted',
+			customer_id = $1,
+			converted_project_id = $2,
+			converted_at = NOW(),
+			updated_at = NOW()
+		WHERE id = $3
+	`, res.CustomerID, created.ID, id); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	res.ProjectID = created.ID
+	res.ProjectCode = created.ProjectCode
+	res.SPHNumber = created.SPHNumber
+
+	c.JSON(201, res)
+}
+
+// findOrCreateLeadCustomerTx → customer dengan nama (normalized) sama dipakai ulang
+// (hanya kalau terlihat oleh user, sama seperti CreateCustomer); kalau belum ada
+// dibuat baru dan dimiliki divisi lead. Error disertai HTTP status.
+func findOrCreateLeadCustomerTx(
+	c *gin.Context,
+	tx pgx.Tx,
+	name, industry, region, division string,
+) (int64, bool, int, error) {
+	ctx := c.Request.Context()
+
+	dupID, _, err := findCustomerByNormalizedName(ctx, tx, name, 0)
+	if err != nil {
+		return 0, false, 500, err
+	}
+	if dupID != 0 {
+		visible, err := customerVisible(c, dupID)
+		if err != nil {
+			return 0, false, 500, err
+		}
+		if !visible {
+			return 0, false, 409, errors.New("customer with a similar name already exists")
+		}
+		return dupID, false, 0, nil
+	}
+
+	// industry / region wajib dari reference data (sama seperti CreateCustomer)
+	industryName, ok, err := resolveIndustry(ctx, industry)
+	if err != nil {
+		return 0, false, 500, err
+	}
+	if !ok {
+		return 0, false, 400, fmt.Errorf("unknown industry: %s", industry)
+	}
+
+	regionName, ok, err := resolveRegion(ctx, region)
+	if err != nil {
+		return 0, false, 500, err
+	}
+	if !ok {
+		return 0, false, 400, fmt.Errorf("unknown region: %s", region)
+	}
+
+	var id int64
+	err = tx.QueryRow(ctx, `
+		INSERT INTO customers (name, industry, region)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''))
+		RETURNING id
+	`, name, industryName, regionName).Scan(&id)
+	if err != nil {
+		return 0, false, 500, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO customer_divisions (customer_id, division)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, id, division); err != nil {
+		return 0, false, 500, err
+	}
+
+	return id, true, 0, nil
+}
+
+// ======================================================
+// REPORT — GET /reports/lead-sources?division=&from=&to=
+// lead (funnel) + project ber-lead_source (pipeline / win) per source
+// from/to (YYYY-MM-DD) → created_at lead & project
+// ======================================================
+
+func GetLeadSourceReport(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	role := c.GetString("role")
+	userDiv := NormalizeDivision(c.GetString("division"))
+
+	division := NormalizeDivision(strings.TrimSpace(c.Query("division")))
+	if role == "user" {
+		division = userDiv
+	}
+	if strings.ToUpper(division) == "ALL" {
+		division = ""
+	}
+
+	var from, to *time.Time
+	for _, p := range []struct {
+		key string
+		dst **time.Time
+	}{{"from", &from}, {"to", &to}} {
+		v := strings.TrimSpace(c.Query(p.key))
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid " + p.key + " (YYYY-MM-DD)"})
+			return
+		}
+		*p.dst = &t
+	}
+
+	// $1 division ('' = semua), $2 from, $3 to (inklusif)
+	args := []any{division, from, to}
+
+	bySource := map[string]*models.LeadSourceReportRow{}
+	rowFor := func(src string) *models.LeadSourceReportRow {
+		if bySource[src] == nil {
+			bySource[src] = &models.LeadSourceReportRow{Source: src}
+		}
+		return bySource[src]
+	}
+
+	rows, err := database.Pool.Query(ctx, `
+		SELECT
+			source,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status IN ('qualified', 'converted')),
+			COUNT(*) FILTER (WHERE status = 'unqualified'),
+			COUNT(*) FILTER (WHERE status = 'converted')
+		FROM leads
+		WHERE ($1 = '' OR division = $1)
+		  AND ($2::date IS NULL OR created_at >= $2::date)
+		  AND ($3::date IS NULL OR created_at < $3::date + 1)
+		GROUP BY source
+	`, args...)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	for rows.Next() {
+		var src string
+		var total, qualified, unqualified, converted int64
+		if err := rows.Scan(&src, &total, &qualified, &unqualified, &converted); err != nil {
+			rows.Close()
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		r := rowFor(src)
+		r.Leads = total
+		r.Qualified = qualified
+		r.Unqualified = unqualified
+		r.Converted = converted
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, err = database.Pool.Query(ctx, `
+		SELECT
+			p.lead_source,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE p.sph_status = 'Win'),
+			COALESCE(SUM(r.revenue), 0)::float8,
+			COALESCE(SUM(r.revenue) FILTER (WHERE p.sph_status = 'Win'), 0)::float8
+		FROM projects p
+		LEFT JOIN (
+			SELECT project_id, SUM(target_revenue) AS revenue
+			FROM project_revenue_plan
+			GROUP BY project_id
+		) r ON r.project_id = p.id
+		WHERE p.lead_source IS NOT NULL
+		  AND ($1 = '' OR p.division = $1)
+		  AND ($2::date IS NULL OR p.created_at >= $2::date)
+		  AND ($3::date IS NULL OR p.created_at < $3::date + 1)
+		GROUP BY p.lead_source
+	`, args...)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	for rows.Next() {
+		var src string
+		var projects, won int64
+		var pipeline, wonRevenue float64
+		if err := rows.Scan(&src, &projects, &won, &pipeline, &wonRevenue); err != nil {
+			rows.Close()
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		r := rowFor(src)
+		r.Projects = projects
+		r.WonProjects = won
+		r.PipelineRevenue = pipeline
+		r.WonRevenue = wonRevenue
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	// urutan mengikuti leadSources, source lama / tidak dikenal di akhir
+	result := []models.LeadSourceReportRow{}
+	for _, src := range leadSources {
+		if r := bySource[src]; r != nil {
+			r.ConversionRate = round2(pct(float64(r.Converted), float64(r.Leads)))
+			result = append(result, *r)
+			delete(bySource, src)
+		}
+	}
+	others := make([]string, 0, len(bySource))
+	for src := range bySource {
+		others = append(others, src)
+	}
+	sort.Strings(others)
+	for _, src := range others {
+		r := bySource[src]
+		r.ConversionRate = round2(pct(float64(r.Converted), float64(r.Leads)))
+		result = append(result, *r)
+	}
+
+	c.JSON(200, gin.H{
+		"division": division,
+		"sources":  result,
+	})
+}
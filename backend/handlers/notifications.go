@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"sales-system-backend/database"
+	"sales-system-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// dbtx = *pgxpool.Pool atau pgx.Tx, supaya helper bisa dipakai di dalam/luar transaksi
+type dbtx interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// =====================================================
+//  NOTIFICATION HELPERS
+// =====================================================
+
+func createNotification(
+	ctx context.Context,
+	db dbtx,
+	userID int64,
+	nType, title, message string,
+	refType string,
+	refID int64,
+) error {
+	var rt *string
+	var rid *int64
+	if refType != "" {
+		rt = &refType
+		rid = &refID
+	}
+
+	_, err := db.Exec(ctx, `
+		INSERT INTO notifications (user_id, type, title, message, ref_type, ref_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, userID, nType, title, message, rt, rid)
+	return err
+}
+
+// format angka IDR: 1234567.8 -> "Rp 1.234.568"
+func formatRupiah(v float64) string {
+	neg := v < 0
+	if neg {
+		v = -v
+	}
+
+	s := strconv.FormatFloat(v, 'f', 0, 64)
+	var b strings.Builder
+	for i, ch := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(ch)
+	}
+
+	if neg {
+		return "-Rp " + b.String()
+	}
+	return "Rp " + b.String()
+}
+
+// ======================================================
+// LIST NOTIFICATIONS (milik user sendiri)
+// ======================================================
+
+func ListNotifications(c *gin.Context) {
+	userID := c.GetInt64("user_id")
+	unreadOnly := c.Query("unread") == "true"
+
+	limit := 50
+	if v, err := strconv.Atoi(c.Query("limit")); err == nil && v > 0 && v <= 200 {
+		limit = v
+	}
+
+	rows, err := database.Pool.Query(
+		c,
+		`
+		SELECT id, user_id, type, title, message, ref_type, ref_id, read_at, created_at
+		FROM notifications
+		WHERE user_id = $1
+		  AND ($2 = false OR read_at IS NULL)
+		ORDER BY created_at DESC
+		LIMIT $3
+		`,
+		userID, unreadOnly, limit,
+	)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	items := []models.Notification{}
+	for rows.Next() {
+		var n models.Notification
+		if err := rows.Scan(
+			&n.ID, &n.UserID, &n.Type, &n.Title, &n.Message,
+			&n.RefType, &n.RefID, &n.ReadAt, &n.CreatedAt,
+		); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		items = append(items, n)
+	}
+
+	var unread int64
+	if err := database.Pool.QueryRow(
+		c,
+		`SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`,
+		userID,
+	).Scan(&unread); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"items":        items,
+		"unread_count": unread,
+	})
+}
+
+// ======================================================
+// MARK READ
+// ======================================================
+
+func MarkNotificationRead(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid notification id"})
+		return
+	}
+
+	userID := c.GetInt64("user_id")
+
+	tag, err := database.Pool.Exec(
+		c,
+		`
+		UPDATE notifications
+		   SET read_at = COALESCE(read_at, NOW())
+		 WHERE id = $1 AND user_id = $2
+		`,
+		id, userID,
+	)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if tag.RowsAffected() == 0 {
+		c.JSON(404, gin.H{"error": "notification not found"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "ok"})
+}
+
+func MarkAllNotificationsRead(c *gin.Context) {
+	userID := c.GetInt64("user_id")
+
+	tag, err := database.Pool.Exec(
+		c,
+		`UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`,
+		userID,
+	)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "ok", "updated": tag.RowsAffected()})
+}
+
+// dipakai untuk subject email
+func notificationSubject(title string) string {
+	return fmt.Sprintf("[Sales System] %s", title)
+}
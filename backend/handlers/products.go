@@ -0,0 +1,696 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"sales-system-backend/database"
+	"sales-system-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// record yang menunjuk ke produk (hapus ditolak; nonaktifkan saja)
+var productDependents = []dependentRef{
+	{Type: "project_line_items", Table: "project_line_items", Column: "product_id", Label: "'project #' || project_id::text"},
+	{Type: "price_book_entries", Table: "price_book_entries", Column: "product_id", Label: "'price book #' || price_book_id::text"},
+	{Type: "sph_line_items", Table: "sph_line_items", Column: "product_id", Label: "'SPH version #' || version_id::text"},
+}
+
+// ======================================================
+// PRICE RESOLUTION
+// ======================================================
+
+// resolveProductPrice → harga dari price book aktif yang berlaku di tanggal `at`.
+// Prioritas: divisi+industry > industry > divisi > umum; seri → valid_from terbaru.
+// Tidak ada entry → default_price produk.
+func resolveProductPrice(ctx context.Context, db dbtx, productID int64, division, industry string, at time.Time) (models.ResolvedPrice, error) {
+	res := models.ResolvedPrice{ProductID: productID, Source: "price_book"}
+
+	err := db.QueryRow(ctx, `
+		SELECT e.unit_price::float8, pb.id, pb.name
+		FROM price_book_entries e
+		JOIN price_books pb ON pb.id = e.price_book_id
+		WHERE e.product_id = $1
+		  AND pb.is_active
+		  AND pb.valid_from <= $4 AND (pb.valid_to IS NULL OR pb.valid_to >= $4)
+		  AND (pb.division IS NULL OR pb.division = $2)
+		  AND (pb.industry IS NULL OR pb.industry = $3)
+		ORDER BY (pb.division IS NOT NULL AND pb.industry IS NOT NULL) DESC,
+		         (pb.industry IS NOT NULL) DESC,
+		         (pb.division IS NOT NULL) DESC,
+		         pb.valid_from DESC, pb.id DESC
+		LIMIT 1
+	`, productID, division, industry, at).Scan(&res.UnitPrice, &res.PriceBookID, &res.PriceBookName)
+	if err == nil {
+		return res, nil
+	}
+	if err != pgx.ErrNoRows {
+		return res, err
+	}
+
+	res.Source = "default"
+	err = db.QueryRow(ctx,
+		`SELECT default_price::float8 FROM products WHERE id = $1`, productID,
+	).Scan(&res.UnitPrice)
+	return res, err
+}
+
+// projectPriceContext → divisi project + industry customer (segmen)
+func projectPriceContext(ctx context.Context, db dbtx, projectID int64) (string, string, error) {
+	var division, industry string
+	err := db.QueryRow(ctx, `
+		SELECT p.division, COALESCE(cu.industry, '')
+		FROM projects p
+		LEFT JOIN customers cu ON cu.id = p.customer_id
+		WHERE p.id = $1
+	`, projectID).Scan(&division, &industry)
+	return NormalizeDivision(division), industry, err
+}
+
+// GET /products/:id/price?project_id= | ?division=&industry=&date=YYYY-MM-DD
+func GetProductPrice(c *gin.Context) {
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid product id"})
+		return
+	}
+
+	division := NormalizeDivision(strings.TrimSpace(c.Query("division")))
+	industry := strings.TrimSpace(c.Query("industry"))
+
+	if v := strings.TrimSpace(c.Query("project_id")); v != "" {
+		projectID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid project_id"})
+			return
+		}
+		if _, ok := checkProjectAccess(c, projectID); !ok {
+			return
+		}
+		division, industry, err = projectPriceContext(c, database.Pool, projectID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	at := jakartaToday()
+	if v := strings.TrimSpace(c.Query("date")); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid date (YYYY-MM-DD)"})
+			return
+		}
+		at = t
+	}
+
+	res, err := resolveProductPrice(c, database.Pool, productID, division, industry, at)
+	if err == pgx.ErrNoRows {
+		c.JSON(404, gin.H{"error": "product not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, res)
+}
+
+// ======================================================
+// PRODUCTS — GET /products?q=&category=&billing_type=&active=
+// ======================================================
+
+func ListProducts(c *gin.Context) {
+	conds := []string{"1=1"}
+	args := []any{}
+	i := 1
+
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		conds = append(conds, fmt.Sprintf("(sku ILIKE $%d OR name ILIKE $%d)", i, i))
+		args = append(args, "%"+q+"%")
+		i++
+	}
+	if v := strings.TrimSpace(c.Query("category")); v != "" && strings.ToUpper(v) != "ALL" {
+		conds = append(conds, fmt.Sprintf("category = $%d", i))
+		args = append(args, v)
+		i++
+	}
+	if v := strings.TrimSpace(c.Query("billing_type")); v != "" && strings.ToUpper(v) != "ALL" {
+		conds = append(conds, fmt.Sprintf("billing_type = $%d", i))
+		args = append(args, v)
+		i++
+	}
+	if v := strings.TrimSpace(c.Query("active")); v == "true" || v == "1" {
+		conds = append(conds, "is_active")
+	}
+
+	rows, err := database.Pool.Query(c, fmt.Sprintf(`
+		SELECT id, sku, name, category, unit, billing_type, default_price::float8,
+		       is_active, created_at, updated_at
+		FROM products
+		WHERE %s
+		ORDER BY category ASC, name ASC
+	`, strings.Join(conds, " AND ")), args...)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	list := []models.Product{}
+	for rows.Next() {
+		var p models.Product
+		if err := rows.Scan(
+			&p.ID, &p.SKU, &p.Name, &p.Category, &p.Unit, &p.BillingType, &p.DefaultPrice,
+			&p.IsActive, &p.CreatedAt, &p.UpdatedAt,
+		); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		list = append(list, p)
+	}
+
+	c.JSON(200, list)
+}
+
+// validateProduct → trim + default; "" = valid
+func validateProduct(req *models.ProductRequest) string {
+	req.SKU = strings.ToUpper(strings.TrimSpace(req.SKU))
+	req.Name = strings.TrimSpace(req.Name)
+	req.Category = strings.TrimSpace(req.Category)
+	req.Unit = strings.TrimSpace(req.Unit)
+	req.BillingType = strings.TrimSpace(req.BillingType)
+
+	if req.SKU == "" || req.Name == "" {
+		return "sku and name are required"
+	}
+	if req.Category == "" {
+		req.Category = "Other"
+	}
+	if req.Unit == "" {
+		req.Unit = "unit"
+	}
+	if req.BillingType == "" {
+		req.BillingType = "one_time"
+	}
+	if req.BillingType != "one_time" && req.BillingType != "recurring" {
+		return "billing_type must be one_time or recurring"
+	}
+	if req.DefaultPrice != nil && *req.DefaultPrice < 0 {
+		return "default_price must be >= 0"
+	}
+	return ""
+}
+
+// POST /products (ADMIN)
+func CreateProduct(c *gin.Context) {
+	var req models.ProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "sku and name are required"})
+		return
+	}
+	if msg := validateProduct(&req); msg != "" {
+		c.JSON(400, gin.H{"error": msg})
+		return
+	}
+
+	price := 0.0
+	if req.DefaultPrice != nil {
+		price = *req.DefaultPrice
+	}
+	active := true
+	if req.IsActive != nil {
+		active = *req.IsActive
+	}
+
+	var id int64
+	err := database.Pool.QueryRow(c, `
+		INSERT INTO products (sku, name, category, unit, billing_type, default_price, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (sku) DO NOTHING
+		RETURNING id
+	`, req.SKU, req.Name, req.Category, req.Unit, req.BillingType, price, active).Scan(&id)
+	if err == pgx.ErrNoRows {
+		c.JSON(409, gin.H{"error": "sku already exists"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(201, gin.H{"id": id, "sku": req.SKU})
+}
+
+// PUT /products/:id (ADMIN)
+func UpdateProduct(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid product id"})
+		return
+	}
+
+	var req models.ProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "sku and name are required"})
+		return
+	}
+	if msg := validateProduct(&req); msg != "" {
+		c.JSON(400, gin.H{"error": msg})
+		return
+	}
+
+	var taken bool
+	if err := database.Pool.QueryRow(c,
+		`SELECT EXISTS(SELECT 1 FROM products WHERE sku = $1 AND id <> $2)`, req.SKU, id,
+	).Scan(&taken); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if taken {
+		c.JSON(409, gin.H{"error": "sku already exists"})
+		return
+	}
+
+	tag, err := database.Pool.Exec(c, `
+		UPDATE products SET
+			sku = $1, name = $2, category = $3, unit = $4, billing_type = $5,
+			default_price = COALESCE($6, default_price),
+			is_active = COALESCE($7, is_active),
+			updated_at = NOW()
+		WHERE id = $8
+	`, req.SKU, req.Name, req.Category, req.Unit, req.BillingType, req.DefaultPrice, req.IsActive, id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(404, gin.H{"error": "product not found"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "updated"})
+}
+
+// DELETE /products/:id (ADMIN) — produk yang sudah dipakai → 409 + dependents
+func DeleteProduct(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid product id"})
+		return
+	}
+
+	deps, err := loadDependents(c, database.Pool, productDependents, id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if len(deps) > 0 {
+		c.JSON(409, gin.H{
+			"error":      "product is in use; set is_active=false instead",
+			"dependents": deps,
+		})
+		return
+	}
+
+	tag, err := database.Pool.Exec(c, `DELETE FROM products WHERE id = $1`, id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(404, gin.H{"error": "product not found"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "deleted"})
+}
+
+// ======================================================
+// PRICE BOOKS
+// ======================================================
+
+const priceBookColumns = `
+	pb.id, pb.name, pb.division, pb.industry,
+	to_char(pb.valid_from, 'YYYY-MM-DD'), to_char(pb.valid_to, 'YYYY-MM-DD'),
+	pb.is_active,
+	(SELECT COUNT(*) FROM price_book_entries e WHERE e.price_book_id = pb.id)::int,
+	pb.created_at, pb.updated_at`
+
+func scanPriceBook(row pgx.Row, pb *models.PriceBook) error {
+	return row.Scan(
+		&pb.ID, &pb.Name, &pb.Division, &pb.Industry,
+		&pb.ValidFrom, &pb.ValidTo,
+		&pb.IsActive, &pb.EntryCount,
+		&pb.CreatedAt, &pb.UpdatedAt,
+	)
+}
+
+// GET /price-books?division=&active=
+func ListPriceBooks(c *gin.Context) {
+	conds := []string{"1=1"}
+	args := []any{}
+	i := 1
+
+	division := NormalizeDivision(strings.TrimSpace(c.Query("division")))
+	if c.GetString("role") == "user" {
+		division = NormalizeDivision(c.GetString("division"))
+	}
+	if division != "" && strings.ToUpper(division) != "ALL" {
+		conds = append(conds, fmt.Sprintf("(pb.division IS NULL OR pb.division = $%d)", i))
+		args = append(args, division)
+		i++
+	}
+	if v := strings.TrimSpace(c.Query("active")); v == "true" || v == "1" {
+		conds = append(conds, "pb.is_active")
+	}
+
+	rows, err := database.Pool.Query(c, fmt.Sprintf(`
+		SELECT %s
+		FROM price_books pb
+		WHERE %s
+		ORDER BY pb.valid_from DESC, pb.name ASC
+	`, priceBookColumns, strings.Join(conds, " AND ")), args...)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	list := []models.PriceBook{}
+	for rows.Next() {
+		var pb models.PriceBook
+		if err := scanPriceBook(rows, &pb); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		list = append(list, pb)
+	}
+
+	c.JSON(200, list)
+}
+
+// GET /price-books/:id (+ entries)
+func GetPriceBook(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid price book id"})
+		return
+	}
+
+	var pb models.PriceBook
+	err = scanPriceBook(database.Pool.QueryRow(c, `
+		SELECT `+priceBookColumns+`
+		FROM price_books pb WHERE pb.id = $1
+	`, id), &pb)
+	if err == pgx.ErrNoRows {
+		c.JSON(404, gin.H{"error": "price book not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, err := database.Pool.Query(c, `
+		SELECT e.product_id, pr.sku, pr.name, e.unit_price::float8
+		FROM price_book_entries e
+		JOIN products pr ON pr.id = e.product_id
+		WHERE e.price_book_id = $1
+		ORDER BY pr.category ASC, pr.name ASC
+	`, id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	pb.Entries = []models.PriceBookEntry{}
+	for rows.Next() {
+		var e models.PriceBookEntry
+		if err := rows.Scan(&e.ProductID, &e.SKU, &e.ProductName, &e.UnitPrice); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		pb.Entries = append(pb.Entries, e)
+	}
+
+	c.JSON(200, pb)
+}
+
+// validatePriceBook → normalisasi divisi / industry + cek tanggal; "" = valid
+func validatePriceBook(ctx context.Context, req *models.PriceBookRequest) (string, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return "name is required", nil
+	}
+
+	if req.Division != nil {
+		d := NormalizeDivision(*req.Division)
+		if d == "" || strings.ToUpper(d) == "ALL" {
+			req.Division = nil
+		} else if !isValidDivision(d) {
+			return "invalid division", nil
+		} else {
+			req.Division = &d
+		}
+	}
+
+	if req.Industry != nil {
+		ind, ok, err := resolveIndustry(ctx, *req.Industry)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return "unknown industry: " + *req.Industry, nil
+		}
+		if ind == "" {
+			req.Industry = nil
+		} else {
+			req.Industry = &ind
+		}
+	}
+
+	from, err := time.Parse("2006-01-02", strings.TrimSpace(req.ValidFrom))
+	if err != nil {
+		return "invalid valid_from (YYYY-MM-DD)", nil
+	}
+	if req.ValidTo != nil && strings.TrimSpace(*req.ValidTo) == "" {
+		req.ValidTo = nil
+	}
+	if req.ValidTo != nil {
+		to, err := time.Parse("2006-01-02", strings.TrimSpace(*req.ValidTo))
+		if err != nil {
+			return "invalid valid_to (YYYY-MM-DD)", nil
+		}
+		if to.Before(from) {
+			return "valid_to must be on or after valid_from", nil
+		}
+	}
+
+	return "", nil
+}
+
+// POST /price-books (ADMIN)
+func CreatePriceBook(c *gin.Context) {
+	var req models.PriceBookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "name and valid_from are required"})
+		return
+	}
+	msg, err := validatePriceBook(c, &req)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if msg != "" {
+		c.JSON(400, gin.H{"error": msg})
+		return
+	}
+
+	active := true
+	if req.IsActive != nil {
+		active = *req.IsActive
+	}
+
+	var id int64
+	err = database.Pool.QueryRow(c, `
+		INSERT INTO price_books (name, division, industry, valid_from, valid_to, is_active)
+		VALUES ($1, $2, $3, $4::date, $5::date, $6)
+		ON CONFLICT (name) DO NOTHING
+		RETURNING id
+	`, req.Name, req.Division, req.Industry, req.ValidFrom, req.ValidTo, active).Scan(&id)
+	if err == pgx.ErrNoRows {
+		c.JSON(409, gin.H{"error": "price book name already exists"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(201, gin.H{"id": id})
+}
+
+// PUT /price-books/:id (ADMIN)
+func UpdatePriceBook(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid price book id"})
+		return
+	}
+
+	var req models.PriceBookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "name and valid_from are required"})
+		return
+	}
+	msg, err := validatePriceBook(c, &req)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if msg != "" {
+		c.JSON(400, gin.H{"error": msg})
+		return
+	}
+
+	var taken bool
+	if err := database.Pool.QueryRow(c,
+		`SELECT EXISTS(SELECT 1 FROM price_books WHERE name = $1 AND id <> $2)`, req.Name, id,
+	).Scan(&taken); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if taken {
+		c.JSON(409, gin.H{"error": "price book name already exists"})
+		return
+	}
+
+	tag, err := database.Pool.Exec(c, `
+		UPDATE price_books SET
+			name = $1, division = $2, industry = $3,
+			valid_from = $4::date, valid_to = $5::date,
+			is_active = COALESCE($6, is_active),
+			updated_at = NOW()
+		WHERE id = $7
+	`, req.Name, req.Division, req.Industry, req.ValidFrom, req.ValidTo, req.IsActive, id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(404, gin.H{"error": "price book not found"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "updated"})
+}
+
+// DELETE /price-books/:id (ADMIN) — line item yang memakai price book tetap ada (price_book_id → NULL)
+func DeletePriceBook(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid price book id"})
+		return
+	}
+
+	tag, err := database.Pool.Exec(c, `DELETE FROM price_books WHERE id = $1`, id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(404, gin.H{"error": "price book not found"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "deleted"})
+}
+
+// PUT /price-books/:id/entries (ADMIN) {entries: [{product_id, unit_price}]} → replace semua entry
+func SetPriceBookEntries(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid price book id"})
+		return
+	}
+
+	var req models.SetPriceBookEntriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "invalid body"})
+		return
+	}
+
+	seen := map[int64]bool{}
+	for _, e := range req.Entries {
+		if e.UnitPrice < 0 {
+			c.JSON(400, gin.H{"error": fmt.Sprintf("product %d: unit_price must be >= 0", e.ProductID)})
+			return
+		}
+		if seen[e.ProductID] {
+			c.JSON(400, gin.H{"error": fmt.Sprintf("duplicate product %d", e.ProductID)})
+			return
+		}
+		seen[e.ProductID] = true
+	}
+
+	ctx := c.Request.Context()
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	if err := tx.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM price_books WHERE id = $1)`, id,
+	).Scan(&exists); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if !exists {
+		c.JSON(404, gin.H{"error": "price book not found"})
+		return
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM price_book_entries WHERE price_book_id = $1`, id); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, e := range req.Entries {
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO price_book_entries (price_book_id, product_id, unit_price)
+			SELECT $1, id, $3 FROM products WHERE id = $2
+		`, id, e.ProductID, e.UnitPrice)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		if tag.RowsAffected() == 0 {
+			c.JSON(400, gin.H{"error": fmt.Sprintf("product %d not found", e.ProductID)})
+			return
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE price_books SET updated_at = NOW() WHERE id = $1`, id); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "updated", "entries": len(req.Entries)})
+}
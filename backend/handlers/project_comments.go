@@ -0,0 +1,450 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"sales-system-backend/database"
+	"sales-system-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// @username → huruf, angka, titik, underscore, dash
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9._-]+)`)
+
+// parseMentions → username unik (lowercase) yang di-mention di body
+func parseMentions(body string) []string {
+	seen := map[string]bool{}
+	list := []string{}
+	for _, m := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		name := strings.ToLower(strings.TrimRight(m[1], "._-"))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		list = append(list, name)
+	}
+	return list
+}
+
+// syncCommentMentionsTx → simpan mention yang valid, return user yang BARU di-mention.
+// User yang tidak bisa melihat project (beda divisi / nonaktif) diabaikan.
+func syncCommentMentionsTx(ctx context.Context, tx pgx.Tx, commentID int64, projectDivision, body string) ([]int64, error) {
+	names := parseMentions(body)
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, role, division
+		FROM users
+		WHERE lower(username) = ANY($1) AND is_active
+	`, names)
+	if err != nil {
+		return nil, err
+	}
+	userIDs := []int64{}
+	for rows.Next() {
+		var id int64
+		var role, division string
+		if err := rows.Scan(&id, &role, &division); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		// sama dengan ACL GetProject
+		if role == "admin" || NormalizeDivision(division) == projectDivision {
+			userIDs = append(userIDs, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// mention yang dihapus dari body ikut dihapus
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM project_comment_mentions
+		WHERE comment_id = $1 AND NOT (user_id = ANY($2))
+	`, commentID, userIDs); err != nil {
+		return nil, err
+	}
+
+	var added []int64
+	for _, uid := range userIDs {
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO project_comment_mentions (comment_id, user_id)
+			VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+		`, commentID, uid)
+		if err != nil {
+			return nil, err
+		}
+		if tag.RowsAffected() > 0 {
+			added = append(added, uid)
+		}
+	}
+
+	return added, nil
+}
+
+// notifyCommentMentionsTx → notif in-app untuk user yang baru di-mention (kecuali penulis)
+func notifyCommentMentionsTx(ctx context.Context, tx pgx.Tx, projectID int64, authorID int64, userIDs []int64, body string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	var projectCode, author string
+	if err := tx.QueryRow(ctx, `
+		SELECT p.project_code, COALESCE((SELECT username FROM users WHERE id = $2), 'Someone')
+		FROM projects p WHERE p.id = $1
+	`, projectID, authorID).Scan(&projectCode, &author); err != nil {
+		return err
+	}
+
+	excerpt := body
+	if r := []rune(excerpt); len(r) > 140 {
+		excerpt = string(r[:140]) + "…"
+	}
+
+	for _, uid := range userIDs {
+		if uid == authorID {
+			continue
+		}
+		if err := createNotification(ctx, tx, uid,
+			"comment_mention",
+			fmt.Sprintf("%s mentioned you on %s", author, projectCode),
+			excerpt,
+			"project", projectID,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadCommentForWrite → comment milik project + cek penulis (edit / delete)
+// Kalau gagal, response sudah dikirim dan ok=false.
+func loadCommentForWrite(c *gin.Context, tx pgx.Tx, projectID int64) (int64, bool) {
+	commentID, err := strconv.ParseInt(c.Param("commentId"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid comment id"})
+		return 0, false
+	}
+
+	var authorID *int64
+	var deleted bool
+	err = tx.QueryRow(c.Request.Context(), `
+		SELECT author_id, deleted_at IS NOT NULL
+		FROM project_comments
+		WHERE id = $1 AND project_id = $2
+		FOR UPDATE
+	`, commentID, projectID).Scan(&authorID, &deleted)
+	if err == pgx.ErrNoRows {
+		c.JSON(404, gin.H{"error": "comment not found"})
+		return 0, false
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return 0, false
+	}
+	if deleted {
+		c.JSON(409, gin.H{"error": "comment already deleted"})
+		return 0, false
+	}
+	if authorID == nil || *authorID != c.GetInt64("user_id") {
+		c.JSON(403, gin.H{"error": "only the author can modify this comment"})
+		return 0, false
+	}
+
+	return commentID, true
+}
+
+// ======================================================
+// LIST — GET /projects/:id/comments (tree, urut waktu)
+// ======================================================
+
+func ListProjectComments(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid project id"})
+		return
+	}
+	if _, ok := checkProjectAccess(c, projectID); !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	rows, err := database.Pool.Query(ctx, `
+		SELECT pc.id, pc.project_id, pc.parent_id, pc.author_id, u.username,
+		       CASE WHEN pc.deleted_at IS NULL THEN pc.body ELSE '' END,
+		       pc.deleted_at IS NOT NULL, pc.edited_at, pc.created_at
+		FROM project_comments pc
+		LEFT JOIN users u ON u.id = pc.author_id
+		WHERE pc.project_id = $1
+		ORDER BY pc.created_at ASC, pc.id ASC
+	`, projectID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	byID := map[int64]*models.ProjectComment{}
+	var ordered []*models.ProjectComment
+	for rows.Next() {
+		pc := &models.ProjectComment{
+			Mentions: []models.CommentMention{},
+			Replies:  []*models.ProjectComment{},
+		}
+		if err := rows.Scan(
+			&pc.ID, &pc.ProjectID, &pc.ParentID, &pc.AuthorID, &pc.AuthorName,
+			&pc.Body, &pc.IsDeleted, &pc.EditedAt, &pc.CreatedAt,
+		); err != nil {
+			rows.Close()
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		byID[pc.ID] = pc
+		ordered = append(ordered, pc)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, err = database.Pool.Query(ctx, `
+		SELECT m.comment_id, m.user_id, u.username
+		FROM project_comment_mentions m
+		JOIN project_comments pc ON pc.id = m.comment_id
+		JOIN users u ON u.id = m.user_id
+		WHERE pc.project_id = $1 AND pc.deleted_at IS NULL
+		ORDER BY u.username ASC
+	`, projectID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	for rows.Next() {
+		var commentID int64
+		var m models.CommentMention
+		if err := rows.Scan(&commentID, &m.UserID, &m.Username); err != nil {
+			rows.Close()
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		if pc := byID[commentID]; pc != nil {
+			pc.Mentions = append(pc.Mentions, m)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	// susun thread: reply masuk ke parent-nya
+	roots := []*models.ProjectComment{}
+	for _, pc := range ordered {
+		if pc.ParentID != nil {
+			if parent := byID[*pc.ParentID]; parent != nil {
+				parent.Replies = append(parent.Replies, pc)
+				continue
+			}
+		}
+		roots = append(roots, pc)
+	}
+
+	c.JSON(200, roots)
+}
+
+// ======================================================
+// CREATE — POST /projects/:id/comments {body, parent_id?}
+// ======================================================
+
+func CreateProjectComment(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid project id"})
+		return
+	}
+
+	var req models.ProjectCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "body is required"})
+		return
+	}
+	req.Body = strings.TrimSpace(req.Body)
+	if req.Body == "" {
+		c.JSON(400, gin.H{"error": "body is required"})
+		return
+	}
+
+	p, ok := checkProjectAccess(c, projectID)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	authorID := c.GetInt64("user_id")
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	if req.ParentID != nil {
+		var exists bool
+		if err := tx.QueryRow(ctx, `
+			SELECT EXISTS(SELECT 1 FROM project_comments WHERE id = $1 AND project_id = $2)
+		`, *req.ParentID, projectID).Scan(&exists); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		if !exists {
+			c.JSON(400, gin.H{"error": "parent comment not found in this project"})
+			return
+		}
+	}
+
+	var id int64
+	if err := tx.QueryRow(ctx, `
+		INSERT INTO project_comments (project_id, parent_id, author_id, body)
+		VALUES ($1, $2, NULLIF($3::bigint, 0), $4)
+		RETURNING id
+	`, projectID, req.ParentID, authorID, req.Body).Scan(&id); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	mentioned, err := syncCommentMentionsTx(ctx, tx, id, p.Division, req.Body)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if err := notifyCommentMentionsTx(ctx, tx, projectID, authorID, mentioned, req.Body); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	c.JSON(201, gin.H{"id": id, "mentioned": len(mentioned)})
+}
+
+// ======================================================
+// UPDATE — PUT /projects/:id/comments/:commentId (penulis saja)
+// hanya mention baru yang dapat notifikasi
+// ======================================================
+
+func UpdateProjectComment(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid project id"})
+		return
+	}
+
+	var req models.ProjectCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "body is required"})
+		return
+	}
+	req.Body = strings.TrimSpace(req.Body)
+	if req.Body == "" {
+		c.JSON(400, gin.H{"error": "body is required"})
+		return
+	}
+
+	p, ok := checkProjectAccess(c, projectID)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	commentID, ok := loadCommentForWrite(c, tx, projectID)
+	if !ok {
+		return
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE project_comments SET body = $1, edited_at = NOW() WHERE id = $2
+	`, req.Body, commentID); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	authorID := c.GetInt64("user_id")
+	mentioned, err := syncCommentMentionsTx(ctx, tx, commentID, p.Division, req.Body)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if err := notifyCommentMentionsTx(ctx, tx, projectID, authorID, mentioned, req.Body); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "updated", "mentioned": len(mentioned)})
+}
+
+// DELETE /projects/:id/comments/:commentId (penulis saja, soft delete)
+func DeleteProjectComment(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid project id"})
+		return
+	}
+
+	if _, ok := checkProjectAccess(c, projectID); !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	commentID, ok := loadCommentForWrite(c, tx, projectID)
+	if !ok {
+		return
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE project_comments SET deleted_at = NOW() WHERE id = $1
+	`, commentID); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "deleted"})
+}
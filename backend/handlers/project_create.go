@@ -1,14 +1,18 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"sales-system-backend/database"
 	"sales-system-backend/models"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 )
 
 func CreateProject(c *gin.Context) {
@@ -21,6 +25,45 @@ func CreateProject(c *gin.Context) {
 
 	ctx := c.Request.Context()
 
+	if !validateCreateProjectRequest(c, &body) {
+		return
+	}
+
+	// --- Begin transaction ---
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// owner = pembuat project; lead_source opsional (atribusi tanpa lead)
+	created, status, err := createProjectTx(ctx, tx, body, c.GetInt64("user_id"), projectAttribution{
+		LeadSource: body.LeadSource,
+	})
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	// --- Commit TX ---
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	// --- SUCCESS RESPONSE ---
+	c.JSON(201, gin.H{
+		"id":           created.ID,
+		"project_code": created.ProjectCode,
+		"division":     body.Division,
+		"sph_number":   created.SPHNumber,
+	})
+}
+
+// validateCreateProjectRequest → ACL divisi + business rule (dipakai CreateProject & convert lead).
+// Kalau gagal, response sudah dikirim dan return false.
+func validateCreateProjectRequest(c *gin.Context, body *models.CreateProjectRequest) bool {
 	// --- ACL DATA FROM JWT ---
 	role := c.GetString("role")
 	userDivision := NormalizeDivision(c.GetString("division"))
@@ -35,7 +78,7 @@ func CreateProject(c *gin.Context) {
 		// FORCE project division to user’s division
 		if userDivision == "" {
 			c.JSON(http.StatusForbidden, gin.H{"error": "missing division in token"})
-			return
+			return false
 		}
 
 		body.Division = userDivision
@@ -48,7 +91,7 @@ func CreateProject(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "New Recurring project type only allowed when status is New Prospect",
 		})
-		return
+		return false
 	}
 
 	//
@@ -59,23 +102,23 @@ func CreateProject(c *gin.Context) {
 		st := *body.SPHStatus
 		if st != "Open" && st != "Win" && st != "Hold" && st != "Loss" && st != "Drop" {
 			c.JSON(400, gin.H{"error": "invalid sph_status"})
-			return
+			return false
 		}
 
 		if st == "Loss" || st == "Drop" {
 			if body.SPHStatusReasonCategory == nil || *body.SPHStatusReasonCategory == "" {
 				c.JSON(400, gin.H{"error": "sph_status_reason_category required for Loss/Drop"})
-				return
+				return false
 			}
 			cat := *body.SPHStatusReasonCategory
 			if cat != "Administrasi" && cat != "Teknis" && cat != "Other" {
 				c.JSON(400, gin.H{"error": "invalid sph_status_reason_category"})
-				return
+				return false
 			}
 			if cat == "Other" {
 				if body.SPHStatusReasonNote == nil || *body.SPHStatusReasonNote == "" {
 					c.JSON(400, gin.H{"error": "sph_status_reason_note required when category=Other"})
-					return
+					return false
 				}
 			}
 		} else {
@@ -85,39 +128,111 @@ func CreateProject(c *gin.Context) {
 		}
 	}
 
+	// --- Kompetitor pemenang hanya dicatat untuk Loss ---
+	if body.SPHStatus == nil || *body.SPHStatus != "Loss" {
+		body.WinningCompetitorID = nil
+	}
+
+	// --- Lead source (atribusi) harus dari daftar source lead ---
+	if body.LeadSource != nil {
+		if strings.TrimSpace(*body.LeadSource) == "" {
+			body.LeadSource = nil
+		} else {
+			src, ok := normalizeLeadSource(*body.LeadSource)
+			if !ok {
+				c.JSON(400, gin.H{"error": "invalid lead_source"})
+				return false
+			}
+			body.LeadSource = &src
+		}
+	}
+
 	// --- Final division validation ---
 	if !isValidDivision(body.Division) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid division"})
-		return
+		return false
 	}
 
-	// --- Begin transaction ---
-	tx, err := database.Pool.Begin(ctx)
-	if err != nil {
-		c.JSON(500, gin.H{"error": "db transaction error"})
-		return
-	}
-	defer tx.Rollback(ctx)
+	return true
+}
+
+// projectAttribution = asal project untuk report lead source
+type projectAttribution struct {
+	LeadID     *int64
+	LeadSource *string
+}
+
+type createdProject struct {
+	ID          int64
+	ProjectCode string
+	SPHNumber   *string
+}
+
+// createProjectTx → insert project (+ revenue plan) dari request yang sudah divalidasi.
+// Error dikembalikan bersama HTTP status yang sesuai.
+func createProjectTx(
+	ctx context.Context,
+	tx pgx.Tx,
+	body models.CreateProjectRequest,
+	ownerID int64,
+	attr projectAttribution,
+) (createdProject, int, error) {
+	var res createdProject
 
 	// --- Generate project code AFTER division finalized ---
 	//projectCode := generateProjectCode(body.Division)
 	projectCode, err := generateProjectCodeTx(ctx, tx, body.Division)
 	if err != nil {
-		c.JSON(500, gin.H{"error": fmt.Sprintf("failed generate project code: %v", err)})
-		return
+		return res, 500, fmt.Errorf("failed generate project code: %v", err)
 	}
 
 	fmt.Println("GENERATED projectCode:", projectCode)
 
+	// --- SPH number (manual harus unik, release tanpa nomor → generate) ---
+	// project baru belum punya dokumen SPH, jadi belum ada approval diskon yang bisa terlewati;
+	// release berikutnya dijaga di UpdateProject / ReleaseProjectSPH (projectHasSPHVersions)
+	releaseDate := parseDatePtr(body.SPHRelease)
+	if body.SphReleaseStatus == "Yes" && releaseDate == nil {
+		today := jakartaToday()
+		releaseDate = &today
+	}
+
+	sphAt := jakartaToday()
+	if releaseDate != nil {
+		sphAt = *releaseDate
+	}
+
+	sphNumber, conflict, err := resolveSPHNumberTx(ctx, tx, 0, body.Division,
+		body.SphNumber, nil, body.SphReleaseStatus, sphAt)
+	if err != nil {
+		return res, 500, fmt.Errorf("failed generate sph number: %v", err)
+	}
+	if conflict != "" {
+		return res, http.StatusConflict, errors.New(conflict)
+	}
+
+	if body.WinningCompetitorID != nil {
+		msg, err := checkWinningCompetitorTx(ctx, tx, 0, *body.WinningCompetitorID)
+		if err != nil {
+			return res, 500, err
+		}
+		if msg != "" {
+			return res, 400, errors.New(msg)
+		}
+	}
+
 	var id int64
 	err = tx.QueryRow(ctx, `
         INSERT INTO projects (
 			project_code, description, customer_id, division, status,
 			project_type, sph_status, sph_release_date, sales_stage,
 			sph_release_status, sph_number,
-			sph_status_reason_category, sph_status_reason_note
+			sph_status_reason_category, sph_status_reason_note,
+			owner_user_id, lead_id, lead_source, sph_status_changed_at,
+			winning_competitor_id
 			)
-			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,NULLIF($14::bigint, 0),$15,$16,
+				CASE WHEN NULLIF($7::text, '') IS NOT NULL THEN NOW() END, $17)
         RETURNING id
     `,
 		projectCode,
@@ -127,19 +242,26 @@ func CreateProject(c *gin.Context) {
 		body.Status,
 		body.ProjectType,
 		body.SPHStatus,
-		parseDatePtr(body.SPHRelease),
+		releaseDate,
 		body.SalesStage,
 		body.SphReleaseStatus,
-		body.SphNumber,
+		sphNumber,
 		body.SPHStatusReasonCategory,
 		body.SPHStatusReasonNote,
+		ownerID,
+		attr.LeadID,
+		attr.LeadSource,
+		body.WinningCompetitorID,
 	).Scan(&id)
 
 	if err != nil {
-		c.JSON(500, gin.H{
-			"error": fmt.Sprintf("failed insert project: %v", err),
-		})
-		return
+		return res, 500, fmt.Errorf("failed insert project: %v", err)
+	}
+
+	if body.WinningCompetitorID != nil {
+		if err := linkWinningCompetitorTx(ctx, tx, id, *body.WinningCompetitorID); err != nil {
+			return res, 500, fmt.Errorf("failed link winning competitor: %v", err)
+		}
 	}
 
 	// ----------------------------------------------------
@@ -148,8 +270,7 @@ func CreateProject(c *gin.Context) {
 	for _, rp := range body.RevenuePlans {
 		month, err := time.Parse("2006-01", rp.Month)
 		if err != nil {
-			c.JSON(400, gin.H{"error": "invalid month format (YYYY-MM)"})
-			return
+			return res, 400, errors.New("invalid month format (YYYY-MM)")
 		}
 
 		_, err = tx.Exec(ctx, `
@@ -158,23 +279,20 @@ func CreateProject(c *gin.Context) {
         `, id, month, rp.TargetRevenue)
 
 		if err != nil {
-			c.JSON(500, gin.H{"error": "failed insert revenue plan"})
-			return
+			return res, 500, errors.New("failed insert revenue plan")
 		}
 	}
 
-	// --- Commit TX ---
-	if err := tx.Commit(ctx); err != nil {
-		c.JSON(500, gin.H{"error": "transaction commit error"})
-		return
-	}
+	res.ID = id
+	res.ProjectCode = projectCode
+	res.SPHNumber = sphNumber
+	return res, 0, nil
+}
 
-	// --- SUCCESS RESPONSE ---
-	c.JSON(201, gin.H{
-		"id":           id,
-		"project_code": projectCode,
-		"division":     body.Division,
-	})
+// jakartaToday → tanggal hari ini (Asia/Jakarta) sebagai date UTC 00:00
+func jakartaToday() time.Time {
+	now := time.Now().In(mustLoadLocation("Asia/Jakarta"))
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
 }
 
 func parseDatePtr(s *string) *time.Time {
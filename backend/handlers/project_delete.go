@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"sales-system-backend/database"
 	"strconv"
@@ -47,8 +48,22 @@ func DeleteProject(c *gin.Context) {
 		}
 	}
 
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// bulan komisi diambil sebelum revenue plan / commission_lines ikut terhapus (cascade)
+	months, err := projectCommissionMonthsTx(ctx, tx, id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
 	// --- Delete project ---
-	cmdTag, err := database.Pool.Exec(ctx,
+	cmdTag, err := tx.Exec(ctx,
 		`DELETE FROM projects WHERE id = $1`,
 		id,
 	)
@@ -63,5 +78,16 @@ func DeleteProject(c *gin.Context) {
 		return
 	}
 
+	// statement closed yang kehilangan line → total dihitung ulang dari line yang tersisa
+	if err := recalcClosedCommissionRunsTx(ctx, tx, "a project deletion", months...); err != nil {
+		c.JSON(500, gin.H{"error": fmt.Sprintf("commission recalculation failed: %v", err)})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
 	c.Status(204)
 }
@@ -22,6 +22,10 @@ type ProjectDetailResponse struct {
 	CustomerName     string                          `json:"customer_name,omitempty"`
 	RevenuePlans     []RevenuePlanItem               `json:"revenue_plans"`
 	PostPOMonitoring *models.ProjectPostPOMonitoring `json:"postpo_monitoring,omitempty"`
+	Stakeholders     []models.ProjectStakeholder     `json:"stakeholders"`
+	CoOwners         []models.ProjectOwnerRef        `json:"co_owners"`
+	LineItems        []models.ProjectLineItem        `json:"line_items"`
+	Competitors      []models.ProjectCompetitor      `json:"competitors"`
 }
 
 func mustAtoi64(s string) int64 {
@@ -58,16 +62,26 @@ func GetProject(c *gin.Context) {
 			p.status,
 			p.project_type,
 			p.sph_status,
+			p.sph_status_changed_at,
 			p.sph_release_date,
 			p.sales_stage,
+			p.stage_changed_at,
 			p.sph_release_status,
 			p.sph_number,
 			p.sph_status_reason_category,
 			p.sph_status_reason_note,
+			p.owner_user_id,
+			ow.username,
+			p.lead_id,
+			p.lead_source,
+			p.winning_competitor_id,
+			wc.name,
 			p.created_at,
 			p.updated_at
 		FROM projects p
 		LEFT JOIN customers c ON c.id = p.customer_id
+		LEFT JOIN users ow ON ow.id = p.owner_user_id
+		LEFT JOIN competitors wc ON wc.id = p.winning_competitor_id
 		WHERE p.id = $1
 	`, id).Scan(
 		&p.ID,
@@ -79,12 +93,20 @@ func GetProject(c *gin.Context) {
 		&p.Status,
 		&p.ProjectType,
 		&p.SPHStatus,
+		&p.SPHStatusChangedAt,
 		&p.SPHRelease,
 		&p.SalesStage,
+		&p.StageChangedAt,
 		&p.SPHReleaseStatus,
 		&p.SPHNumber,
 		&p.SPHStatusReasonCategory,
 		&p.SPHStatusReasonNote,
+		&p.OwnerUserID,
+		&p.OwnerName,
+		&p.LeadID,
+		&p.LeadSource,
+		&p.WinningCompetitorID,
+		&p.WinningCompetitorName,
 		&p.CreatedAt,
 		&p.UpdatedAt,
 	)
@@ -137,6 +159,34 @@ func GetProject(c *gin.Context) {
 		}
 	}
 
+	// --- Fetch stakeholders (customer contacts) ---
+	stakeholders, err := loadProjectStakeholders(ctx, id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "stakeholder query error"})
+		return
+	}
+
+	// --- Fetch co-owners ---
+	coOwners, err := loadProjectCoOwners(ctx, id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "co-owner query error"})
+		return
+	}
+
+	// --- Fetch line items (produk) ---
+	lineItems, err := loadProjectLineItems(ctx, database.Pool, id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "line item query error"})
+		return
+	}
+
+	// --- Fetch competitors ---
+	competitors, err := loadProjectCompetitors(ctx, database.Pool, id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "competitor query error"})
+		return
+	}
+
 	// --- Ensure Post-PO monitoring row exists (UPSERT) ---
 	// Supaya frontend selalu dapat object default
 	_, _ = database.Pool.Exec(ctx, `
@@ -169,6 +219,10 @@ func GetProject(c *gin.Context) {
 		Project:      p,
 		CustomerName: customerName,
 		RevenuePlans: plans,
+		Stakeholders: stakeholders,
+		CoOwners:     coOwners,
+		LineItems:    lineItems,
+		Competitors:  competitors,
 	}
 
 	if monErr == nil {
@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+
+	"sales-system-backend/database"
+	"sales-system-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+func loadProjectLineItems(ctx context.Context, db dbtx, projectID int64) ([]models.ProjectLineItem, error) {
+	rows, err := db.Query(ctx, `
+		SELECT li.id, li.project_id, li.product_id, pr.sku, pr.name, pr.category, pr.billing_type, pr.unit,
+		       li.price_book_id, li.qty::float8, li.unit_price::float8, li.discount_pct::float8,
+		       li.amount::float8, li.notes, li.created_at, li.updated_at
+		FROM project_line_items li
+		JOIN products pr ON pr.id = li.product_id
+		WHERE li.project_id = $1
+		ORDER BY li.id ASC
+	`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.ProjectLineItem{}
+	for rows.Next() {
+		var li models.ProjectLineItem
+		if err := rows.Scan(
+			&li.ID, &li.ProjectID, &li.ProductID, &li.SKU, &li.ProductName, &li.Category, &li.BillingType, &li.Unit,
+			&li.PriceBookID, &li.Qty, &li.UnitPrice, &li.DiscountPct,
+			&li.Amount, &li.Notes, &li.CreatedAt, &li.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, li)
+	}
+	return list, rows.Err()
+}
+
+// priceLineItem → unit price (manual atau dari price book) + amount
+func priceLineItem(ctx context.Context, db dbtx, projectID int64, req models.ProjectLineItemRequest) (float64, *int64, float64, string, error) {
+	var active bool
+	err := db.QueryRow(ctx, `SELECT is_active FROM products WHERE id = $1`, req.ProductID).Scan(&active)
+	if err == pgx.ErrNoRows {
+		return 0, nil, 0, "product not found", nil
+	}
+	if err != nil {
+		return 0, nil, 0, "", err
+	}
+	if !active {
+		return 0, nil, 0, "product is inactive", nil
+	}
+
+	if req.Qty <= 0 {
+		return 0, nil, 0, "qty must be > 0", nil
+	}
+	// diskon di sini masih usulan; approval lewat discount rules saat versi SPH
+	// dibangun dari line items (from_line_items) dan di-release
+	if req.DiscountPct < 0 || req.DiscountPct > 100 {
+		return 0, nil, 0, "discount_pct must be between 0 and 100", nil
+	}
+
+	var unitPrice float64
+	var priceBookID *int64
+	if req.UnitPrice != nil {
+		if *req.UnitPrice < 0 {
+			return 0, nil, 0, "unit_price must be >= 0", nil
+		}
+		unitPrice = *req.UnitPrice
+	} else {
+		division, industry, err := projectPriceContext(ctx, db, projectID)
+		if err != nil {
+			return 0, nil, 0, "", err
+		}
+		res, err := resolveProductPrice(ctx, db, req.ProductID, division, industry, jakartaToday())
+		if err != nil {
+			return 0, nil, 0, "", err
+		}
+		unitPrice = res.UnitPrice
+		priceBookID = res.PriceBookID
+	}
+
+	amount := round2(req.Qty * unitPrice * (1 - req.DiscountPct/100))
+	return unitPrice, priceBookID, amount, "", nil
+}
+
+// ======================================================
+// GET /projects/:id/line-items
+// ======================================================
+
+func ListProjectLineItems(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid project id"})
+		return
+	}
+	if _, ok := checkProjectAccess(c, projectID); !ok {
+		return
+	}
+
+	list, err := loadProjectLineItems(c, database.Pool, projectID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	total := 0.0
+	for _, li := range list {
+		total += li.Amount
+	}
+
+	c.JSON(200, gin.H{
+		"items": list,
+		"total": round2(total),
+	})
+}
+
+// ======================================================
+// POST /projects/:id/line-items {product_id, qty, unit_price?, discount_pct, notes}
+// ======================================================
+
+func AddProjectLineItem(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid project id"})
+		return
+	}
+	if _, ok := checkProjectAccess(c, projectID); !ok {
+		return
+	}
+
+	var req models.ProjectLineItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "product_id and qty are required"})
+		return
+	}
+
+	unitPrice, priceBookID, amount, msg, err := priceLineItem(c, database.Pool, projectID, req)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if msg != "" {
+		c.JSON(400, gin.H{"error": msg})
+		return
+	}
+
+	var id int64
+	err = database.Pool.QueryRow(c, `
+		INSERT INTO project_line_items
+			(project_id, product_id, price_book_id, qty, unit_price, discount_pct, amount, notes, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9::bigint, 0))
+		RETURNING id
+	`, projectID, req.ProductID, priceBookID, req.Qty, unitPrice, req.DiscountPct, amount, req.Notes,
+		c.GetInt64("user_id"),
+	).Scan(&id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"id":            id,
+		"unit_price":    unitPrice,
+		"price_book_id": priceBookID,
+		"amount":        amount,
+	})
+}
+
+// ======================================================
+// PUT /projects/:id/line-items/:itemId
+// unit_price kosong → harga di-resolve ulang
+// ======================================================
+
+func UpdateProjectLineItem(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid project id"})
+		return
+	}
+	itemID, err := strconv.ParseInt(c.Param("itemId"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid line item id"})
+		return
+	}
+	if _, ok := checkProjectAccess(c, projectID); !ok {
+		return
+	}
+
+	var req models.ProjectLineItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "product_id and qty are required"})
+		return
+	}
+
+	unitPrice, priceBookID, amount, msg, err := priceLineItem(c, database.Pool, projectID, req)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if msg != "" {
+		c.JSON(400, gin.H{"error": msg})
+		return
+	}
+
+	tag, err := database.Pool.Exec(c, `
+		UPDATE project_line_items SET
+			product_id = $1, price_book_id = $2, qty = $3, unit_price = $4,
+			discount_pct = $5, amount = $6, notes = $7, updated_at = NOW()
+		WHERE id = $8 AND project_id = $9
+	`, req.ProductID, priceBookID, req.Qty, unitPrice, req.DiscountPct, amount, req.Notes, itemID, projectID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(404, gin.H{"error": "line item not found"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "updated", "unit_price": unitPrice, "amount": amount})
+}
+
+// ======================================================
+// DELETE /projects/:id/line-items/:itemId
+// ======================================================
+
+func DeleteProjectLineItem(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid project id"})
+		return
+	}
+	itemID, err := strconv.ParseInt(c.Param("itemId"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid line item id"})
+		return
+	}
+	if _, ok := checkProjectAccess(c, projectID); !ok {
+		return
+	}
+
+	tag, err := database.Pool.Exec(c,
+		`DELETE FROM project_line_items WHERE id = $1 AND project_id = $2`, itemID, projectID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(404, gin.H{"error": "line item not found"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "deleted"})
+}
@@ -37,6 +37,8 @@ func ListProjects(c *gin.Context) {
 		"type":        "p.project_type",
 		"revenue":     "total_revenue",
 		"realization": "total_realization",
+		"owner":       "ow.username",
+		"activity":    "last_activity_at",
 	}
 
 	col, ok := allowed[sortBy]
@@ -46,6 +48,10 @@ func ListProjects(c *gin.Context) {
 	if sortDir != "asc" && sortDir != "desc" {
 		sortDir = "desc"
 	}
+	// project tanpa activity selalu di akhir
+	if col == "last_activity_at" {
+		sortDir += " NULLS LAST"
+	}
 
 	// --- ACL + FILTERS ---
 	role := c.GetString("role")
@@ -70,6 +76,28 @@ func ListProjects(c *gin.Context) {
 		}
 	}
 
+	// owner filter (?mine=true / ?owner_id=)
+	if cond, arg, ok := ownerFilterCond(c, i); ok {
+		whereParts = append(whereParts, cond)
+		args = append(args, arg)
+		i++
+	}
+
+	// deal "diam": tidak ada activity dalam N hari terakhir (?inactive_days=)
+	if v := strings.TrimSpace(c.Query("inactive_days")); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil || days <= 0 {
+			c.JSON(400, gin.H{"error": "invalid inactive_days"})
+			return
+		}
+		whereParts = append(whereParts, fmt.Sprintf(`NOT EXISTS (
+			SELECT 1 FROM activities a
+			WHERE a.project_id = p.id AND a.activity_date >= NOW() - make_interval(days => $%d)
+		)`, i))
+		args = append(args, days)
+		i++
+	}
+
 	whereClause := ""
 	if len(whereParts) > 0 {
 		whereClause = "WHERE " + strings.Join(whereParts, " AND ")
@@ -108,10 +136,18 @@ func ListProjects(c *gin.Context) {
 	m.stage2_status,
 	m.stage3_status,
 	m.stage4_status,
-	m.stage5_status
+	m.stage5_status,
+
+	-- owner (sales rep)
+	p.owner_user_id,
+	ow.username AS owner_name,
+
+	-- activity terakhir (deteksi deal stale)
+	(SELECT MAX(a.activity_date) FROM activities a WHERE a.project_id = p.id) AS last_activity_at
 
 	FROM projects p
 	LEFT JOIN customers cu ON cu.id = p.customer_id
+	LEFT JOIN users ow ON ow.id = p.owner_user_id
 	LEFT JOIN project_revenue_plan rp ON rp.project_id = p.id
 	LEFT JOIN project_postpo_monitoring m ON m.project_id = p.id
 	%s
@@ -139,7 +175,9 @@ func ListProjects(c *gin.Context) {
 	m.stage2_status,
 	m.stage3_status,
 	m.stage4_status,
-	m.stage5_status
+	m.stage5_status,
+	p.owner_user_id,
+	ow.username
 	ORDER BY %s %s
 	`, whereClause, col, sortDir)
 
@@ -171,6 +209,9 @@ func ListProjects(c *gin.Context) {
 		StartMonth              *string                   `json:"start_month"`
 		EndMonth                *string                   `json:"end_month"`
 		PostPOMonitoring        *PostPOMonitoringResponse `json:"postpo_monitoring,omitempty"`
+		OwnerUserID             *int64                    `json:"owner_user_id,omitempty"`
+		OwnerName               *string                   `json:"owner_name,omitempty"`
+		LastActivityAt          *time.Time                `json:"last_activity_at"`
 	}
 
 	var list []ProjectResponse
@@ -200,6 +241,9 @@ func ListProjects(c *gin.Context) {
 			&p.StartMonth,
 			&p.EndMonth,
 			&s1, &s2, &s3, &s4, &s5, // ✅ added
+			&p.OwnerUserID,
+			&p.OwnerName,
+			&p.LastActivityAt,
 		)
 		if err != nil {
 			fmt.Println("SCAN ERROR:", err)
@@ -237,6 +281,12 @@ func GetProjectsSummary(c *gin.Context) {
 		args = append(args, userDiv)
 	}
 
+	// my projects mode (?mine=true / ?owner_id=)
+	if cond, arg, ok := ownerFilterCond(c, len(args)+1); ok {
+		where += " AND " + cond
+		args = append(args, arg)
+	}
+
 	var resp models.ProjectSummaryResponse
 
 	// =============================
@@ -381,6 +431,13 @@ func ExportProjectsCSV(c *gin.Context) {
 		}
 	}
 
+	// Owner filter (?mine=true / ?owner_id=)
+	if cond, arg, ok := ownerFilterCond(c, i); ok {
+		whereParts = append(whereParts, cond)
+		args = append(args, arg)
+		i++
+	}
+
 	// Customer filter
 	if v := strings.TrimSpace(c.Query("customer_id")); v != "" && strings.ToUpper(v) != "ALL" {
 		if cid, err := strconv.ParseInt(v, 10, 64); err == nil {
@@ -526,6 +583,7 @@ SELECT
   COALESCE(cu.name,'') AS customer_name,
   p.project_type,
   p.status,
+  COALESCE(ow.username,'') AS owner_name,
 
   CASE p.sales_stage
     WHEN 1 THEN '1 - Prospecting'
@@ -582,6 +640,7 @@ SELECT
 
 FROM projects p
 LEFT JOIN customers cu ON cu.id = p.customer_id
+LEFT JOIN users ow ON ow.id = p.owner_user_id
 LEFT JOIN project_postpo_monitoring m ON m.project_id = p.id
 LEFT JOIN rp_rng ON rp_rng.project_id = p.id
 LEFT JOIN rp_year ON rp_year.project_id = p.id
@@ -605,7 +664,7 @@ ORDER BY p.project_code ASC
 	defer w.Flush()
 
 	headers := []string{
-		"Code", "Descriptions", "Divisi", "Customer", "Type", "Status",
+		"Code", "Descriptions", "Divisi", "Customer", "Type", "Status", "Owner",
 		"Stage", "Post PO Last Status",
 		"SPH Release?", "SPH Status", "Reason",
 		"Total Revenue", "Total Realization",
@@ -616,7 +675,7 @@ ORDER BY p.project_code ASC
 
 	for rows.Next() {
 		var (
-			code, desc, div, cust, typ, status                   string
+			code, desc, div, cust, typ, status, owner            string
 			stageText, postPoLast                                string
 			sphRel, sphStatus, reasonCat, reasonNote             string
 			totalRev, totalReal                                  string
@@ -625,7 +684,7 @@ ORDER BY p.project_code ASC
 		)
 
 		if err := rows.Scan(
-			&code, &desc, &div, &cust, &typ, &status,
+			&code, &desc, &div, &cust, &typ, &status, &owner,
 			&stageText, &postPoLast,
 			&sphRel, &sphStatus, &reasonCat, &reasonNote,
 			&totalRev, &totalReal,
@@ -652,6 +711,7 @@ ORDER BY p.project_code ASC
 			cust,
 			typ,
 			status,
+			owner,
 
 			stageText,
 			postPoLast,
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"sales-system-backend/database"
+	"sales-system-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+func loadProjectCoOwners(ctx context.Context, projectID int64) ([]models.ProjectOwnerRef, error) {
+	rows, err := database.Pool.Query(ctx, `
+		SELECT u.id, u.username, u.division, u.email
+		FROM project_co_owners po
+		JOIN users u ON u.id = po.user_id
+		WHERE po.project_id = $1
+		ORDER BY u.username ASC
+	`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.ProjectOwnerRef{}
+	for rows.Next() {
+		var o models.ProjectOwnerRef
+		if err := rows.Scan(&o.UserID, &o.Username, &o.Division, &o.Email); err != nil {
+			return nil, err
+		}
+		list = append(list, o)
+	}
+
+	return list, rows.Err()
+}
+
+// validateProjectOwnerTx → user harus aktif; role user harus satu divisi dengan project
+func validateProjectOwnerTx(ctx context.Context, tx pgx.Tx, userID int64, projectDivision string) string {
+	var role, division string
+	var active bool
+	err := tx.QueryRow(ctx,
+		`SELECT role, division, is_active FROM users WHERE id = $1`, userID,
+	).Scan(&role, &division, &active)
+	if err != nil {
+		return fmt.Sprintf("user %d not found", userID)
+	}
+	if !active {
+		return fmt.Sprintf("user %d is deactivated", userID)
+	}
+	if role == "user" && NormalizeDivision(division) != NormalizeDivision(projectDivision) {
+		return fmt.Sprintf("user %d is not in the project's division", userID)
+	}
+	return ""
+}
+
+// ======================================================
+// REASSIGN OWNER (ADMIN) — PUT /projects/:id/owner
+// {owner_user_id, co_owner_ids?}
+// ======================================================
+
+func SetProjectOwner(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+
+	var req models.SetProjectOwnerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "owner_user_id is required"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var projectCode, division string
+	var prevOwner *int64
+	err = tx.QueryRow(ctx, `
+		SELECT project_code, division, owner_user_id FROM projects WHERE id = $1 FOR UPDATE
+	`, projectID).Scan(&projectCode, &division, &prevOwner)
+	if err != nil {
+		c.JSON(404, gin.H{"error": "project not found"})
+		return
+	}
+
+	if msg := validateProjectOwnerTx(ctx, tx, req.OwnerUserID, division); msg != "" {
+		c.JSON(400, gin.H{"error": msg})
+		return
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE projects SET owner_user_id = $1, updated_at = NOW() WHERE id = $2
+	`, req.OwnerUserID, projectID); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	// owner utama tidak perlu tercatat sebagai co-owner
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM project_co_owners WHERE project_id = $1 AND user_id = $2
+	`, projectID, req.OwnerUserID); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.CoOwnerIDs != nil {
+		if _, err := tx.Exec(ctx, `DELETE FROM project_co_owners WHERE project_id = $1`, projectID); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		for _, uid := range *req.CoOwnerIDs {
+			if uid == req.OwnerUserID {
+				continue
+			}
+			if msg := validateProjectOwnerTx(ctx, tx, uid, division); msg != "" {
+				c.JSON(400, gin.H{"error": msg})
+				return
+			}
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO project_co_owners (project_id, user_id)
+				VALUES ($1, $2)
+				ON CONFLICT DO NOTHING
+			`, projectID, uid); err != nil {
+				c.JSON(500, gin.H{"error": err.Error()})
+				return
+			}
+		}
+	}
+
+	// kredit realisasi pindah owner → period komisi closed dihitung ulang
+	if prevOwner == nil || *prevOwner != req.OwnerUserID {
+		months, err := projectCommissionMonthsTx(ctx, tx, projectID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		if err := recalcClosedCommissionRunsTx(ctx, tx, "a project owner change", months...); err != nil {
+			c.JSON(500, gin.H{"error": fmt.Sprintf("commission recalculation failed: %v", err)})
+			return
+		}
+	}
+
+	// notif ke owner baru
+	if prevOwner == nil || *prevOwner != req.OwnerUserID {
+		if err := createNotification(ctx, tx, req.OwnerUserID,
+			"project_owner",
+			"Project assigned: "+projectCode,
+			fmt.Sprintf("You are now the owner of project %s.", projectCode),
+			"project", projectID,
+		); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "updated", "owner_user_id": req.OwnerUserID})
+}
@@ -129,6 +129,12 @@ func UpdateRevenueRealization(c *gin.Context) {
 		}
 	}
 
+	// 4) Period komisi yang sudah closed ikut dihitung ulang
+	if err := recalcClosedCommissionRunsTx(ctx, tx, "a realization update", applyMonth, sourceMonth); err != nil {
+		c.JSON(500, gin.H{"error": fmt.Sprintf("commission recalculation failed: %v", err)})
+		return
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		c.JSON(500, gin.H{"error": "tx commit failed"})
 		return
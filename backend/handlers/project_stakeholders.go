@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"sales-system-backend/database"
+	"sales-system-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func loadProjectStakeholders(ctx context.Context, projectID int64) ([]models.ProjectStakeholder, error) {
+	rows, err := database.Pool.Query(ctx, `
+		SELECT
+			ct.id, ct.customer_id, cu.name,
+			ct.name, ct.title, ct.email, ct.phone, ct.buying_role, ct.is_primary,
+			ps.note
+		FROM project_stakeholders ps
+		JOIN customer_contacts ct ON ct.id = ps.contact_id
+		JOIN customers cu ON cu.id = ct.customer_id
+		WHERE ps.project_id = $1
+		ORDER BY ct.is_primary DESC, ct.name ASC
+	`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.ProjectStakeholder{}
+	for rows.Next() {
+		var s models.ProjectStakeholder
+		if err := rows.Scan(
+			&s.ContactID, &s.CustomerID, &s.CustomerName,
+			&s.Name, &s.Title, &s.Email, &s.Phone, &s.BuyingRole, &s.IsPrimary,
+			&s.Note,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+
+	return list, rows.Err()
+}
+
+// ======================================================
+// ADD STAKEHOLDER (contact harus dari customer / group customer project)
+// ======================================================
+
+func AddProjectStakeholder(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+
+	var body struct {
+		ContactID int64   `json:"contact_id" binding:"required"`
+		Note      *string `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "invalid body"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	p, ok := checkProjectAccess(c, projectID)
+	if !ok {
+		return
+	}
+
+	if p.CustomerID == nil {
+		c.JSON(400, gin.H{"error": "project has no customer"})
+		return
+	}
+
+	var contactCustomerID int64
+	err = database.Pool.QueryRow(ctx,
+		`SELECT customer_id FROM customer_contacts WHERE id = $1`,
+		body.ContactID,
+	).Scan(&contactCustomerID)
+	if err != nil {
+		c.JSON(404, gin.H{"error": "contact not found"})
+		return
+	}
+
+	// boleh contact dari customer lain dalam group yang sama (mis. holding)
+	var sameGroup bool
+	if err := database.Pool.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1
+			FROM customer_group_roots a
+			JOIN customer_group_roots b ON b.root_id = a.root_id
+			WHERE a.customer_id = $1 AND b.customer_id = $2
+		)
+	`, *p.CustomerID, contactCustomerID).Scan(&sameGroup); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !sameGroup {
+		c.JSON(400, gin.H{"error": "contact does not belong to the project's customer"})
+		return
+	}
+
+	_, err = database.Pool.Exec(ctx, `
+		INSERT INTO project_stakeholders (project_id, contact_id, note)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (project_id, contact_id) DO UPDATE SET note = EXCLUDED.note
+	`, projectID, body.ContactID, body.Note)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "ok"})
+}
+
+// ======================================================
+// REMOVE STAKEHOLDER
+// ======================================================
+
+func RemoveProjectStakeholder(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+	contactID, err := strconv.ParseInt(c.Param("contactId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid contact id"})
+		return
+	}
+
+	if _, ok := checkProjectAccess(c, projectID); !ok {
+		return
+	}
+
+	tag, err := database.Pool.Exec(c.Request.Context(),
+		`DELETE FROM project_stakeholders WHERE project_id = $1 AND contact_id = $2`,
+		projectID, contactID,
+	)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if tag.RowsAffected() == 0 {
+		c.JSON(404, gin.H{"error": "stakeholder not found"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "deleted"})
+}
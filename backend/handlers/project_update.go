@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
@@ -99,6 +100,12 @@ func UpdateProject(c *gin.Context) {
 		}
 	}
 
+	// --- Kompetitor pemenang hanya dicatat untuk Loss ---
+	// tidak dikirim (nil) → nilai lama dipertahankan selama masih Loss
+	if body.SPHStatus == nil || *body.SPHStatus != "Loss" {
+		body.WinningCompetitorID = nil
+	}
+
 	// --- Validate division ---
 	if !isValidDivision(body.Division) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid division"})
@@ -113,6 +120,65 @@ func UpdateProject(c *gin.Context) {
 	}
 	defer tx.Rollback(ctx)
 
+	// --- SPH number: manual harus unik, release tanpa nomor → generate ---
+	var existingSPHNumber *string
+	var existingReleaseStatus, existingProjectType string
+	if err := tx.QueryRow(ctx,
+		`SELECT sph_number, COALESCE(sph_release_status, ''), project_type FROM projects WHERE id = $1 FOR UPDATE`, id,
+	).Scan(&existingSPHNumber, &existingReleaseStatus, &existingProjectType); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	// release baru untuk project dengan dokumen SPH → lewat versi (cek approval diskon)
+	if body.SphReleaseStatus == "Yes" && existingReleaseStatus != "Yes" {
+		hasDocument, err := projectHasSPHVersions(ctx, tx, id)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		if hasDocument {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "project has SPH document versions; release a version via /sph/versions/:versionId/release",
+			})
+			return
+		}
+	}
+
+	if body.WinningCompetitorID != nil {
+		msg, err := checkWinningCompetitorTx(ctx, tx, id, *body.WinningCompetitorID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		if msg != "" {
+			c.JSON(400, gin.H{"error": msg})
+			return
+		}
+	}
+
+	releaseDate := parseDatePtr(body.SPHRelease)
+	if body.SphReleaseStatus == "Yes" && releaseDate == nil {
+		today := jakartaToday()
+		releaseDate = &today
+	}
+
+	sphAt := jakartaToday()
+	if releaseDate != nil {
+		sphAt = *releaseDate
+	}
+
+	sphNumber, conflict, err := resolveSPHNumberTx(ctx, tx, id, body.Division,
+		body.SphNumber, existingSPHNumber, body.SphReleaseStatus, sphAt)
+	if err != nil {
+		c.JSON(500, gin.H{"error": fmt.Sprintf("failed generate sph number: %v", err)})
+		return
+	}
+	if conflict != "" {
+		c.JSON(http.StatusConflict, gin.H{"error": conflict})
+		return
+	}
+
 	// --- Update Project ---
 	_, err = tx.Exec(ctx, `
 	UPDATE projects
@@ -121,15 +187,18 @@ func UpdateProject(c *gin.Context) {
 			division                 = $3,
 			status                   = $4,
 			project_type             = $5,
+			sph_status_changed_at    = CASE WHEN sph_status IS DISTINCT FROM $6 THEN NOW() ELSE sph_status_changed_at END,
 			sph_status               = $6,
 			sph_release_date         = $7,
+			stage_changed_at         = CASE WHEN sales_stage IS DISTINCT FROM $8 THEN NOW() ELSE stage_changed_at END,
 			sales_stage              = $8,
 			sph_release_status       = $9,
 			sph_number               = $10,
 			sph_status_reason_category = $11,
 			sph_status_reason_note     = $12,
+			winning_competitor_id    = CASE WHEN $6 = 'Loss' THEN COALESCE($13, winning_competitor_id) END,
 			updated_at               = NOW()
-	WHERE id = $13
+	WHERE id = $14
 	`,
 		body.Description,
 		body.CustomerID,
@@ -137,12 +206,13 @@ func UpdateProject(c *gin.Context) {
 		body.Status,
 		body.ProjectType,
 		body.SPHStatus,
-		parseDatePtr(body.SPHRelease),
+		releaseDate,
 		body.SalesStage,
 		body.SphReleaseStatus,
-		body.SphNumber,
+		sphNumber,
 		body.SPHStatusReasonCategory,
 		body.SPHStatusReasonNote,
+		body.WinningCompetitorID,
 		id,
 	)
 
@@ -150,6 +220,13 @@ func UpdateProject(c *gin.Context) {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
+
+	if body.WinningCompetitorID != nil {
+		if err := linkWinningCompetitorTx(ctx, tx, id, *body.WinningCompetitorID); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+	}
 	// --- Upsert revenue plans (preserve target_realization) ---
 	for _, rp := range body.RevenuePlans {
 		month, err := time.Parse("2006-01", rp.Month)
@@ -172,11 +249,24 @@ func UpdateProject(c *gin.Context) {
 		}
 	}
 
+	// --- project_type menentukan rate komisi → period closed dihitung ulang ---
+	if body.ProjectType != existingProjectType {
+		months, err := projectCommissionMonthsTx(ctx, tx, id)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		if err := recalcClosedCommissionRunsTx(ctx, tx, "a project type change", months...); err != nil {
+			c.JSON(500, gin.H{"error": fmt.Sprintf("commission recalculation failed: %v", err)})
+			return
+		}
+	}
+
 	// --- Commit ---
 	if err := tx.Commit(ctx); err != nil {
 		c.JSON(500, gin.H{"error": "transaction commit error"})
 		return
 	}
 
-	c.JSON(200, gin.H{"status": "ok"})
+	c.JSON(200, gin.H{"status": "ok", "sph_number": sphNumber})
 }
@@ -0,0 +1,536 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"sales-system-backend/database"
+	"sales-system-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// granularitas period (lebih kecil = lebih detail)
+var periodRank = map[string]int{"month": 1, "quarter": 2, "year": 3}
+
+var quotaRevenueTypes = map[string]bool{
+	"all": true, "Project Based": true, "Recurring": true, "New Recurring": true,
+}
+
+// parsePeriod → [start, end) untuk "2026-05" (month), "2026-Q2" (quarter), "2026" (year)
+func parsePeriod(periodType, s string) (time.Time, time.Time, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+
+	switch periodType {
+	case "month":
+		t, err := time.Parse("2006-01", s)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid month period (use YYYY-MM)")
+		}
+		return t, t.AddDate(0, 1, 0), nil
+
+	case "quarter":
+		parts := strings.SplitN(s, "-Q", 2)
+		if len(parts) != 2 {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid quarter period (use YYYY-Qn)")
+		}
+		y, err1 := strconv.Atoi(parts[0])
+		q, err2 := strconv.Atoi(parts[1])
+		if err1 != nil || err2 != nil || q < 1 || q > 4 || y < 2000 || y > 2100 {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid quarter period (use YYYY-Qn)")
+		}
+		start := time.Date(y, time.Month((q-1)*3+1), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 3, 0), nil
+
+	case "year":
+		y, err := strconv.Atoi(s)
+		if err != nil || y < 2000 || y > 2100 {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid year period (use YYYY)")
+		}
+		start := time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(1, 0, 0), nil
+	}
+
+	return time.Time{}, time.Time{}, fmt.Errorf("period_type must be month, quarter or year")
+}
+
+func formatPeriod(periodType string, start time.Time) string {
+	switch periodType {
+	case "month":
+		return start.Format("2006-01")
+	case "quarter":
+		return fmt.Sprintf("%d-Q%d", start.Year(), (int(start.Month())-1)/3+1)
+	default:
+		return strconv.Itoa(start.Year())
+	}
+}
+
+// period berjalan (Asia/Jakarta)
+func currentPeriod(periodType string) string {
+	now := time.Now().In(mustLoadLocation("Asia/Jakarta"))
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return formatPeriod(periodType, start)
+}
+
+func normalizeRevenueType(rt string) (string, bool) {
+	rt = strings.TrimSpace(rt)
+	if rt == "" || strings.EqualFold(rt, "all") {
+		return "all", true
+	}
+	return rt, quotaRevenueTypes[rt]
+}
+
+// loadQuotaTotals → key ("u:<id>" / "d:<division>") → period_type → total quota di [start, end)
+func loadQuotaTotals(ctx context.Context, db dbtx, periodType string, start, end time.Time, revenueType string) (map[string]map[string]float64, error) {
+	rows, err := db.Query(ctx, `
+		SELECT q.scope, q.user_id, COALESCE(q.division, ''), q.period_type, SUM(q.amount)::float8
+		FROM quotas q
+		WHERE q.period_start >= $1 AND q.period_start < $2
+		  AND q.revenue_type = $3
+		GROUP BY 1, 2, 3, 4
+	`, start, end, revenueType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byKey := map[string]map[string]float64{}
+	for rows.Next() {
+		var scope, div, pt string
+		var uid *int64
+		var amount float64
+		if err := rows.Scan(&scope, &uid, &div, &pt, &amount); err != nil {
+			return nil, err
+		}
+		if periodRank[pt] > periodRank[periodType] {
+			continue
+		}
+		key := "d:" + NormalizeDivision(div)
+		if scope == "user" && uid != nil {
+			key = "u:" + strconv.FormatInt(*uid, 10)
+		}
+		if byKey[key] == nil {
+			byKey[key] = map[string]float64{}
+		}
+		byKey[key][pt] += amount
+	}
+
+	return byKey, rows.Err()
+}
+
+// resolveQuota → pilih granularitas paling kasar yang tersedia (year → quarter → month)
+func resolveQuota(byKey map[string]map[string]float64, key, periodType string) (float64, bool) {
+	m, ok := byKey[key]
+	if !ok {
+		return 0, false
+	}
+	for _, pt := range []string{"year", "quarter", "month"} {
+		if periodRank[pt] > periodRank[periodType] {
+			continue
+		}
+		if v, ok := m[pt]; ok {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// ======================================================
+// LIST QUOTAS — GET /quotas?year=&division=&user_id=&scope=
+// ======================================================
+
+func ListQuotas(c *gin.Context) {
+	role := c.GetString("role")
+	userDiv := NormalizeDivision(c.GetString("division"))
+
+	conds := []string{"1=1"}
+	args := []any{}
+	i := 1
+
+	division := NormalizeDivision(strings.TrimSpace(c.Query("division")))
+	if role == "user" {
+		division = userDiv
+	}
+	if division != "" && strings.ToUpper(division) != "ALL" {
+		conds = append(conds, fmt.Sprintf("COALESCE(q.division, u.division) = $%d", i))
+		args = append(args, division)
+		i++
+	}
+
+	if y := strings.TrimSpace(c.Query("year")); y != "" {
+		year, err := strconv.Atoi(y)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid year"})
+			return
+		}
+		conds = append(conds, fmt.Sprintf("EXTRACT(YEAR FROM q.period_start)::int = $%d", i))
+		args = append(args, year)
+		i++
+	}
+
+	if v := strings.TrimSpace(c.Query("user_id")); v != "" {
+		uid, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid user_id"})
+			return
+		}
+		conds = append(conds, fmt.Sprintf("q.user_id = $%d", i))
+		args = append(args, uid)
+		i++
+	}
+
+	if v := strings.TrimSpace(c.Query("scope")); v != "" {
+		conds = append(conds, fmt.Sprintf("q.scope = $%d", i))
+		args = append(args, v)
+		i++
+	}
+
+	rows, err := database.Pool.Query(c, fmt.Sprintf(`
+		SELECT
+			q.id, q.scope, q.user_id, u.username, COALESCE(q.division, u.division, ''),
+			q.period_type, q.period_start, q.revenue_type, q.amount::float8,
+			q.created_at, q.updated_at
+		FROM quotas q
+		LEFT JOIN users u ON u.id = q.user_id
+		WHERE %s
+		ORDER BY q.period_start ASC, q.scope ASC, 5 ASC, u.username ASC
+	`, strings.Join(conds, " AND ")), args...)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	list := []models.Quota{}
+	for rows.Next() {
+		var q models.Quota
+		var start time.Time
+		if err := rows.Scan(
+			&q.ID, &q.Scope, &q.UserID, &q.Username, &q.Division,
+			&q.PeriodType, &start, &q.RevenueType, &q.Amount,
+			&q.CreatedAt, &q.UpdatedAt,
+		); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		q.Division = NormalizeDivision(q.Division)
+		q.Period = formatPeriod(q.PeriodType, start)
+		list = append(list, q)
+	}
+
+	c.JSON(200, list)
+}
+
+// ======================================================
+// SET QUOTA (ADMIN, upsert) — POST /quotas
+// ======================================================
+
+func SetQuota(c *gin.Context) {
+	var req models.QuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "invalid body"})
+		return
+	}
+
+	if req.Amount <= 0 {
+		c.JSON(400, gin.H{"error": "amount must be > 0"})
+		return
+	}
+
+	start, _, err := parsePeriod(req.PeriodType, req.Period)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	revenueType, ok := normalizeRevenueType(req.RevenueType)
+	if !ok {
+		c.JSON(400, gin.H{"error": "invalid revenue_type"})
+		return
+	}
+
+	var userID *int64
+	var division *string
+
+	switch req.Scope {
+	case "user":
+		if req.UserID == nil {
+			c.JSON(400, gin.H{"error": "user_id is required for user quota"})
+			return
+		}
+		var exists bool
+		if err := database.Pool.QueryRow(c, `SELECT EXISTS(SELECT 1 FROM users WHERE id=$1)`, *req.UserID).Scan(&exists); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		if !exists {
+			c.JSON(404, gin.H{"error": "user not found"})
+			return
+		}
+		userID = req.UserID
+
+	case "division":
+		d := NormalizeDivision(req.Division)
+		if !isValidDivision(d) {
+			c.JSON(400, gin.H{"error": "invalid division"})
+			return
+		}
+		division = &d
+
+	default:
+		c.JSON(400, gin.H{"error": "scope must be user or division"})
+		return
+	}
+
+	var id int64
+	err = database.Pool.QueryRow(c, `
+		INSERT INTO quotas (scope, user_id, division, period_type, period_start, revenue_type, amount, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8::bigint, 0))
+		ON CONFLICT (scope, COALESCE(user_id, 0), COALESCE(division, ''), period_type, period_start, revenue_type)
+		DO UPDATE SET amount = EXCLUDED.amount, updated_at = NOW()
+		RETURNING id
+	`, req.Scope, userID, division, req.PeriodType, start, revenueType, req.Amount, c.GetInt64("user_id")).Scan(&id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"id": id, "period": formatPeriod(req.PeriodType, start)})
+}
+
+// ======================================================
+// UPDATE / DELETE QUOTA (ADMIN)
+// ======================================================
+
+func UpdateQuota(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid quota id"})
+		return
+	}
+
+	var req models.UpdateQuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Amount <= 0 {
+		c.JSON(400, gin.H{"error": "amount must be > 0"})
+		return
+	}
+
+	tag, err := database.Pool.Exec(c,
+		`UPDATE quotas SET amount = $1, updated_at = NOW() WHERE id = $2`, req.Amount, id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(404, gin.H{"error": "quota not found"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "updated"})
+}
+
+func DeleteQuota(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid quota id"})
+		return
+	}
+
+	tag, err := database.Pool.Exec(c, `DELETE FROM quotas WHERE id = $1`, id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(404, gin.H{"error": "quota not found"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "deleted"})
+}
+
+// ======================================================
+// ATTAINMENT + LEADERBOARD
+// GET /quotas/attainment?period_type=quarter&period=2026-Q2&revenue_type=all&division=
+//
+// quota period = quota dengan period_type sama; kalau tidak ada,
+// dijumlah dari quota yang lebih detail di dalam period (quarter → month).
+// realisasi = project_revenue_plan.target_realization;
+// user dikredit lewat projects.owner_user_id.
+// ======================================================
+
+func GetQuotaAttainment(c *gin.Context) {
+	role := c.GetString("role")
+	userDiv := NormalizeDivision(c.GetString("division"))
+
+	periodType := strings.ToLower(strings.TrimSpace(c.DefaultQuery("period_type", "quarter")))
+	if _, ok := periodRank[periodType]; !ok {
+		c.JSON(400, gin.H{"error": "period_type must be month, quarter or year"})
+		return
+	}
+
+	period := strings.TrimSpace(c.Query("period"))
+	if period == "" {
+		period = currentPeriod(periodType)
+	}
+
+	start, end, err := parsePeriod(periodType, period)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	revenueType, ok := normalizeRevenueType(c.Query("revenue_type"))
+	if !ok {
+		c.JSON(400, gin.H{"error": "invalid revenue_type"})
+		return
+	}
+
+	division := NormalizeDivision(strings.TrimSpace(c.Query("division")))
+	if role == "user" {
+		division = userDiv
+	}
+	if strings.ToUpper(division) == "ALL" {
+		division = ""
+	}
+	if division != "" && !isValidDivision(division) {
+		c.JSON(400, gin.H{"error": "invalid division"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	byKey, err := loadQuotaTotals(ctx, database.Pool, periodType, start, end, revenueType)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	quotaFor := func(key string) (float64, bool) {
+		return resolveQuota(byKey, key, periodType)
+	}
+
+	// --- realisasi per divisi + owner ---
+	rConds := []string{"rp.month >= $1", "rp.month < $2"}
+	rArgs := []any{start, end}
+	if revenueType != "all" {
+		rConds = append(rConds, fmt.Sprintf("p.project_type = $%d", len(rArgs)+1))
+		rArgs = append(rArgs, revenueType)
+	}
+
+	rRows, err := database.Pool.Query(ctx, fmt.Sprintf(`
+		SELECT p.division, p.owner_user_id, COALESCE(SUM(rp.target_realization), 0)::float8
+		FROM project_revenue_plan rp
+		JOIN projects p ON p.id = rp.project_id
+		WHERE %s
+		GROUP BY 1, 2
+	`, strings.Join(rConds, " AND ")), rArgs...)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	defer rRows.Close()
+
+	divReal := map[string]float64{}
+	userReal := map[int64]float64{}
+	for rRows.Next() {
+		var div string
+		var owner *int64
+		var v float64
+		if err := rRows.Scan(&div, &owner, &v); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		divReal[NormalizeDivision(div)] += v
+		if owner != nil {
+			userReal[*owner] += v
+		}
+	}
+	rRows.Close()
+
+	// --- users ---
+	uRows, err := database.Pool.Query(ctx, `
+		SELECT id, username, division, role FROM users WHERE is_active ORDER BY username
+	`)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	defer uRows.Close()
+
+	leaderboards := map[string][]models.QuotaAttainmentRow{}
+	userQuotaSum := map[string]float64{}
+	for uRows.Next() {
+		var id int64
+		var username, div, urole string
+		if err := uRows.Scan(&id, &username, &div, &urole); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		div = NormalizeDivision(div)
+
+		quota, hasQuota := quotaFor("u:" + strconv.FormatInt(id, 10))
+		real := userReal[id]
+		if !hasQuota && real == 0 && urole != "user" {
+			continue
+		}
+
+		userQuotaSum[div] += quota
+		leaderboards[div] = append(leaderboards[div], models.QuotaAttainmentRow{
+			UserID:        id,
+			Username:      username,
+			Quota:         quota,
+			Realization:   real,
+			AttainmentPct: pct(real, quota),
+		})
+	}
+	uRows.Close()
+
+	resp := models.QuotaAttainmentResponse{
+		PeriodType:  periodType,
+		Period:      formatPeriod(periodType, start),
+		Start:       start.Format("2006-01-02"),
+		End:         end.Format("2006-01-02"),
+		RevenueType: revenueType,
+		Divisions:   []models.QuotaAttainmentDivision{},
+	}
+
+	for _, div := range canonicalDivisions {
+		if division != "" && div != division {
+			continue
+		}
+
+		quota, ok := quotaFor("d:" + div)
+		if !ok {
+			// belum ada quota divisi → jumlah quota user di divisi tsb
+			quota = userQuotaSum[div]
+		}
+
+		board := leaderboards[div]
+		if board == nil {
+			board = []models.QuotaAttainmentRow{}
+		}
+		sort.SliceStable(board, func(i, j int) bool {
+			if board[i].AttainmentPct != board[j].AttainmentPct {
+				return board[i].AttainmentPct > board[j].AttainmentPct
+			}
+			return board[i].Realization > board[j].Realization
+		})
+		for i := range board {
+			board[i].Rank = i + 1
+		}
+
+		resp.Divisions = append(resp.Divisions, models.QuotaAttainmentDivision{
+			Division:      div,
+			Quota:         quota,
+			Realization:   divReal[div],
+			AttainmentPct: pct(divReal[div], quota),
+			Leaderboard:   board,
+		})
+	}
+
+	c.JSON(200, resp)
+}
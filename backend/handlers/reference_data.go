@@ -0,0 +1,493 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"sales-system-backend/database"
+	"sales-system-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// cleanAliases → trim, lowercase, dedupe (pencocokan alias case-insensitive)
+func cleanAliases(in []string) []string {
+	out := []string{}
+	seen := map[string]bool{}
+	for _, a := range in {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == "" || seen[a] {
+			continue
+		}
+		seen[a] = true
+		out = append(out, a)
+	}
+	return out
+}
+
+// isBlankReference → nilai kosong / placeholder "-" dianggap tidak diisi
+func isBlankReference(s string) bool {
+	s = strings.TrimSpace(s)
+	return s == "" || s == "-"
+}
+
+// resolveIndustry → nama kanonik dari industries (nama / alias).
+// ok=false kalau tidak dikenal; input kosong → "", true
+func resolveIndustry(ctx context.Context, s string) (string, bool, error) {
+	if isBlankReference(s) {
+		return "", true, nil
+	}
+	var name *string
+	if err := database.Pool.QueryRow(ctx, `SELECT resolve_industry($1)`, s).Scan(&name); err != nil {
+		return "", false, err
+	}
+	if name == nil {
+		return "", false, nil
+	}
+	return *name, true, nil
+}
+
+// resolveRegion → nama region kanonik (nama / alias region, nama / alias provinsi)
+func resolveRegion(ctx context.Context, s string) (string, bool, error) {
+	if isBlankReference(s) {
+		return "", true, nil
+	}
+	var name *string
+	if err := database.Pool.QueryRow(ctx, `SELECT resolve_region($1)`, s).Scan(&name); err != nil {
+		return "", false, err
+	}
+	if name == nil {
+		return "", false, nil
+	}
+	return *name, true, nil
+}
+
+// ======================================================
+// INDUSTRIES
+// ======================================================
+
+func ListIndustries(c *gin.Context) {
+	rows, err := database.Pool.Query(c, `
+		SELECT i.id, i.name, i.aliases,
+		       (SELECT COUNT(*) FROM customers cu WHERE cu.industry = i.name),
+		       i.created_at, i.updated_at
+		FROM industries i
+		ORDER BY i.name ASC
+	`)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	list := []models.Industry{}
+	for rows.Next() {
+		var it models.Industry
+		if err := rows.Scan(&it.ID, &it.Name, &it.Aliases, &it.CustomerCount, &it.CreatedAt, &it.UpdatedAt); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		list = append(list, it)
+	}
+
+	c.JSON(200, list)
+}
+
+func CreateIndustry(c *gin.Context) {
+	var req models.IndustryRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Name == nil || strings.TrimSpace(*req.Name) == "" {
+		c.JSON(400, gin.H{"error": "name is required"})
+		return
+	}
+
+	aliases := []string{}
+	if req.Aliases != nil {
+		aliases = cleanAliases(*req.Aliases)
+	}
+
+	var id int64
+	err := database.Pool.QueryRow(c, `
+		INSERT INTO industries (name, aliases)
+		VALUES ($1, $2)
+		ON CONFLICT (name) DO NOTHING
+		RETURNING id
+	`, strings.TrimSpace(*req.Name), aliases).Scan(&id)
+	if err == pgx.ErrNoRows {
+		c.JSON(409, gin.H{"error": "industry already exists"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(201, gin.H{"id": id})
+}
+
+// rename ikut ter-cascade ke customers.industry (FK ON UPDATE CASCADE)
+func UpdateIndustry(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid industry id"})
+		return
+	}
+
+	var req models.IndustryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "invalid body"})
+		return
+	}
+
+	var name *string
+	if req.Name != nil {
+		n := strings.TrimSpace(*req.Name)
+		if n == "" {
+			c.JSON(400, gin.H{"error": "name cannot be empty"})
+			return
+		}
+		name = &n
+	}
+
+	var aliases []string
+	if req.Aliases != nil {
+		aliases = cleanAliases(*req.Aliases)
+	}
+
+	tag, err := database.Pool.Exec(c, `
+		UPDATE industries
+		SET name       = COALESCE($1, name),
+		    aliases    = COALESCE($2, aliases),
+		    updated_at = NOW()
+		WHERE id = $3
+	`, name, aliases, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			c.JSON(409, gin.H{"error": "industry already exists"})
+			return
+		}
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if tag.RowsAffected() == 0 {
+		c.JSON(404, gin.H{"error": "industry not found"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "updated"})
+}
+
+func DeleteIndustry(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid industry id"})
+		return
+	}
+
+	var used int64
+	if err := database.Pool.QueryRow(c, `
+		SELECT COUNT(*) FROM customers cu
+		JOIN industries i ON i.name = cu.industry
+		WHERE i.id = $1
+	`, id).Scan(&used); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if used > 0 {
+		c.JSON(409, gin.H{"error": "industry is used by customers", "customer_count": used})
+		return
+	}
+
+	var books int64
+	if err := database.Pool.QueryRow(c, `
+		SELECT COUNT(*) FROM price_books pb
+		JOIN industries i ON i.name = pb.industry
+		WHERE i.id = $1
+	`, id).Scan(&books); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if books > 0 {
+		c.JSON(409, gin.H{"error": "industry is used by price books", "price_book_count": books})
+		return
+	}
+
+	tag, err := database.Pool.Exec(c, `DELETE FROM industries WHERE id = $1`, id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if tag.RowsAffected() == 0 {
+		c.JSON(404, gin.H{"error": "industry not found"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "deleted"})
+}
+
+// ======================================================
+// REGIONS (+ mapping provinsi)
+// ======================================================
+
+func ListRegions(c *gin.Context) {
+	rows, err := database.Pool.Query(c, `
+		SELECT r.id, r.name, r.aliases,
+		       COALESCE((SELECT array_agg(pv.name ORDER BY pv.name) FROM provinces pv WHERE pv.region_id = r.id), '{}'),
+		       (SELECT COUNT(*) FROM customers cu WHERE cu.region = r.name),
+		       r.created_at, r.updated_at
+		FROM regions r
+		ORDER BY r.name ASC
+	`)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	list := []models.Region{}
+	for rows.Next() {
+		var r models.Region
+		if err := rows.Scan(&r.ID, &r.Name, &r.Aliases, &r.Provinces, &r.CustomerCount, &r.CreatedAt, &r.UpdatedAt); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		list = append(list, r)
+	}
+
+	c.JSON(200, list)
+}
+
+func ListProvinces(c *gin.Context) {
+	rows, err := database.Pool.Query(c, `
+		SELECT pv.id, pv.name, pv.aliases, pv.region_id, r.name
+		FROM provinces pv
+		LEFT JOIN regions r ON r.id = pv.region_id
+		ORDER BY pv.name ASC
+	`)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	list := []models.Province{}
+	for rows.Next() {
+		var p models.Province
+		if err := rows.Scan(&p.ID, &p.Name, &p.Aliases, &p.RegionID, &p.RegionName); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		list = append(list, p)
+	}
+
+	c.JSON(200, list)
+}
+
+// setRegionProvincesTx → provinsi di list dipindah ke region ini, sisanya dilepas
+func setRegionProvincesTx(ctx context.Context, tx pgx.Tx, regionID int64, provinces []string) (string, error) {
+	names := []string{}
+	for _, p := range provinces {
+		if p = strings.TrimSpace(p); p != "" {
+			names = append(names, p)
+		}
+	}
+
+	var found int
+	if err := tx.QueryRow(ctx,
+		`SELECT COUNT(*) FROM provinces WHERE lower(name) = ANY (SELECT lower(x) FROM unnest($1::text[]) x)`,
+		names,
+	).Scan(&found); err != nil {
+		return "", err
+	}
+	if found != len(names) {
+		return "unknown province in list", nil
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE provinces SET region_id = NULL WHERE region_id = $1`, regionID,
+	); err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE provinces SET region_id = $1
+		WHERE lower(name) = ANY (SELECT lower(x) FROM unnest($2::text[]) x)
+	`, regionID, names); err != nil {
+		return "", err
+	}
+
+	return "", nil
+}
+
+func CreateRegion(c *gin.Context) {
+	var req models.RegionRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Name == nil || strings.TrimSpace(*req.Name) == "" {
+		c.JSON(400, gin.H{"error": "name is required"})
+		return
+	}
+
+	aliases := []string{}
+	if req.Aliases != nil {
+		aliases = cleanAliases(*req.Aliases)
+	}
+
+	ctx := c.Request.Context()
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var id int64
+	err = tx.QueryRow(ctx, `
+		INSERT INTO regions (name, aliases)
+		VALUES ($1, $2)
+		ON CONFLICT (name) DO NOTHING
+		RETURNING id
+	`, strings.TrimSpace(*req.Name), aliases).Scan(&id)
+	if err == pgx.ErrNoRows {
+		c.JSON(409, gin.H{"error": "region already exists"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Provinces != nil {
+		msg, err := setRegionProvincesTx(ctx, tx, id, *req.Provinces)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		if msg != "" {
+			c.JSON(400, gin.H{"error": msg})
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	c.JSON(201, gin.H{"id": id})
+}
+
+func UpdateRegion(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid region id"})
+		return
+	}
+
+	var req models.RegionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "invalid body"})
+		return
+	}
+
+	var name *string
+	if req.Name != nil {
+		n := strings.TrimSpace(*req.Name)
+		if n == "" {
+			c.JSON(400, gin.H{"error": "name cannot be empty"})
+			return
+		}
+		name = &n
+	}
+
+	var aliases []string
+	if req.Aliases != nil {
+		aliases = cleanAliases(*req.Aliases)
+	}
+
+	ctx := c.Request.Context()
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE regions
+		SET name       = COALESCE($1, name),
+		    aliases    = COALESCE($2, aliases),
+		    updated_at = NOW()
+		WHERE id = $3
+	`, name, aliases, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			c.JSON(409, gin.H{"error": "region already exists"})
+			return
+		}
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if tag.RowsAffected() == 0 {
+		c.JSON(404, gin.H{"error": "region not found"})
+		return
+	}
+
+	if req.Provinces != nil {
+		msg, err := setRegionProvincesTx(ctx, tx, id, *req.Provinces)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		if msg != "" {
+			c.JSON(400, gin.H{"error": msg})
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "updated"})
+}
+
+func DeleteRegion(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid region id"})
+		return
+	}
+
+	var used int64
+	if err := database.Pool.QueryRow(c, `
+		SELECT COUNT(*) FROM customers cu
+		JOIN regions r ON r.name = cu.region
+		WHERE r.id = $1
+	`, id).Scan(&used); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if used > 0 {
+		c.JSON(409, gin.H{"error": "region is used by customers", "customer_count": used})
+		return
+	}
+
+	// provinsi otomatis lepas (ON DELETE SET NULL)
+	tag, err := database.Pool.Exec(c, `DELETE FROM regions WHERE id = $1`, id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if tag.RowsAffected() == 0 {
+		c.JSON(404, gin.H{"error": "region not found"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "deleted"})
+}
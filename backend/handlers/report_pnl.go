@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"sales-system-backend/database"
+	"sales-system-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// margin = revenue realization - budget spend
+func finalizePnLRow(r *models.PnLMonthRow) {
+	r.ContributionMargin = r.RevenueRealization - r.BudgetSpend
+	r.MarginPct = pct(r.ContributionMargin, r.RevenueRealization)
+}
+
+// ======================================================
+// P&L PER DIVISI (revenue realization vs budget spend)
+// GET /api/reports/pnl?division=&from=&to=
+// filter sama dengan dashboard (buildProjectDashboardFilter + buildBudgetDashboardFilter)
+// ======================================================
+
+func GetDivisionPnL(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	role := c.GetString("role")
+	userDiv := NormalizeDivision(c.GetString("division"))
+
+	projectWhere, projectArgs := buildProjectDashboardFilter(c, role, userDiv, true)
+	budgetWhere, budgetArgs, berr := buildBudgetDashboardFilter(c, role, userDiv)
+	if berr != nil {
+		c.JSON(400, gin.H{"error": berr.Error()})
+		return
+	}
+
+	months := map[string]*models.PnLMonthRow{}
+	rowFor := func(m string) *models.PnLMonthRow {
+		if months[m] == nil {
+			months[m] = &models.PnLMonthRow{Month: m}
+		}
+		return months[m]
+	}
+
+	// --- revenue (target baseline = Prospect + Carry Over, sama dengan forecast dashboard) ---
+	revQuery := fmt.Sprintf(`
+		SELECT
+			to_char(r.month, 'YYYY-MM') AS m,
+			COALESCE(SUM(
+				CASE WHEN p.status IN ('Prospect','Carry Over')
+				THEN COALESCE(r.target_revenue,0) ELSE 0 END
+			), 0) AS target,
+			COALESCE(SUM(COALESCE(r.target_realization,0)), 0) AS realization
+		FROM project_revenue_plan r
+		JOIN projects p ON p.id = r.project_id
+		LEFT JOIN customers c ON c.id = p.customer_id
+		WHERE %s
+		GROUP BY m
+	`, projectWhere)
+
+	rowsRev, err := database.Pool.Query(ctx, revQuery, projectArgs...)
+	if err != nil {
+		log.Println("PNL REVENUE ERROR:", err)
+		c.JSON(500, gin.H{"error": "failed to load revenue"})
+		return
+	}
+	defer rowsRev.Close()
+
+	for rowsRev.Next() {
+		var m string
+		var target, real float64
+		if err := rowsRev.Scan(&m, &target, &real); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		row := rowFor(m)
+		row.RevenueTarget = target
+		row.RevenueRealization = real
+	}
+	if err := rowsRev.Err(); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	// --- budget ---
+	budgetQuery := fmt.Sprintf(`
+		SELECT
+			to_char(b.month, 'YYYY-MM') AS m,
+			COALESCE(SUM(b.budget_amount), 0) AS budget,
+			COALESCE(SUM(r.total_realization), 0) AS spend
+		FROM budgets b
+		LEFT JOIN (
+			SELECT budget_id, SUM(amount) AS total_realization
+			FROM budget_realization
+			GROUP BY budget_id
+		) r ON r.budget_id = b.id
+		WHERE %s
+		GROUP BY m
+	`, budgetWhere)
+
+	rowsBudget, err := database.Pool.Query(ctx, budgetQuery, budgetArgs...)
+	if err != nil {
+		log.Println("PNL BUDGET ERROR:", err)
+		c.JSON(500, gin.H{"error": "failed to load budget"})
+		return
+	}
+	defer rowsBudget.Close()
+
+	for rowsBudget.Next() {
+		var m string
+		var budget, spend float64
+		if err := rowsBudget.Scan(&m, &budget, &spend); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		row := rowFor(m)
+		row.Budget = budget
+		row.BudgetSpend = spend
+	}
+	if err := rowsBudget.Err(); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	keys := make([]string, 0, len(months))
+	for k := range months {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	result := make([]models.PnLMonthRow, 0, len(keys))
+	total := models.PnLMonthRow{Month: "TOTAL"}
+	for _, k := range keys {
+		row := months[k]
+		finalizePnLRow(row)
+		result = append(result, *row)
+
+		total.RevenueTarget += row.RevenueTarget
+		total.RevenueRealization += row.RevenueRealization
+		total.Budget += row.Budget
+		total.BudgetSpend += row.BudgetSpend
+	}
+	finalizePnLRow(&total)
+
+	c.JSON(200, models.PnLResponse{
+		Months: result,
+		Total:  total,
+	})
+}
+
+// ======================================================
+// DRILL-DOWN 1 BULAN: project kontributor + line realisasi budget
+// GET /api/reports/pnl/:month   (month = YYYY-MM, filter lain tetap berlaku)
+// ======================================================
+
+func GetDivisionPnLDetail(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	month := c.Param("month")
+	if _, err := time.Parse("2006-01", month); err != nil {
+		c.JSON(400, gin.H{"error": "invalid month format, expected YYYY-MM"})
+		return
+	}
+
+	role := c.GetString("role")
+	userDiv := NormalizeDivision(c.GetString("division"))
+
+	// clone query dengan from/to dikunci ke bulan drill-down
+	mc := withMonthRange(c, month)
+
+	projectWhere, projectArgs := buildProjectDashboardFilter(mc, role, userDiv, true)
+	budgetWhere, budgetArgs, berr := buildBudgetDashboardFilter(mc, role, userDiv)
+	if berr != nil {
+		c.JSON(400, gin.H{"error": berr.Error()})
+		return
+	}
+
+	projQuery := fmt.Sprintf(`
+		SELECT
+			p.id,
+			p.project_code,
+			COALESCE(p.description,'-'),
+			COALESCE(c.name,'Unknown'),
+			p.division,
+			p.status,
+			COALESCE(SUM(COALESCE(r.target_revenue,0)),0),
+			COALESCE(SUM(COALESCE(r.target_realization,0)),0)
+		FROM projects p
+		JOIN project_revenue_plan r ON r.project_id = p.id
+		LEFT JOIN customers c ON c.id = p.customer_id
+		WHERE %s
+		GROUP BY p.id, p.project_code, p.description, c.name, p.division, p.status
+		HAVING SUM(COALESCE(r.target_revenue,0)) <> 0
+		    OR SUM(COALESCE(r.target_realization,0)) <> 0
+		ORDER BY 8 DESC, 7 DESC
+	`, projectWhere)
+
+	rowsProj, err := database.Pool.Query(ctx, projQuery, projectArgs...)
+	if err != nil {
+		log.Println("PNL DETAIL PROJECT ERROR:", err)
+		c.JSON(500, gin.H{"error": "failed to load projects"})
+		return
+	}
+	defer rowsProj.Close()
+
+	projects := []models.PnLProjectLine{}
+	for rowsProj.Next() {
+		var p models.PnLProjectLine
+		if err := rowsProj.Scan(
+			&p.ID, &p.ProjectCode, &p.Description, &p.Customer,
+			&p.Division, &p.Status, &p.TargetRevenue, &p.Realization,
+		); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		projects = append(projects, p)
+	}
+	if err := rowsProj.Err(); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	spendQuery := fmt.Sprintf(`
+		SELECT br.id, b.id, b.division, br.category, br.amount, COALESCE(br.note,'')
+		FROM budgets b
+		JOIN budget_realization br ON br.budget_id = b.id
+		WHERE %s
+		ORDER BY br.amount DESC
+	`, budgetWhere)
+
+	rowsSpend, err := database.Pool.Query(ctx, spendQuery, budgetArgs...)
+	if err != nil {
+		log.Println("PNL DETAIL SPEND ERROR:", err)
+		c.JSON(500, gin.H{"error": "failed to load budget realization"})
+		return
+	}
+	defer rowsSpend.Close()
+
+	spend := []models.PnLSpendLine{}
+	for rowsSpend.Next() {
+		var s models.PnLSpendLine
+		if err := rowsSpend.Scan(&s.ID, &s.BudgetID, &s.Division, &s.Category, &s.Amount, &s.Note); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		s.Division = NormalizeDivision(s.Division)
+		spend = append(spend, s)
+	}
+	if err := rowsSpend.Err(); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	row := models.PnLMonthRow{Month: month}
+	for _, p := range projects {
+		if p.Status == "Prospect" || p.Status == "Carry Over" {
+			row.RevenueTarget += p.TargetRevenue
+		}
+		row.RevenueRealization += p.Realization
+	}
+	for _, s := range spend {
+		row.BudgetSpend += s.Amount
+	}
+
+	// budget amount bulan itu (bisa ada budget tanpa realisasi)
+	budgetAmountQuery := fmt.Sprintf(`
+		SELECT COALESCE(SUM(b.budget_amount), 0) FROM budgets b WHERE %s
+	`, budgetWhere)
+	err = database.Pool.QueryRow(ctx, budgetAmountQuery, budgetArgs...).Scan(&row.Budget)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	finalizePnLRow(&row)
+
+	c.JSON(200, models.PnLDetailResponse{
+		Summary:           row,
+		Projects:          projects,
+		BudgetRealization: spend,
+	})
+}
+
+// withMonthRange meng-copy context dengan query from/to = month (YYYY-MM).
+func withMonthRange(c *gin.Context, month string) *gin.Context {
+	q := c.Request.URL.Query()
+	q.Set("from", month)
+	q.Set("to", month)
+
+	cloneReq := *c.Request
+	cloneURL := *c.Request.URL
+	cloneURL.RawQuery = q.Encode()
+	cloneReq.URL = &cloneURL
+
+	cloneCtx := c.Copy()
+	cloneCtx.Request = &cloneReq
+	return cloneCtx
+}
@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"sales-system-backend/database"
+	"sales-system-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+var sphReasonCategories = []string{"Administrasi", "Teknis", "Other"}
+
+// winLossAcc = akumulator bucket + total hari release → keputusan
+type winLossAcc struct {
+	models.WinLossBucket
+	daysTotal float64
+	daysCount int
+}
+
+func (a *winLossAcc) add(status string, value float64, days *float64) {
+	a.Decided++
+	switch status {
+	case "Win":
+		a.Win++
+		a.WinValue += value
+	case "Loss":
+		a.Loss++
+		a.LossValue += value
+	case "Drop":
+		a.Drop++
+		a.DropValue += value
+	}
+	if days != nil {
+		a.daysTotal += *days
+		a.daysCount++
+	}
+}
+
+func (a *winLossAcc) finalize() models.WinLossBucket {
+	b := a.WinLossBucket
+	b.WinRate = round2(pct(float64(b.Win), float64(b.Decided)))
+	b.WinValue = round2(b.WinValue)
+	b.LossValue = round2(b.LossValue)
+	b.DropValue = round2(b.DropValue)
+	if a.daysCount > 0 {
+		avg := round2(a.daysTotal / float64(a.daysCount))
+		b.AvgDaysToDecision = &avg
+	}
+	return b
+}
+
+type winLossGroup struct {
+	order []string
+	byKey map[string]*winLossAcc
+}
+
+func newWinLossGroup() *winLossGroup {
+	return &winLossGroup{byKey: map[string]*winLossAcc{}}
+}
+
+func (g *winLossGroup) get(key, label string) *winLossAcc {
+	if g.byKey[key] == nil {
+		g.byKey[key] = &winLossAcc{WinLossBucket: models.WinLossBucket{Key: key, Label: label}}
+		g.order = append(g.order, key)
+	}
+	return g.byKey[key]
+}
+
+func (g *winLossGroup) list() []models.WinLossBucket {
+	out := make([]models.WinLossBucket, 0, len(g.order))
+	for _, k := range g.order {
+		out = append(out, g.byKey[k].finalize())
+	}
+	return out
+}
+
+// project value untuk report keputusan = total target revenue project (alias p)
+const projectValueJoin = `
+	LEFT JOIN (
+		SELECT project_id, SUM(target_revenue) AS revenue
+		FROM project_revenue_plan
+		GROUP BY project_id
+	) r ON r.project_id = p.id`
+
+const projectValueExpr = `COALESCE(r.revenue, 0)::float8`
+
+// decisionFilter = project Open/Hold (tanpa periode) + keputusan Win/Loss/Drop
+// yang tanggalnya (sph_status_changed_at, Asia/Jakarta) masuk ?from=&to=
+type decisionFilter struct {
+	Cond string // predicate SQL (alias p)
+	From *string
+	To   *string
+}
+
+// parseDecisionFilter → argumen periode ditambahkan ke args mulai placeholder $i.
+// false = response 400 sudah ditulis.
+func parseDecisionFilter(c *gin.Context, args []any, i int) (decisionFilter, []any, bool) {
+	var f decisionFilter
+
+	decidedConds := []string{}
+	for _, q := range []struct {
+		key  string
+		cond string
+		dst  **string
+	}{
+		{"from", "(p.sph_status_changed_at AT TIME ZONE 'Asia/Jakarta')::date >= $%d::date", &f.From},
+		{"to", "(p.sph_status_changed_at AT TIME ZONE 'Asia/Jakarta')::date <= $%d::date", &f.To},
+	} {
+		v := strings.TrimSpace(c.Query(q.key))
+		if v == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", v); err != nil {
+			c.JSON(400, gin.H{"error": "invalid " + q.key + " (YYYY-MM-DD)"})
+			return f, args, false
+		}
+		decidedConds = append(decidedConds, fmt.Sprintf(q.cond, i))
+		args = append(args, v)
+		i++
+		val := v
+		*q.dst = &val
+	}
+
+	decidedFilter := "TRUE"
+	if len(decidedConds) > 0 {
+		decidedFilter = strings.Join(decidedConds, " AND ")
+	}
+
+	f.Cond = fmt.Sprintf(`(
+		p.sph_status IN ('Open', 'Hold')
+		OR (p.sph_status IN ('Win', 'Loss', 'Drop') AND p.sph_status_changed_at IS NOT NULL AND %s)
+	)`, decidedFilter)
+	return f, args, true
+}
+
+// ======================================================
+// WIN / LOSS — GET /api/reports/win-loss
+//   ?division=&from=YYYY-MM-DD&to=YYYY-MM-DD&period_type=month|quarter|year
+//   &customer_id=&project_type=
+// keputusan = sph_status Win/Loss/Drop, tanggal = sph_status_changed_at (Asia/Jakarta)
+// value = total target revenue project
+// ======================================================
+
+func GetWinLossReport(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	role := c.GetString("role")
+	userDiv := NormalizeDivision(c.GetString("division"))
+
+	periodType := strings.ToLower(strings.TrimSpace(c.DefaultQuery("period_type", "month")))
+	if _, ok := periodRank[periodType]; !ok {
+		c.JSON(400, gin.H{"error": "period_type must be month, quarter or year"})
+		return
+	}
+
+	resp := models.WinLossResponse{PeriodType: periodType}
+
+	conds := []string{"1=1"}
+	args := []any{}
+	i := 1
+
+	division := NormalizeDivision(strings.TrimSpace(c.Query("division")))
+	if role == "user" {
+		division = userDiv
+	}
+	if strings.ToUpper(division) == "ALL" {
+		division = ""
+	}
+	if division != "" {
+		conds = append(conds, fmt.Sprintf("p.division = $%d", i))
+		args = append(args, division)
+		i++
+	}
+	resp.Division = division
+
+	if v := strings.TrimSpace(c.Query("customer_id")); v != "" {
+		customerID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid customer_id"})
+			return
+		}
+		conds = append(conds, fmt.Sprintf("p.customer_id = $%d", i))
+		args = append(args, customerID)
+		i++
+	}
+
+	if v := strings.TrimSpace(c.Query("project_type")); v != "" && strings.ToUpper(v) != "ALL" {
+		conds = append(conds, fmt.Sprintf("p.project_type = $%d", i))
+		args = append(args, v)
+		i++
+	}
+
+	// periode berlaku untuk tanggal keputusan; Open/Hold dihitung apa adanya
+	decision, args, ok := parseDecisionFilter(c, args, i)
+	if !ok {
+		return
+	}
+	resp.From, resp.To = decision.From, decision.To
+
+	rows, err := database.Pool.Query(ctx, fmt.Sprintf(`
+		SELECT
+			p.division,
+			p.customer_id,
+			COALESCE(cu.name, ''),
+			p.project_type,
+			p.sph_status,
+			COALESCE(p.sph_status_reason_category, ''),
+			%s,
+			(p.sph_status_changed_at AT TIME ZONE 'Asia/Jakarta')::date,
+			CASE
+				WHEN p.sph_release_date IS NOT NULL AND p.sph_status_changed_at IS NOT NULL
+				     AND (p.sph_status_changed_at AT TIME ZONE 'Asia/Jakarta')::date >= p.sph_release_date::date
+				THEN ((p.sph_status_changed_at AT TIME ZONE 'Asia/Jakarta')::date - p.sph_release_date::date)::float8
+			END
+		FROM projects p
+		LEFT JOIN customers cu ON cu.id = p.customer_id
+		%s
+		WHERE %s AND %s
+	`, projectValueExpr, projectValueJoin, strings.Join(conds, " AND "), decision.Cond), args...)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	var summary winLossAcc
+	byDivision := newWinLossGroup()
+	byCustomer := newWinLossGroup()
+	byType := newWinLossGroup()
+	byPeriod := newWinLossGroup()
+
+	reasons := map[string]*models.WinLossReasonRow{}
+	reasonFor := func(cat string) *models.WinLossReasonRow {
+		if cat == "" {
+			cat = "Unspecified"
+		}
+		if reasons[cat] == nil {
+			reasons[cat] = &models.WinLossReasonRow{Category: cat}
+		}
+		return reasons[cat]
+	}
+
+	for rows.Next() {
+		var (
+			div, customerName, projectType, status, reason string
+			customerID                                     *int64
+			value                                          float64
+			decidedAt                                      *time.Time
+			days                                           *float64
+		)
+		if err := rows.Scan(&div, &customerID, &customerName, &projectType, &status,
+			&reason, &value, &decidedAt, &days); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		switch status {
+		case "Open":
+			resp.OpenCount++
+			continue
+		case "Hold":
+			resp.HoldCount++
+			continue
+		}
+
+		summary.add(status, value, days)
+		byDivision.get(NormalizeDivision(div), "").add(status, value, days)
+
+		customerKey, customerLabel := "none", "(no customer)"
+		if customerID != nil {
+			customerKey, customerLabel = strconv.FormatInt(*customerID, 10), customerName
+		}
+		byCustomer.get(customerKey, customerLabel).add(status, value, days)
+
+		byType.get(projectType, "").add(status, value, days)
+
+		if decidedAt != nil {
+			byPeriod.get(formatPeriod(periodType, *decidedAt), "").add(status, value, days)
+		}
+
+		if status == "Loss" || status == "Drop" {
+			r := reasonFor(reason)
+			if status == "Loss" {
+				r.Loss++
+				r.LossValue += value
+			} else {
+				r.Drop++
+				r.DropValue += value
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp.Summary = summary.finalize()
+	resp.Summary.Key = "all"
+
+	// reason: urutan kategori tetap, Unspecified (data lama) di akhir
+	resp.ByReason = []models.WinLossReasonRow{}
+	for _, cat := range append(append([]string{}, sphReasonCategories...), "Unspecified") {
+		if r := reasons[cat]; r != nil {
+			r.LossValue = round2(r.LossValue)
+			r.DropValue = round2(r.DropValue)
+			resp.ByReason = append(resp.ByReason, *r)
+		}
+	}
+
+	resp.ByDivision = byDivision.list()
+	sort.Slice(resp.ByDivision, func(a, b int) bool { return resp.ByDivision[a].Key < resp.ByDivision[b].Key })
+
+	resp.ByCustomer = byCustomer.list()
+	sort.Slice(resp.ByCustomer, func(a, b int) bool {
+		if resp.ByCustomer[a].Decided != resp.ByCustomer[b].Decided {
+			return resp.ByCustomer[a].Decided > resp.ByCustomer[b].Decided
+		}
+		return resp.ByCustomer[a].Label < resp.ByCustomer[b].Label
+	})
+
+	resp.ByProjectType = byType.list()
+	sort.Slice(resp.ByProjectType, func(a, b int) bool { return resp.ByProjectType[a].Key < resp.ByProjectType[b].Key })
+
+	resp.ByPeriod = byPeriod.list()
+	sort.Slice(resp.ByPeriod, func(a, b int) bool { return resp.ByPeriod[a].Key < resp.ByPeriod[b].Key })
+
+	c.JSON(200, resp)
+}
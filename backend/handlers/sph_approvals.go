@@ -0,0 +1,480 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"sales-system-backend/database"
+	"sales-system-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+var approvalLevelRank = map[string]int{"manager": 1, "director": 2}
+
+// SQL: rank level approver (alias a)
+const approverRankSQL = `(CASE a.level WHEN 'director' THEN 2 ELSE 1 END)`
+
+type requiredApproval struct {
+	Level  string
+	RuleID int64
+}
+
+// requiredSPHApprovalsTx → level yang dibutuhkan versi berdasarkan rule aktif divisi
+// (satu per level, rule dengan threshold tertinggi yang terlewati)
+func requiredSPHApprovalsTx(ctx context.Context, db dbtx, versionID int64, division string) ([]requiredApproval, float64, error) {
+	var maxDiscount float64
+	if err := db.QueryRow(ctx, `
+		SELECT COALESCE(MAX(discount_pct), 0)::float8 FROM sph_line_items WHERE version_id = $1
+	`, versionID).Scan(&maxDiscount); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := db.Query(ctx, `
+		SELECT DISTINCT ON (level) level, id
+		FROM discount_approval_rules
+		WHERE is_active
+		  AND (division IS NULL OR division = $1)
+		  AND $2::numeric > min_discount_pct
+		ORDER BY level, min_discount_pct DESC, id
+	`, division, maxDiscount)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	out := []requiredApproval{}
+	for rows.Next() {
+		var r requiredApproval
+		if err := rows.Scan(&r.Level, &r.RuleID); err != nil {
+			return nil, 0, err
+		}
+		out = append(out, r)
+	}
+	return out, maxDiscount, rows.Err()
+}
+
+// syncSPHApprovalsTx dipanggil setiap versi draft disimpan: approval lama batal
+// (isi berubah) dan request baru dibuat untuk setiap level yang dilanggar.
+func syncSPHApprovalsTx(ctx context.Context, tx pgx.Tx, projectID, versionID int64, division string, requestedBy int64) ([]string, error) {
+	if _, err := tx.Exec(ctx, `
+		UPDATE sph_approval_requests SET status = 'cancelled'
+		WHERE version_id = $1 AND status <> 'cancelled'
+	`, versionID); err != nil {
+		return nil, err
+	}
+
+	required, maxDiscount, err := requiredSPHApprovalsTx(ctx, tx, versionID, division)
+	if err != nil {
+		return nil, err
+	}
+	if len(required) == 0 {
+		return []string{}, nil
+	}
+
+	var projectCode string
+	var versionNo int
+	if err := tx.QueryRow(ctx, `
+		SELECT p.project_code, v.version_no
+		FROM sph_document_versions v
+		JOIN sph_documents d ON d.id = v.document_id
+		JOIN projects p ON p.id = d.project_id
+		WHERE v.id = $1
+	`, versionID).Scan(&projectCode, &versionNo); err != nil {
+		return nil, err
+	}
+
+	levels := []string{}
+	for _, r := range required {
+		var requestID int64
+		if err := tx.QueryRow(ctx, `
+			INSERT INTO sph_approval_requests (version_id, rule_id, level, discount_pct, requested_by)
+			VALUES ($1, $2, $3, $4, NULLIF($5::bigint, 0))
+			RETURNING id
+		`, versionID, r.RuleID, r.Level, maxDiscount, requestedBy).Scan(&requestID); err != nil {
+			return nil, err
+		}
+		levels = append(levels, r.Level)
+
+		// notif ke approver yang berwenang
+		rows, err := tx.Query(ctx, `
+			SELECT DISTINCT a.user_id
+			FROM approvers a
+			JOIN users u ON u.id = a.user_id
+			WHERE u.is_active
+			  AND (a.division IS NULL OR a.division = $1)
+			  AND `+approverRankSQL+` >= $2
+			  AND a.user_id <> $3
+		`, division, approvalLevelRank[r.Level], requestedBy)
+		if err != nil {
+			return nil, err
+		}
+		var approverIDs []int64
+		for rows.Next() {
+			var uid int64
+			if err := rows.Scan(&uid); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			approverIDs = append(approverIDs, uid)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+
+		for _, uid := range approverIDs {
+			if err := createNotification(ctx, tx, uid,
+				"sph_approval",
+				fmt.Sprintf("Discount approval needed: %s v%d", projectCode, versionNo),
+				fmt.Sprintf("SPH %s version %d has a %.2f%% discount and needs %s approval.",
+					projectCode, versionNo, maxDiscount, r.Level),
+				"sph_approval", requestID,
+			); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return levels, nil
+}
+
+// sphReleaseBlockerTx → "" jika versi boleh di-release; selain itu alasan blokir.
+// Rule dievaluasi ulang saat release (rule bisa berubah setelah versi disimpan).
+func sphReleaseBlockerTx(ctx context.Context, tx pgx.Tx, versionID int64, division string) (string, error) {
+	required, _, err := requiredSPHApprovalsTx(ctx, tx, versionID, division)
+	if err != nil {
+		return "", err
+	}
+
+	missing := []string{}
+	for _, r := range required {
+		var status string
+		err := tx.QueryRow(ctx, `
+			SELECT status FROM sph_approval_requests
+			WHERE version_id = $1 AND level = $2 AND status <> 'cancelled'
+		`, versionID, r.Level).Scan(&status)
+		if err == pgx.ErrNoRows {
+			status = "not requested"
+		} else if err != nil {
+			return "", err
+		}
+		if status != "approved" {
+			missing = append(missing, fmt.Sprintf("%s (%s)", r.Level, status))
+		}
+	}
+
+	if len(missing) == 0 {
+		return "", nil
+	}
+	return "discount approval required before release: " + strings.Join(missing, ", "), nil
+}
+
+const sphApprovalSelect = `
+	SELECT ar.id, ar.version_id, v.version_no, p.id, p.project_code, p.division,
+	       ar.rule_id, ar.level, ar.discount_pct::float8, ar.status,
+	       ar.requested_by, ru.username, ar.decided_by, du.username, ar.decided_at,
+	       ar.note, ar.created_at
+	FROM sph_approval_requests ar
+	JOIN sph_document_versions v ON v.id = ar.version_id
+	JOIN sph_documents d ON d.id = v.document_id
+	JOIN projects p ON p.id = d.project_id
+	LEFT JOIN users ru ON ru.id = ar.requested_by
+	LEFT JOIN users du ON du.id = ar.decided_by`
+
+func scanSPHApprovals(rows pgx.Rows) ([]models.SPHApprovalRequest, error) {
+	defer rows.Close()
+
+	list := []models.SPHApprovalRequest{}
+	for rows.Next() {
+		var a models.SPHApprovalRequest
+		if err := rows.Scan(
+			&a.ID, &a.VersionID, &a.VersionNo, &a.ProjectID, &a.ProjectCode, &a.Division,
+			&a.RuleID, &a.Level, &a.DiscountPct, &a.Status,
+			&a.RequestedBy, &a.RequestedName, &a.DecidedBy, &a.DecidedName, &a.DecidedAt,
+			&a.Note, &a.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		a.Division = NormalizeDivision(a.Division)
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
+
+// ======================================================
+// GET /approvals?status=pending&scope=mine|requested|all
+// mine = request yang bisa diputuskan user; all = admin
+// ======================================================
+
+func ListSPHApprovals(c *gin.Context) {
+	userID := c.GetInt64("user_id")
+
+	conds := []string{"1=1"}
+	args := []any{}
+	i := 1
+
+	status := strings.TrimSpace(c.DefaultQuery("status", "pending"))
+	if status != "" && strings.ToUpper(status) != "ALL" {
+		conds = append(conds, fmt.Sprintf("ar.status = $%d", i))
+		args = append(args, status)
+		i++
+	}
+
+	scope := strings.TrimSpace(c.DefaultQuery("scope", "mine"))
+	if scope == "all" && c.GetString("role") != "admin" {
+		scope = "mine"
+	}
+
+	switch scope {
+	case "requested":
+		conds = append(conds, fmt.Sprintf("ar.requested_by = $%d", i))
+		args = append(args, userID)
+		i++
+	case "all":
+		// admin: tanpa filter
+	default:
+		conds = append(conds, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM approvers a
+			WHERE a.user_id = $%d
+			  AND (a.division IS NULL OR a.division = p.division)
+			  AND %s >= (CASE ar.level WHEN 'director' THEN 2 ELSE 1 END)
+		)`, i, approverRankSQL))
+		args = append(args, userID)
+		i++
+	}
+
+	rows, err := database.Pool.Query(c, fmt.Sprintf(`%s
+		WHERE %s
+		ORDER BY ar.created_at DESC, ar.id DESC
+	`, sphApprovalSelect, strings.Join(conds, " AND ")), args...)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	list, err := scanSPHApprovals(rows)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, list)
+}
+
+// ======================================================
+// GET /projects/:id/sph/versions/:versionId/approvals
+// ======================================================
+
+func ListSPHVersionApprovals(c *gin.Context) {
+	projectID, versionID, ok := parseSPHVersionParams(c)
+	if !ok {
+		return
+	}
+	if _, ok := checkProjectAccess(c, projectID); !ok {
+		return
+	}
+
+	rows, err := database.Pool.Query(c, sphApprovalSelect+`
+		WHERE ar.version_id = $1 AND p.id = $2
+		ORDER BY ar.created_at DESC, ar.id DESC
+	`, versionID, projectID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	list, err := scanSPHApprovals(rows)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, list)
+}
+
+// ======================================================
+// POST /projects/:id/sph/versions/:versionId/approvals → raise ulang request
+// (mis. setelah rule berubah atau request ditolak)
+// ======================================================
+
+func RequestSPHApproval(c *gin.Context) {
+	projectID, versionID, ok := parseSPHVersionParams(c)
+	if !ok {
+		return
+	}
+	project, ok := checkProjectAccess(c, projectID)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	err = tx.QueryRow(ctx, `
+		SELECT v.status
+		FROM sph_document_versions v
+		JOIN sph_documents d ON d.id = v.document_id
+		WHERE v.id = $1 AND d.project_id = $2
+		FOR UPDATE OF v
+	`, versionID, projectID).Scan(&status)
+	if err == pgx.ErrNoRows {
+		c.JSON(404, gin.H{"error": "SPH version not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if status != "draft" {
+		c.JSON(409, gin.H{"error": "approvals can only be requested for draft versions"})
+		return
+	}
+
+	levels, err := syncSPHApprovalsTx(ctx, tx, projectID, versionID, project.Division, c.GetInt64("user_id"))
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	c.JSON(200, gin.H{"required_levels": levels})
+}
+
+// ======================================================
+// POST /approvals/:id/approve | /approvals/:id/reject {note}
+// ======================================================
+
+func ApproveSPHApproval(c *gin.Context) {
+	decideSPHApproval(c, "approved")
+}
+
+func RejectSPHApproval(c *gin.Context) {
+	decideSPHApproval(c, "rejected")
+}
+
+func decideSPHApproval(c *gin.Context, decision string) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid approval id"})
+		return
+	}
+
+	var req models.DecideApprovalRequest
+	_ = c.ShouldBindJSON(&req)
+	req.Note = strings.TrimSpace(req.Note)
+	if decision == "rejected" && req.Note == "" {
+		c.JSON(400, gin.H{"error": "note is required when rejecting"})
+		return
+	}
+
+	userID := c.GetInt64("user_id")
+	ctx := c.Request.Context()
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var (
+		level, status, versionStatus, division, projectCode string
+		requestedBy                                         *int64
+		versionID, projectID                                int64
+		versionNo                                           int
+	)
+	err = tx.QueryRow(ctx, `
+		SELECT ar.level, ar.status, ar.requested_by, ar.version_id,
+		       v.status, v.version_no, p.id, p.division, p.project_code
+		FROM sph_approval_requests ar
+		JOIN sph_document_versions v ON v.id = ar.version_id
+		JOIN sph_documents d ON d.id = v.document_id
+		JOIN projects p ON p.id = d.project_id
+		WHERE ar.id = $1
+		FOR UPDATE OF ar
+	`, id).Scan(&level, &status, &requestedBy, &versionID,
+		&versionStatus, &versionNo, &projectID, &division, &projectCode)
+	if err == pgx.ErrNoRows {
+		c.JSON(404, gin.H{"error": "approval request not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	division = NormalizeDivision(division)
+
+	if status != "pending" {
+		c.JSON(409, gin.H{"error": "approval request is already " + status})
+		return
+	}
+	if versionStatus != "draft" {
+		c.JSON(409, gin.H{"error": "SPH version is no longer a draft"})
+		return
+	}
+	if requestedBy != nil && *requestedBy == userID {
+		c.JSON(403, gin.H{"error": "cannot decide your own approval request"})
+		return
+	}
+
+	var allowed bool
+	if err := tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM approvers a
+			WHERE a.user_id = $1
+			  AND (a.division IS NULL OR a.division = $2)
+			  AND `+approverRankSQL+` >= $3
+		)
+	`, userID, division, approvalLevelRank[level]).Scan(&allowed); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if !allowed {
+		c.JSON(403, gin.H{"error": "forbidden: not an approver for this level / division"})
+		return
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE sph_approval_requests
+		SET status = $2, decided_by = $3, decided_at = NOW(), note = $4
+		WHERE id = $1
+	`, id, decision, userID, req.Note); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	// notif ke pembuat request
+	if requestedBy != nil {
+		title := fmt.Sprintf("Discount %s %s: %s v%d", level, decision, projectCode, versionNo)
+		message := fmt.Sprintf("The %s approval for SPH %s version %d was %s.", level, projectCode, versionNo, decision)
+		if req.Note != "" {
+			message += " Note: " + req.Note
+		}
+		if err := createNotification(ctx, tx, *requestedBy, "sph_approval", title, message, "project", projectID); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": decision})
+}
@@ -0,0 +1,764 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"sales-system-backend/database"
+	"sales-system-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// ======================================================
+// SPH HELPERS
+// ======================================================
+
+// computeSPHLine → validasi + hitung nominal baris (diskon dulu, pajak dari nilai setelah diskon)
+func computeSPHLine(req models.SPHLineItemRequest, lineNo int) (models.SPHLineItem, string) {
+	it := models.SPHLineItem{
+		LineNo:      lineNo,
+		ProductID:   req.ProductID,
+		ProductName: strings.TrimSpace(req.ProductName),
+		Description: strings.TrimSpace(req.Description),
+		Qty:         req.Qty,
+		Unit:        strings.TrimSpace(req.Unit),
+		UnitPrice:   req.UnitPrice,
+		DiscountPct: req.DiscountPct,
+		TaxPct:      req.TaxPct,
+	}
+
+	if it.ProductName == "" {
+		return it, fmt.Sprintf("item %d: product_name is required", lineNo)
+	}
+	if it.Qty <= 0 {
+		return it, fmt.Sprintf("item %d: qty must be > 0", lineNo)
+	}
+	if it.UnitPrice < 0 {
+		return it, fmt.Sprintf("item %d: unit_price must be >= 0", lineNo)
+	}
+	if it.DiscountPct < 0 || it.DiscountPct > 100 || it.TaxPct < 0 || it.TaxPct > 100 {
+		return it, fmt.Sprintf("item %d: discount_pct / tax_pct must be between 0 and 100", lineNo)
+	}
+	if it.Unit == "" {
+		it.Unit = "unit"
+	}
+
+	it.GrossAmount = round2(it.Qty * it.UnitPrice)
+	it.DiscountAmount = round2(it.GrossAmount * it.DiscountPct / 100)
+	it.TaxAmount = round2((it.GrossAmount - it.DiscountAmount) * it.TaxPct / 100)
+	it.LineTotal = round2(it.GrossAmount - it.DiscountAmount + it.TaxAmount)
+
+	return it, ""
+}
+
+// replaceSPHItemsTx → tulis ulang items versi + update total versi
+func replaceSPHItemsTx(ctx context.Context, tx pgx.Tx, versionID int64, items []models.SPHLineItem) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM sph_line_items WHERE version_id = $1`, versionID); err != nil {
+		return err
+	}
+
+	var subtotal, discount, tax, grand float64
+	for _, it := range items {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO sph_line_items
+				(version_id, line_no, product_id, product_name, description, qty, unit, unit_price,
+				 discount_pct, tax_pct, gross_amount, discount_amount, tax_amount, line_total)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		`, versionID, it.LineNo, it.ProductID, it.ProductName, it.Description, it.Qty, it.Unit, it.UnitPrice,
+			it.DiscountPct, it.TaxPct, it.GrossAmount, it.DiscountAmount, it.TaxAmount, it.LineTotal,
+		); err != nil {
+			return err
+		}
+		subtotal += it.GrossAmount
+		discount += it.DiscountAmount
+		tax += it.TaxAmount
+		grand += it.LineTotal
+	}
+
+	_, err := tx.Exec(ctx, `
+		UPDATE sph_document_versions
+		SET subtotal = $1, discount_total = $2, tax_total = $3, grand_total = $4, updated_at = NOW()
+		WHERE id = $5
+	`, round2(subtotal), round2(discount), round2(tax), round2(grand), versionID)
+	return err
+}
+
+func loadSPHItems(ctx context.Context, db dbtx, versionID int64) ([]models.SPHLineItem, error) {
+	rows, err := db.Query(ctx, `
+		SELECT line_no, product_id, product_name, description, qty::float8, unit, unit_price::float8,
+		       discount_pct::float8, tax_pct::float8,
+		       gross_amount::float8, discount_amount::float8, tax_amount::float8, line_total::float8
+		FROM sph_line_items
+		WHERE version_id = $1
+		ORDER BY line_no ASC
+	`, versionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.SPHLineItem{}
+	for rows.Next() {
+		var it models.SPHLineItem
+		if err := rows.Scan(
+			&it.LineNo, &it.ProductID, &it.ProductName, &it.Description, &it.Qty, &it.Unit, &it.UnitPrice,
+			&it.DiscountPct, &it.TaxPct,
+			&it.GrossAmount, &it.DiscountAmount, &it.TaxAmount, &it.LineTotal,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
+
+const sphVersionColumns = `
+	v.id, v.document_id, v.version_no, v.status, to_char(v.valid_until, 'YYYY-MM-DD'),
+	v.notes, v.terms,
+	v.subtotal::float8, v.discount_total::float8, v.tax_total::float8, v.grand_total::float8,
+	v.sph_number,
+	COALESCE((SELECT MAX(li.discount_pct) FROM sph_line_items li WHERE li.version_id = v.id), 0)::float8,
+	COALESCE((
+		SELECT CASE
+			WHEN bool_or(ar.status = 'rejected') THEN 'rejected'
+			WHEN bool_or(ar.status = 'pending') THEN 'pending'
+			ELSE 'approved' END
+		FROM sph_approval_requests ar
+		WHERE ar.version_id = v.id AND ar.status <> 'cancelled'
+	), 'not_required'),
+	v.released_at, v.created_at, v.updated_at`
+
+func scanSPHVersion(row pgx.Row, v *models.SPHVersion) error {
+	return row.Scan(
+		&v.ID, &v.DocumentID, &v.VersionNo, &v.Status, &v.ValidUntil,
+		&v.Notes, &v.Terms,
+		&v.Subtotal, &v.DiscountTotal, &v.TaxTotal, &v.GrandTotal,
+		&v.SPHNumber, &v.MaxDiscount, &v.Approval,
+		&v.ReleasedAt, &v.CreatedAt, &v.UpdatedAt,
+	)
+}
+
+// loadSPHVersion → versi milik project (versi project lain = not found)
+func loadSPHVersion(ctx context.Context, db dbtx, projectID, versionID int64) (models.SPHVersion, error) {
+	var v models.SPHVersion
+	err := scanSPHVersion(db.QueryRow(ctx, `
+		SELECT `+sphVersionColumns+`
+		FROM sph_document_versions v
+		JOIN sph_documents d ON d.id = v.document_id
+		WHERE v.id = $1 AND d.project_id = $2
+	`, versionID, projectID), &v)
+	if err != nil {
+		return v, err
+	}
+
+	v.Items, err = loadSPHItems(ctx, db, versionID)
+	return v, err
+}
+
+// releaseSPHVersionTx → versi jadi released (yang lama superseded) dan
+// projects.sph_number / sph_release_date / sph_release_status ikut di-set
+func releaseSPHVersionTx(ctx context.Context, tx pgx.Tx, projectID, versionID int64, sphNumber string, releaseDate time.Time, userID int64) error {
+	if _, err := tx.Exec(ctx, `
+		UPDATE sph_document_versions v
+		SET status = 'superseded', updated_at = NOW()
+		FROM sph_documents d
+		WHERE d.id = v.document_id AND d.project_id = $1 AND v.status = 'released'
+	`, projectID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE sph_document_versions
+		SET status = 'released', sph_number = $2, released_at = NOW(),
+		    released_by = NULLIF($3::bigint, 0), updated_at = NOW()
+		WHERE id = $1
+	`, versionID, sphNumber, userID); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(ctx, `
+		UPDATE projects
+		SET sph_number = $2, sph_release_date = $3, sph_release_status = 'Yes', updated_at = NOW()
+		WHERE id = $1
+	`, projectID, sphNumber, releaseDate)
+	return err
+}
+
+func parseSPHVersionParams(c *gin.Context) (int64, int64, bool) {
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid project id"})
+		return 0, 0, false
+	}
+	versionID, err := strconv.ParseInt(c.Param("versionId"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid version id"})
+		return 0, 0, false
+	}
+	return projectID, versionID, true
+}
+
+// fillSPHItemProduct → item dengan product_id harus produk katalog aktif;
+// product_name / unit kosong diisi dari katalog. "" = valid
+func fillSPHItemProduct(ctx context.Context, db dbtx, req *models.SPHLineItemRequest, lineNo int) (string, error) {
+	if req.ProductID == nil {
+		return "", nil
+	}
+	var name, unit string
+	var active bool
+	err := db.QueryRow(ctx,
+		`SELECT name, unit, is_active FROM products WHERE id = $1`, *req.ProductID,
+	).Scan(&name, &unit, &active)
+	if err == pgx.ErrNoRows {
+		return fmt.Sprintf("item %d: product not found", lineNo), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if !active {
+		return fmt.Sprintf("item %d: product is inactive", lineNo), nil
+	}
+	if strings.TrimSpace(req.ProductName) == "" {
+		req.ProductName = name
+	}
+	if strings.TrimSpace(req.Unit) == "" {
+		req.Unit = unit
+	}
+	return "", nil
+}
+
+func buildSPHItems(c *gin.Context, reqs []models.SPHLineItemRequest) ([]models.SPHLineItem, bool) {
+	items := make([]models.SPHLineItem, 0, len(reqs))
+	for i, r := range reqs {
+		msg, err := fillSPHItemProduct(c, database.Pool, &r, i+1)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		if msg != "" {
+			c.JSON(400, gin.H{"error": msg})
+			return nil, false
+		}
+
+		it, msg := computeSPHLine(r, i+1)
+		if msg != "" {
+			c.JSON(400, gin.H{"error": msg})
+			return nil, false
+		}
+		items = append(items, it)
+	}
+	return items, true
+}
+
+func validateSPHValidUntil(c *gin.Context, s *string) bool {
+	if s == nil || *s == "" {
+		return true
+	}
+	if _, err := time.Parse("2006-01-02", *s); err != nil {
+		c.JSON(400, gin.H{"error": "invalid valid_until (YYYY-MM-DD)"})
+		return false
+	}
+	return true
+}
+
+// ======================================================
+// GET /projects/:id/sph → dokumen + daftar versi (tanpa items)
+// ======================================================
+
+func GetProjectSPH(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid project id"})
+		return
+	}
+	if _, ok := checkProjectAccess(c, projectID); !ok {
+		return
+	}
+
+	var d models.SPHDocument
+	err = database.Pool.QueryRow(c, `
+		SELECT id, project_id, title, currency, created_at, updated_at
+		FROM sph_documents WHERE project_id = $1
+	`, projectID).Scan(&d.ID, &d.ProjectID, &d.Title, &d.Currency, &d.CreatedAt, &d.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		c.JSON(404, gin.H{"error": "project has no SPH document yet"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, err := database.Pool.Query(c, `
+		SELECT `+sphVersionColumns+`
+		FROM sph_document_versions v
+		WHERE v.document_id = $1
+		ORDER BY v.version_no DESC
+	`, d.ID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	d.Versions = []models.SPHVersion{}
+	for rows.Next() {
+		var v models.SPHVersion
+		if err := scanSPHVersion(rows, &v); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		d.Versions = append(d.Versions, v)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, d)
+}
+
+// ======================================================
+// POST /projects/:id/sph/versions → versi draft baru
+// dokumen dibuat otomatis saat versi pertama
+// ======================================================
+
+func CreateSPHVersion(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid project id"})
+		return
+	}
+	project, ok := checkProjectAccess(c, projectID)
+	if !ok {
+		return
+	}
+
+	var req models.SPHVersionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "invalid body"})
+		return
+	}
+	if !validateSPHValidUntil(c, req.ValidUntil) {
+		return
+	}
+
+	if req.CopyFromVersionID != nil && req.FromLineItems {
+		c.JSON(400, gin.H{"error": "use either copy_from_version_id or from_line_items"})
+		return
+	}
+
+	items, ok := buildSPHItems(c, req.Items)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID := c.GetInt64("user_id")
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var title string
+	if req.Title != nil {
+		title = strings.TrimSpace(*req.Title)
+	}
+
+	var docID int64
+	err = tx.QueryRow(ctx, `
+		INSERT INTO sph_documents (project_id, title, created_by)
+		VALUES ($1, $2, NULLIF($3::bigint, 0))
+		ON CONFLICT (project_id) DO UPDATE
+			SET title = CASE WHEN $4 THEN EXCLUDED.title ELSE sph_documents.title END,
+			    updated_at = NOW()
+		RETURNING id
+	`, projectID, title, userID, req.Title != nil).Scan(&docID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	// salin items dari versi sebelumnya
+	if len(items) == 0 && req.CopyFromVersionID != nil {
+		src, err := loadSPHVersion(ctx, tx, projectID, *req.CopyFromVersionID)
+		if err == pgx.ErrNoRows {
+			c.JSON(404, gin.H{"error": "copy_from_version_id not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		items = src.Items
+		if req.Notes == "" {
+			req.Notes = src.Notes
+		}
+		if req.Terms == "" {
+			req.Terms = src.Terms
+		}
+	}
+
+	// items dari project line items (harga katalog / price book); diskonnya
+	// ikut dievaluasi discount rules lewat syncSPHApprovalsTx di bawah
+	if len(items) == 0 && req.FromLineItems {
+		lineItems, err := loadProjectLineItems(ctx, tx, projectID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		if len(lineItems) == 0 {
+			c.JSON(400, gin.H{"error": "project has no line items"})
+			return
+		}
+		for i, li := range lineItems {
+			productID := li.ProductID
+			it, msg := computeSPHLine(models.SPHLineItemRequest{
+				ProductID:   &productID,
+				ProductName: li.ProductName,
+				Description: li.Notes,
+				Qty:         li.Qty,
+				Unit:        li.Unit,
+				UnitPrice:   li.UnitPrice,
+				DiscountPct: li.DiscountPct,
+			}, i+1)
+			if msg != "" {
+				c.JSON(400, gin.H{"error": msg})
+				return
+			}
+			items = append(items, it)
+		}
+	}
+
+	var versionID int64
+	err = tx.QueryRow(ctx, `
+		INSERT INTO sph_document_versions (document_id, version_no, valid_until, notes, terms, created_by)
+		SELECT $1, COALESCE(MAX(version_no), 0) + 1, NULLIF($2, '')::date, $3, $4, NULLIF($5::bigint, 0)
+		FROM sph_document_versions WHERE document_id = $1
+		RETURNING id
+	`, docID, req.ValidUntil, req.Notes, req.Terms, userID).Scan(&versionID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := replaceSPHItemsTx(ctx, tx, versionID, items); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	// diskon melewati rule → approval request baru
+	if _, err := syncSPHApprovalsTx(ctx, tx, projectID, versionID, project.Division, c.GetInt64("user_id")); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	v, err := loadSPHVersion(ctx, tx, projectID, versionID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	c.JSON(201, v)
+}
+
+// ======================================================
+// GET /projects/:id/sph/versions/:versionId (+ items)
+// ======================================================
+
+func GetSPHVersion(c *gin.Context) {
+	projectID, versionID, ok := parseSPHVersionParams(c)
+	if !ok {
+		return
+	}
+	if _, ok := checkProjectAccess(c, projectID); !ok {
+		return
+	}
+
+	v, err := loadSPHVersion(c, database.Pool, projectID, versionID)
+	if err == pgx.ErrNoRows {
+		c.JSON(404, gin.H{"error": "SPH version not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, v)
+}
+
+// ======================================================
+// PUT /projects/:id/sph/versions/:versionId → hanya draft
+// ======================================================
+
+func UpdateSPHVersion(c *gin.Context) {
+	projectID, versionID, ok := parseSPHVersionParams(c)
+	if !ok {
+		return
+	}
+	project, ok := checkProjectAccess(c, projectID)
+	if !ok {
+		return
+	}
+
+	var req models.SPHVersionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "invalid body"})
+		return
+	}
+	if !validateSPHValidUntil(c, req.ValidUntil) {
+		return
+	}
+
+	items, ok := buildSPHItems(c, req.Items)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	var docID int64
+	err = tx.QueryRow(ctx, `
+		SELECT v.status, v.document_id
+		FROM sph_document_versions v
+		JOIN sph_documents d ON d.id = v.document_id
+		WHERE v.id = $1 AND d.project_id = $2
+		FOR UPDATE OF v
+	`, versionID, projectID).Scan(&status, &docID)
+	if err == pgx.ErrNoRows {
+		c.JSON(404, gin.H{"error": "SPH version not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if status != "draft" {
+		c.JSON(409, gin.H{"error": "only draft versions can be edited; create a new version instead"})
+		return
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE sph_document_versions
+		SET valid_until = NULLIF($2, '')::date, notes = $3, terms = $4, updated_at = NOW()
+		WHERE id = $1
+	`, versionID, req.ValidUntil, req.Notes, req.Terms); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Title != nil {
+		if _, err := tx.Exec(ctx,
+			`UPDATE sph_documents SET title = $2, updated_at = NOW() WHERE id = $1`,
+			docID, strings.TrimSpace(*req.Title),
+		); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := replaceSPHItemsTx(ctx, tx, versionID, items); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	// diskon melewati rule → approval request baru
+	if _, err := syncSPHApprovalsTx(ctx, tx, projectID, versionID, project.Division, c.GetInt64("user_id")); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	v, err := loadSPHVersion(ctx, tx, projectID, versionID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	c.JSON(200, v)
+}
+
+// ======================================================
+// DELETE /projects/:id/sph/versions/:versionId → hanya draft
+// ======================================================
+
+func DeleteSPHVersion(c *gin.Context) {
+	projectID, versionID, ok := parseSPHVersionParams(c)
+	if !ok {
+		return
+	}
+	if _, ok := checkProjectAccess(c, projectID); !ok {
+		return
+	}
+
+	var status string
+	err := database.Pool.QueryRow(c, `
+		SELECT v.status
+		FROM sph_document_versions v
+		JOIN sph_documents d ON d.id = v.document_id
+		WHERE v.id = $1 AND d.project_id = $2
+	`, versionID, projectID).Scan(&status)
+	if err == pgx.ErrNoRows {
+		c.JSON(404, gin.H{"error": "SPH version not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if status != "draft" {
+		c.JSON(409, gin.H{"error": "only draft versions can be deleted"})
+		return
+	}
+
+	if _, err := database.Pool.Exec(c,
+		`DELETE FROM sph_document_versions WHERE id = $1 AND status = 'draft'`, versionID,
+	); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "deleted"})
+}
+
+// ======================================================
+// POST /projects/:id/sph/versions/:versionId/release
+// {sph_number?, release_date?}; tanpa sph_number → nomor project / generate
+// ======================================================
+
+func ReleaseSPHVersion(c *gin.Context) {
+	projectID, versionID, ok := parseSPHVersionParams(c)
+	if !ok {
+		return
+	}
+	project, ok := checkProjectAccess(c, projectID)
+	if !ok {
+		return
+	}
+
+	var req models.ReleaseSPHRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(400, gin.H{"error": "invalid body"})
+		return
+	}
+
+	releaseDate := jakartaToday()
+	if req.ReleaseDate != nil && *req.ReleaseDate != "" {
+		t, err := time.Parse("2006-01-02", *req.ReleaseDate)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid release_date (YYYY-MM-DD)"})
+			return
+		}
+		releaseDate = t
+	}
+
+	ctx := c.Request.Context()
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	var itemCount int
+	err = tx.QueryRow(ctx, `
+		SELECT v.status, (SELECT COUNT(*) FROM sph_line_items li WHERE li.version_id = v.id)
+		FROM sph_document_versions v
+		JOIN sph_documents d ON d.id = v.document_id
+		WHERE v.id = $1 AND d.project_id = $2
+		FOR UPDATE OF v
+	`, versionID, projectID).Scan(&status, &itemCount)
+	if err == pgx.ErrNoRows {
+		c.JSON(404, gin.H{"error": "SPH version not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if status != "draft" {
+		c.JSON(409, gin.H{"error": "only draft versions can be released"})
+		return
+	}
+	if itemCount == 0 {
+		c.JSON(400, gin.H{"error": "SPH version has no line items"})
+		return
+	}
+
+	blocker, err := sphReleaseBlockerTx(ctx, tx, versionID, project.Division)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if blocker != "" {
+		c.JSON(409, gin.H{"error": blocker})
+		return
+	}
+
+	var existing *string
+	if err := tx.QueryRow(ctx,
+		`SELECT sph_number FROM projects WHERE id = $1 FOR UPDATE`, projectID,
+	).Scan(&existing); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	// tanpa sph_number → nomor project yang ada, kalau belum ada digenerate
+	number, conflict, err := resolveSPHNumberTx(ctx, tx, projectID, project.Division,
+		req.SPHNumber, existing, "Yes", releaseDate)
+	if err != nil {
+		c.JSON(500, gin.H{"error": fmt.Sprintf("failed generate sph number: %v", err)})
+		return
+	}
+	if conflict != "" {
+		c.JSON(409, gin.H{"error": conflict})
+		return
+	}
+	sphNumber := *number
+
+	if err := releaseSPHVersionTx(ctx, tx, projectID, versionID, sphNumber, releaseDate, c.GetInt64("user_id")); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"status":           "released",
+		"sph_number":       sphNumber,
+		"sph_release_date": releaseDate.Format("2006-01-02"),
+	})
+}
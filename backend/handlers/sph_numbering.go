@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"sales-system-backend/database"
+	"sales-system-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// projectHasSPHVersions → project dengan dokumen SPH wajib release lewat
+// /sph/versions/:versionId/release supaya approval diskon tidak terlewati
+func projectHasSPHVersions(ctx context.Context, db dbtx, projectID int64) (bool, error) {
+	var has bool
+	err := db.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM sph_document_versions v
+			JOIN sph_documents d ON d.id = v.document_id
+			WHERE d.project_id = $1
+		)
+	`, projectID).Scan(&has)
+	return has, err
+}
+
+// ======================================================
+// RELEASE SPH (tanpa dokumen) — POST /projects/:id/sph/release
+// {sph_number?, release_date?} → nomor di-issue kalau belum ada,
+// sph_release_status = Yes
+// ======================================================
+
+func ReleaseProjectSPH(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid project id"})
+		return
+	}
+
+	project, ok := checkProjectAccess(c, projectID)
+	if !ok {
+		return
+	}
+
+	var req models.ReleaseSPHRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(400, gin.H{"error": "invalid body"})
+		return
+	}
+
+	releaseDate := jakartaToday()
+	if req.ReleaseDate != nil && *req.ReleaseDate != "" {
+		t, err := time.Parse("2006-01-02", *req.ReleaseDate)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid release_date (YYYY-MM-DD)"})
+			return
+		}
+		releaseDate = t
+	}
+
+	ctx := c.Request.Context()
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "db transaction error"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var existing *string
+	if err := tx.QueryRow(ctx,
+		`SELECT sph_number FROM projects WHERE id = $1 FOR UPDATE`, projectID,
+	).Scan(&existing); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	// project dengan dokumen SPH → release lewat versi (cek approval diskon)
+	hasDocument, err := projectHasSPHVersions(ctx, tx, projectID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if hasDocument {
+		c.JSON(409, gin.H{"error": "project has SPH document versions; release a version instead"})
+		return
+	}
+
+	number, conflict, err := resolveSPHNumberTx(ctx, tx, projectID, project.Division,
+		req.SPHNumber, existing, "Yes", releaseDate)
+	if err != nil {
+		c.JSON(500, gin.H{"error": fmt.Sprintf("failed generate sph number: %v", err)})
+		return
+	}
+	if conflict != "" {
+		c.JSON(409, gin.H{"error": conflict})
+		return
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE projects
+		SET sph_number = $2, sph_release_date = $3, sph_release_status = 'Yes', updated_at = NOW()
+		WHERE id = $1
+	`, projectID, *number, releaseDate); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"status":           "released",
+		"sph_number":       *number,
+		"sph_release_date": releaseDate.Format("2006-01-02"),
+	})
+}
+
+// ======================================================
+// SPH NUMBER FORMAT — GET /sph-number-formats
+// ======================================================
+
+func ListSPHNumberFormats(c *gin.Context) {
+	now := jakartaToday()
+
+	rows, err := database.Pool.Query(c, `
+		SELECT division, format, seq_padding, updated_at
+		FROM sph_number_formats
+		ORDER BY division
+	`)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	list := []models.SPHNumberFormat{}
+	for rows.Next() {
+		var f models.SPHNumberFormat
+		if err := rows.Scan(&f.Division, &f.Format, &f.SeqPadding, &f.UpdatedAt); err != nil {
+			rows.Close()
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		list = append(list, f)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	// preview = nomor berikutnya tahun ini
+	for i := range list {
+		var lastSeq int
+		if err := database.Pool.QueryRow(c, `
+			SELECT COALESCE(MAX(last_seq), 0) FROM sph_number_counters
+			WHERE year = $1 AND division_code = $2
+		`, now.Year(), divisionCode(list[i].Division)).Scan(&lastSeq); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		list[i].Preview = formatSPHNumber(list[i].Format, list[i].SeqPadding, list[i].Division, lastSeq+1, now)
+	}
+
+	c.JSON(200, list)
+}
+
+// ======================================================
+// SET FORMAT (ADMIN) — PUT /sph-number-formats/:division
+// ======================================================
+
+func SetSPHNumberFormat(c *gin.Context) {
+	raw, _ := url.PathUnescape(c.Param("division"))
+	division := NormalizeDivision(raw)
+	if !isValidDivision(division) {
+		c.JSON(400, gin.H{"error": "invalid division"})
+		return
+	}
+
+	var req models.SPHNumberFormatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "format is required"})
+		return
+	}
+
+	req.Format = strings.TrimSpace(req.Format)
+	if !strings.Contains(req.Format, "{seq}") {
+		c.JSON(400, gin.H{"error": "format must contain {seq}"})
+		return
+	}
+	// nomor tanpa tahun akan bentrok setelah counter reset
+	if !strings.Contains(req.Format, "{year}") && !strings.Contains(req.Format, "{yy}") {
+		c.JSON(400, gin.H{"error": "format must contain {year} or {yy}"})
+		return
+	}
+	if req.SeqPadding == 0 {
+		req.SeqPadding = 3
+	}
+	if req.SeqPadding < 1 || req.SeqPadding > 8 {
+		c.JSON(400, gin.H{"error": "seq_padding must be between 1 and 8"})
+		return
+	}
+
+	_, err := database.Pool.Exec(c, `
+		INSERT INTO sph_number_formats (division, format, seq_padding, updated_by, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4::bigint, 0), NOW())
+		ON CONFLICT (division) DO UPDATE
+			SET format = EXCLUDED.format, seq_padding = EXCLUDED.seq_padding,
+			    updated_by = EXCLUDED.updated_by, updated_at = NOW()
+	`, division, req.Format, req.SeqPadding, c.GetInt64("user_id"))
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"status":  "updated",
+		"preview": formatSPHNumber(req.Format, req.SeqPadding, division, 1, jakartaToday()),
+	})
+}
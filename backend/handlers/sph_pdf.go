@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"sales-system-backend/database"
+	"sales-system-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-pdf/fpdf"
+	"github.com/jackc/pgx/v5"
+)
+
+// data yang dibutuhkan template PDF
+type sphPDFData struct {
+	Title        string
+	ProjectCode  string
+	Description  string
+	CustomerName string
+	ContactName  string
+	ContactTitle string
+	Version      models.SPHVersion
+}
+
+var indonesianMonths = []string{
+	"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+	"Juli", "Agustus", "September", "Oktober", "November", "Desember",
+}
+
+func formatTanggal(t time.Time) string {
+	return fmt.Sprintf("%d %s %d", t.Day(), indonesianMonths[t.Month()-1], t.Year())
+}
+
+// formatQty: 2 → "2", 2.5 → "2,5"
+func formatQty(v float64) string {
+	s := strconv.FormatFloat(v, 'f', -1, 64)
+	return strings.Replace(s, ".", ",", 1)
+}
+
+// renderSPHPDF → template SPH A4 (kop, penerima, tabel item, total, syarat)
+func renderSPHPDF(w io.Writer, d sphPDFData) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 20)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	v := d.Version
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.CellFormat(0, 5, fmt.Sprintf("Halaman %d", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+
+	pdf.AddPage()
+
+	// --- kop ---
+	company := os.Getenv("SPH_COMPANY_NAME")
+	if company != "" {
+		pdf.SetFont("Helvetica", "B", 12)
+		pdf.CellFormat(0, 6, tr(company), "", 1, "L", false, 0, "")
+		if addr := os.Getenv("SPH_COMPANY_ADDRESS"); addr != "" {
+			pdf.SetFont("Helvetica", "", 9)
+			pdf.MultiCell(0, 4.5, tr(addr), "", "L", false)
+		}
+		pdf.Ln(2)
+		pdf.Line(15, pdf.GetY(), 195, pdf.GetY())
+		pdf.Ln(4)
+	}
+
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(0, 8, "SURAT PENAWARAN HARGA", "", 1, "C", false, 0, "")
+	if v.Status == "draft" {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.SetTextColor(200, 0, 0)
+		pdf.CellFormat(0, 5, "DRAFT - BELUM DIRILIS", "", 1, "C", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	}
+	pdf.Ln(4)
+
+	// --- info dokumen ---
+	number := "-"
+	if v.SPHNumber != nil {
+		number = *v.SPHNumber
+	}
+	date := v.CreatedAt
+	if v.ReleasedAt != nil {
+		date = *v.ReleasedAt
+	}
+	date = date.In(mustLoadLocation("Asia/Jakarta"))
+
+	pdf.SetFont("Helvetica", "", 10)
+	info := [][2]string{
+		{"Nomor", number},
+		{"Tanggal", formatTanggal(date)},
+		{"Versi", strconv.Itoa(v.VersionNo)},
+		{"Project", d.ProjectCode},
+	}
+	if d.Title != "" {
+		info = append(info, [2]string{"Perihal", d.Title})
+	}
+	for _, row := range info {
+		pdf.CellFormat(30, 5.5, row[0], "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 5.5, tr(": "+row[1]), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	// --- penerima ---
+	pdf.CellFormat(0, 5.5, "Kepada Yth.", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "B", 10)
+	if d.ContactName != "" {
+		contact := d.ContactName
+		if d.ContactTitle != "" {
+			contact += " (" + d.ContactTitle + ")"
+		}
+		pdf.CellFormat(0, 5.5, tr(contact), "", 1, "L", false, 0, "")
+	}
+	pdf.CellFormat(0, 5.5, tr(d.CustomerName), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.Ln(3)
+
+	pdf.MultiCell(0, 5, tr("Dengan hormat, bersama ini kami sampaikan penawaran harga untuk "+d.Description+" dengan rincian sebagai berikut:"), "", "L", false)
+	pdf.Ln(3)
+
+	// --- tabel item ---
+	cols := []struct {
+		Title string
+		Width float64
+		Align string
+	}{
+		{"No", 9, "C"},
+		{"Produk / Deskripsi", 61, "L"},
+		{"Qty", 18, "R"},
+		{"Harga Satuan", 30, "R"},
+		{"Disc %", 14, "R"},
+		{"PPN %", 14, "R"},
+		{"Jumlah", 34, "R"},
+	}
+
+	header := func() {
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.SetFillColor(230, 230, 230)
+		for _, col := range cols {
+			pdf.CellFormat(col.Width, 7, col.Title, "1", 0, "C", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Helvetica", "", 9)
+	}
+	header()
+
+	for _, it := range v.Items {
+		product := it.ProductName
+		if it.Description != "" {
+			product += "\n" + it.Description
+		}
+		lines := pdf.SplitLines([]byte(tr(product)), cols[1].Width-2)
+		h := float64(len(lines)) * 4.5
+		if h < 6 {
+			h = 6
+		}
+
+		// pindah halaman manual supaya baris tidak terpotong
+		_, pageH := pdf.GetPageSize()
+		if pdf.GetY()+h > pageH-20 {
+			pdf.AddPage()
+			header()
+		}
+
+		x, y := pdf.GetXY()
+		cells := []string{
+			strconv.Itoa(it.LineNo),
+			"",
+			formatQty(it.Qty) + " " + it.Unit,
+			strings.TrimPrefix(formatRupiah(it.UnitPrice), "Rp "),
+			formatQty(it.DiscountPct),
+			formatQty(it.TaxPct),
+			strings.TrimPrefix(formatRupiah(it.LineTotal), "Rp "),
+		}
+		for i, col := range cols {
+			if i == 1 {
+				pdf.Rect(x, y, col.Width, h, "D")
+				pdf.SetXY(x+1, y+0.75)
+				pdf.MultiCell(col.Width-2, 4.5, tr(product), "", "L", false)
+				pdf.SetXY(x+col.Width, y)
+			} else {
+				pdf.CellFormat(col.Width, h, tr(cells[i]), "1", 0, col.Align, false, 0, "")
+			}
+			x += col.Width
+		}
+		pdf.SetXY(15, y+h)
+	}
+
+	// --- total ---
+	labelW := 0.0
+	for _, col := range cols[:6] {
+		labelW += col.Width
+	}
+	totals := [][2]string{
+		{"Subtotal", formatRupiah(v.Subtotal)},
+		{"Diskon", formatRupiah(-v.DiscountTotal)},
+		{"PPN", formatRupiah(v.TaxTotal)},
+		{"Grand Total", formatRupiah(v.GrandTotal)},
+	}
+	for i, t := range totals {
+		if i == len(totals)-1 {
+			pdf.SetFont("Helvetica", "B", 9)
+		}
+		pdf.CellFormat(labelW, 6, t[0], "1", 0, "R", false, 0, "")
+		pdf.CellFormat(cols[6].Width, 6, t[1], "1", 1, "R", false, 0, "")
+	}
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.Ln(5)
+
+	// --- syarat & catatan ---
+	if v.ValidUntil != nil {
+		if t, err := time.Parse("2006-01-02", *v.ValidUntil); err == nil {
+			pdf.MultiCell(0, 5, "Penawaran ini berlaku sampai dengan "+formatTanggal(t)+".", "", "L", false)
+			pdf.Ln(2)
+		}
+	}
+	if v.Terms != "" {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(0, 5.5, "Syarat & Ketentuan", "", 1, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.MultiCell(0, 5, tr(v.Terms), "", "L", false)
+		pdf.Ln(2)
+	}
+	if v.Notes != "" {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(0, 5.5, "Catatan", "", 1, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.MultiCell(0, 5, tr(v.Notes), "", "L", false)
+		pdf.Ln(2)
+	}
+
+	pdf.Ln(4)
+	pdf.MultiCell(0, 5, "Demikian penawaran ini kami sampaikan. Atas perhatian dan kerja samanya kami ucapkan terima kasih.", "", "L", false)
+	pdf.Ln(12)
+	pdf.CellFormat(0, 5.5, "Hormat kami,", "", 1, "L", false, 0, "")
+	if company != "" {
+		pdf.CellFormat(0, 5.5, tr(company), "", 1, "L", false, 0, "")
+	}
+
+	return pdf.Output(w)
+}
+
+// ======================================================
+// GET /projects/:id/sph/versions/:versionId/pdf
+// ======================================================
+
+func DownloadSPHVersionPDF(c *gin.Context) {
+	projectID, versionID, ok := parseSPHVersionParams(c)
+	if !ok {
+		return
+	}
+	if _, ok := checkProjectAccess(c, projectID); !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	v, err := loadSPHVersion(ctx, database.Pool, projectID, versionID)
+	if err == pgx.ErrNoRows {
+		c.JSON(404, gin.H{"error": "SPH version not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	d := sphPDFData{Version: v}
+	var customerID *int64
+	err = database.Pool.QueryRow(ctx, `
+		SELECT p.project_code, p.description, p.customer_id, COALESCE(cu.name, ''), sd.title
+		FROM projects p
+		JOIN sph_documents sd ON sd.project_id = p.id
+		LEFT JOIN customers cu ON cu.id = p.customer_id
+		WHERE p.id = $1
+	`, projectID).Scan(&d.ProjectCode, &d.Description, &customerID, &d.CustomerName, &d.Title)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	// penerima = primary contact customer (kalau ada)
+	if customerID != nil {
+		err = database.Pool.QueryRow(ctx, `
+			SELECT name, COALESCE(title, '')
+			FROM customer_contacts
+			WHERE customer_id = $1 AND is_primary
+			LIMIT 1
+		`, *customerID).Scan(&d.ContactName, &d.ContactTitle)
+		if err != nil && err != pgx.ErrNoRows {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := renderSPHPDF(&buf, d); err != nil {
+		c.JSON(500, gin.H{"error": "failed to render PDF: " + err.Error()})
+		return
+	}
+
+	name := d.ProjectCode
+	if v.SPHNumber != nil {
+		name = *v.SPHNumber
+	}
+	name = strings.NewReplacer("/", "-", "\\", "-", " ", "_").Replace(name)
+	filename := fmt.Sprintf("SPH_%s_v%d.pdf", name, v.VersionNo)
+
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s"`, filename))
+	c.Data(200, "application/pdf", buf.Bytes())
+}
@@ -0,0 +1,572 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sales-system-backend/database"
+	"sales-system-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+var staleRuleTypes = []string{"stage_idle", "sph_open", "revenue_unrealized"}
+
+func isValidStaleRuleType(t string) bool {
+	for _, v := range staleRuleTypes {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+// ======================================================
+// RULES
+// ======================================================
+
+func scanStaleRule(row pgx.Row) (models.StaleRule, error) {
+	var r models.StaleRule
+	err := row.Scan(&r.ID, &r.Division, &r.RuleType, &r.SalesStage, &r.ThresholdDays,
+		&r.IsActive, &r.CreatedAt, &r.UpdatedAt)
+	return r, err
+}
+
+const staleRuleColumns = `id, division, rule_type, sales_stage, threshold_days, is_active, created_at, updated_at`
+
+// loadEffectiveStaleRules → rule default + override divisi (termasuk yang nonaktif,
+// supaya divisi bisa mematikan rule default)
+func loadEffectiveStaleRules(ctx context.Context, db dbtx, division string) ([]models.EffectiveStaleRule, error) {
+	rows, err := db.Query(ctx, `
+		SELECT `+staleRuleColumns+`
+		FROM stale_rules
+		WHERE division IS NULL OR division = $1
+		ORDER BY rule_type, COALESCE(sales_stage, 0), division NULLS FIRST
+	`, division)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type key struct {
+		ruleType string
+		stage    int
+	}
+	byKey := map[key]int{}
+	list := []models.EffectiveStaleRule{}
+
+	for rows.Next() {
+		r, err := scanStaleRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		k := key{ruleType: r.RuleType}
+		if r.SalesStage != nil {
+			k.stage = *r.SalesStage
+		}
+
+		eff := models.EffectiveStaleRule{StaleRule: r, Inherited: r.Division == nil}
+		// default muncul lebih dulu (NULLS FIRST) → rule divisi menimpa
+		if i, ok := byKey[k]; ok {
+			list[i] = eff
+			continue
+		}
+		byKey[k] = len(list)
+		list = append(list, eff)
+	}
+
+	return list, rows.Err()
+}
+
+// GET /stale-rules?division=
+// tanpa division (admin) → semua rule mentah; dengan division → rule efektif
+func ListStaleRules(c *gin.Context) {
+	division := NormalizeDivision(strings.TrimSpace(c.Query("division")))
+	if c.GetString("role") == "user" {
+		division = NormalizeDivision(c.GetString("division"))
+	}
+	if strings.ToUpper(division) == "ALL" {
+		division = ""
+	}
+
+	if division != "" {
+		if !isValidDivision(division) {
+			c.JSON(400, gin.H{"error": "invalid division"})
+			return
+		}
+		list, err := loadEffectiveStaleRules(c, database.Pool, division)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{"division": division, "rules": list})
+		return
+	}
+
+	rows, err := database.Pool.Query(c, `
+		SELECT `+staleRuleColumns+`
+		FROM stale_rules
+		ORDER BY division NULLS FIRST, rule_type, COALESCE(sales_stage, 0)
+	`)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	list := []models.StaleRule{}
+	for rows.Next() {
+		r, err := scanStaleRule(rows)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		list = append(list, r)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, list)
+}
+
+// PUT /stale-rules (ADMIN) — upsert per (division, rule_type, sales_stage)
+func SetStaleRule(c *gin.Context) {
+	var req models.StaleRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "rule_type and threshold_days are required"})
+		return
+	}
+
+	if !isValidStaleRuleType(req.RuleType) {
+		c.JSON(400, gin.H{"error": "invalid rule_type"})
+		return
+	}
+	if req.RuleType == "stage_idle" {
+		if req.SalesStage == nil || *req.SalesStage < 1 || *req.SalesStage > 6 {
+			c.JSON(400, gin.H{"error": "sales_stage (1-6) is required for stage_idle"})
+			return
+		}
+	} else {
+		req.SalesStage = nil
+	}
+	if *req.ThresholdDays < 0 {
+		c.JSON(400, gin.H{"error": "threshold_days must be >= 0"})
+		return
+	}
+
+	division, ok := normalizeOptionalDivision(req.Division)
+	if !ok {
+		c.JSON(400, gin.H{"error": "invalid division"})
+		return
+	}
+
+	active := true
+	if req.IsActive != nil {
+		active = *req.IsActive
+	}
+
+	r, err := scanStaleRule(database.Pool.QueryRow(c, `
+		INSERT INTO stale_rules (division, rule_type, sales_stage, threshold_days, is_active)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (COALESCE(division, ''), rule_type, COALESCE(sales_stage, 0))
+		DO UPDATE SET threshold_days = EXCLUDED.threshold_days,
+		              is_active = EXCLUDED.is_active,
+		              updated_at = NOW()
+		RETURNING `+staleRuleColumns,
+		division, req.RuleType, req.SalesStage, *req.ThresholdDays, active,
+	))
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, r)
+}
+
+// DELETE /stale-rules/:id (ADMIN) — override divisi dihapus → kembali ke default
+func DeleteStaleRule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid rule id"})
+		return
+	}
+
+	tag, err := database.Pool.Exec(c, `DELETE FROM stale_rules WHERE id = $1`, id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(404, gin.H{"error": "rule not found"})
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "deleted"})
+}
+
+// ======================================================
+// EVALUATION
+// ======================================================
+
+type staleFlag struct {
+	ProjectID int64
+	RuleType  string
+	RuleID    int64
+	Threshold int
+	DaysStale int
+	Detail    string
+}
+
+// query per rule_type: $1 division, $2 threshold_days, $3 hari ini (Asia/Jakarta),
+// $4 sales_stage (stage_idle saja)
+// hasil: project_id, days_stale, detail
+var staleRuleQueries = map[string]string{
+	"stage_idle": `
+		SELECT p.id,
+		       ($3::date - (p.stage_changed_at AT TIME ZONE 'Asia/Jakarta')::date)::int AS days,
+		       'Stage ' || p.sales_stage || ' unchanged since ' ||
+		       to_char(p.stage_changed_at AT TIME ZONE 'Asia/Jakarta', 'YYYY-MM-DD')
+		FROM projects p
+		WHERE p.division = $1
+		  AND p.sales_stage = $4
+		  AND COALESCE(p.sph_status, 'Open') NOT IN ('Win', 'Loss', 'Drop')
+		  AND ($3::date - (p.stage_changed_at AT TIME ZONE 'Asia/Jakarta')::date) > $2
+	`,
+	"sph_open": `
+		SELECT p.id,
+		       ($3::date - p.sph_release_date::date)::int AS days,
+		       'SPH released ' || to_char(p.sph_release_date, 'YYYY-MM-DD') || ', status still Open'
+		FROM projects p
+		WHERE p.division = $1
+		  AND p.sph_release_status = 'Yes'
+		  AND p.sph_release_date IS NOT NULL
+		  AND COALESCE(p.sph_status, 'Open') = 'Open'
+		  AND ($3::date - p.sph_release_date::date) > $2
+	`,
+	"revenue_unrealized": `
+		SELECT p.id,
+		       MAX($3::date - (rp.month + INTERVAL '1 month' - INTERVAL '1 day')::date)::int AS days,
+		       'No realization for ' || string_agg(to_char(rp.month, 'YYYY-MM'), ', ' ORDER BY rp.month)
+		FROM projects p
+		JOIN project_revenue_plan rp ON rp.project_id = p.id
+		WHERE p.division = $1
+		  AND COALESCE(p.sph_status, '') NOT IN ('Loss', 'Drop')
+		  AND rp.target_revenue > 0
+		  AND COALESCE(rp.target_realization, 0) = 0
+		  AND ($3::date - (rp.month + INTERVAL '1 month' - INTERVAL '1 day')::date) > $2
+		GROUP BY p.id
+	`,
+}
+
+// collectStaleFlagsTx → flag untuk semua divisi berdasarkan rule efektif
+func collectStaleFlagsTx(ctx context.Context, tx pgx.Tx, today time.Time) ([]staleFlag, error) {
+	var flags []staleFlag
+
+	for _, division := range canonicalDivisions {
+		rules, err := loadEffectiveStaleRules(ctx, tx, division)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range rules {
+			if !r.IsActive {
+				continue
+			}
+			q, ok := staleRuleQueries[r.RuleType]
+			if !ok {
+				continue
+			}
+
+			args := []any{division, r.ThresholdDays, today}
+			if r.RuleType == "stage_idle" {
+				args = append(args, r.SalesStage)
+			}
+
+			rows, err := tx.Query(ctx, q, args...)
+			if err != nil {
+				return nil, err
+			}
+			for rows.Next() {
+				f := staleFlag{RuleType: r.RuleType, RuleID: r.ID, Threshold: r.ThresholdDays}
+				if err := rows.Scan(&f.ProjectID, &f.DaysStale, &f.Detail); err != nil {
+					rows.Close()
+					return nil, err
+				}
+				flags = append(flags, f)
+			}
+			rows.Close()
+			if err := rows.Err(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return flags, nil
+}
+
+// cegah evaluasi paralel (job + trigger manual)
+var staleEvalMu sync.Mutex
+
+// evaluateStaleProjects menyimpan flag stale terbaru, menghapus flag yang sudah
+// tidak berlaku, lalu mengirim notifikasi ke owner untuk flag yang baru muncul.
+// Return: total flag aktif & jumlah flag baru.
+func evaluateStaleProjects(ctx context.Context) (int, int, error) {
+	staleEvalMu.Lock()
+	defer staleEvalMu.Unlock()
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	flags, err := collectStaleFlagsTx(ctx, tx, jakartaToday())
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var newFlags []staleFlag
+	for _, f := range flags {
+		var inserted bool
+		err := tx.QueryRow(ctx, `
+			INSERT INTO stale_project_flags
+				(project_id, rule_type, rule_id, threshold_days, days_stale, detail)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (project_id, rule_type) DO UPDATE SET
+				rule_id = EXCLUDED.rule_id,
+				threshold_days = EXCLUDED.threshold_days,
+				days_stale = EXCLUDED.days_stale,
+				detail = EXCLUDED.detail,
+				last_evaluated_at = NOW()
+			RETURNING (xmax = 0)
+		`, f.ProjectID, f.RuleType, f.RuleID, f.Threshold, f.DaysStale, f.Detail).Scan(&inserted)
+		if err != nil {
+			return 0, 0, err
+		}
+		if inserted {
+			newFlags = append(newFlags, f)
+		}
+	}
+
+	// NOW() = waktu mulai transaksi → flag yang tidak tersentuh run ini sudah tidak stale
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM stale_project_flags WHERE last_evaluated_at < NOW()
+	`); err != nil {
+		return 0, 0, err
+	}
+
+	if err := notifyStaleOwnersTx(ctx, tx, newFlags); err != nil {
+		return 0, 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, 0, err
+	}
+
+	return len(flags), len(newFlags), nil
+}
+
+// notifyStaleOwnersTx → 1 flag: notif per project; lebih dari itu: 1 ringkasan per owner
+func notifyStaleOwnersTx(ctx context.Context, tx pgx.Tx, flags []staleFlag) error {
+	if len(flags) == 0 {
+		return nil
+	}
+
+	type projectInfo struct {
+		code  string
+		owner *int64
+	}
+	infos := map[int64]projectInfo{}
+	byOwner := map[int64][]staleFlag{}
+
+	for _, f := range flags {
+		info, ok := infos[f.ProjectID]
+		if !ok {
+			// owner nonaktif diperlakukan seperti project tanpa owner
+			if err := tx.QueryRow(ctx, `
+				SELECT p.project_code, u.id
+				FROM projects p
+				LEFT JOIN users u ON u.id = p.owner_user_id AND u.is_active
+				WHERE p.id = $1
+			`, f.ProjectID).Scan(&info.code, &info.owner); err != nil {
+				return err
+			}
+			infos[f.ProjectID] = info
+		}
+		// project tanpa owner (aktif) → cukup muncul di report
+		if info.owner != nil {
+			byOwner[*info.owner] = append(byOwner[*info.owner], f)
+		}
+	}
+
+	for ownerID, list := range byOwner {
+		if len(list) == 1 {
+			f := list[0]
+			code := infos[f.ProjectID].code
+			if err := createNotification(ctx, tx, ownerID,
+				"stale_project",
+				"Stale deal: "+code,
+				fmt.Sprintf("%s: %s (%d days).", code, f.Detail, f.DaysStale),
+				"project", f.ProjectID,
+			); err != nil {
+				return err
+			}
+			continue
+		}
+
+		codes := make([]string, 0, len(list))
+		seen := map[int64]bool{}
+		for _, f := range list {
+			if !seen[f.ProjectID] {
+				seen[f.ProjectID] = true
+				codes = append(codes, infos[f.ProjectID].code)
+			}
+		}
+		if err := createNotification(ctx, tx, ownerID,
+			"stale_project",
+			fmt.Sprintf("%d deals need attention", len(codes)),
+			"Stale deals: "+strings.Join(codes, ", ")+".",
+			"", 0,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// StartStaleDealJob menjalankan evaluasi saat start lalu tiap STALE_JOB_INTERVAL
+// (default 6h) sampai ctx dibatalkan.
+func StartStaleDealJob(ctx context.Context) {
+	interval := 6 * time.Hour
+	if v := os.Getenv("STALE_JOB_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < time.Minute {
+			log.Println("invalid STALE_JOB_INTERVAL, using default:", v)
+		} else {
+			interval = d
+		}
+	}
+
+	go func() {
+		run := func() {
+			total, added, err := evaluateStaleProjects(ctx)
+			if err != nil {
+				log.Println("STALE DEAL JOB ERROR:", err)
+				return
+			}
+			log.Printf("stale deal job: %d flagged, %d new", total, added)
+		}
+
+		run()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				run()
+			}
+		}
+	}()
+}
+
+// POST /reports/stale-projects/run (ADMIN) — evaluasi sekarang
+func RunStaleProjectEvaluation(c *gin.Context) {
+	total, added, err := evaluateStaleProjects(c.Request.Context())
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"flagged": total, "new": added})
+}
+
+// ======================================================
+// REPORT — GET /reports/stale-projects?division=&rule_type=&mine=&owner_id=
+// hasil evaluasi job terakhir
+// ======================================================
+
+func GetStaleProjectsReport(c *gin.Context) {
+	role := c.GetString("role")
+	userDiv := NormalizeDivision(c.GetString("division"))
+
+	conds := []string{"1=1"}
+	args := []any{}
+	i := 1
+
+	division := NormalizeDivision(strings.TrimSpace(c.Query("division")))
+	if role == "user" {
+		division = userDiv
+	}
+	if division != "" && strings.ToUpper(division) != "ALL" {
+		conds = append(conds, fmt.Sprintf("p.division = $%d", i))
+		args = append(args, division)
+		i++
+	}
+
+	if v := strings.TrimSpace(c.Query("rule_type")); v != "" && strings.ToUpper(v) != "ALL" {
+		if !isValidStaleRuleType(v) {
+			c.JSON(400, gin.H{"error": "invalid rule_type"})
+			return
+		}
+		conds = append(conds, fmt.Sprintf("f.rule_type = $%d", i))
+		args = append(args, v)
+		i++
+	}
+
+	if cond, arg, ok := ownerFilterCond(c, i); ok {
+		conds = append(conds, cond)
+		args = append(args, arg)
+	}
+
+	rows, err := database.Pool.Query(c, `
+		SELECT
+			p.id, p.project_code, p.description, COALESCE(cu.name, ''), p.division,
+			p.sales_stage, p.sph_status, p.owner_user_id, ow.username,
+			f.rule_type, f.threshold_days, f.days_stale, f.detail,
+			f.first_flagged_at, f.last_evaluated_at
+		FROM stale_project_flags f
+		JOIN projects p ON p.id = f.project_id
+		LEFT JOIN customers cu ON cu.id = p.customer_id
+		LEFT JOIN users ow ON ow.id = p.owner_user_id
+		WHERE `+strings.Join(conds, " AND ")+`
+		ORDER BY f.days_stale DESC, p.project_code ASC
+	`, args...)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	list := []models.StaleProjectRow{}
+	for rows.Next() {
+		var r models.StaleProjectRow
+		if err := rows.Scan(
+			&r.ProjectID, &r.ProjectCode, &r.Description, &r.CustomerName, &r.Division,
+			&r.SalesStage, &r.SPHStatus, &r.OwnerUserID, &r.OwnerName,
+			&r.RuleType, &r.ThresholdDays, &r.DaysStale, &r.Detail,
+			&r.FirstFlaggedAt, &r.LastEvaluated,
+		); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		list = append(list, r)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, list)
+}
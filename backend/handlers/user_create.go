@@ -21,6 +21,7 @@ func CreateUser(c *gin.Context) {
 		Password string `json:"password"`
 		Role     string `json:"role"`
 		Division string `json:"division"`
+		Email    string `json:"email"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -46,6 +47,16 @@ func CreateUser(c *gin.Context) {
 		return
 	}
 
+	// Email opsional (untuk notifikasi)
+	var email *string
+	if e := strings.TrimSpace(req.Email); e != "" {
+		if !strings.Contains(e, "@") {
+			c.JSON(400, gin.H{"error": "invalid email"})
+			return
+		}
+		email = &e
+	}
+
 	// Normalize division to match your DB usage
 	normalizedDivision := NormalizeDivision(req.Division)
 
@@ -61,14 +72,15 @@ func CreateUser(c *gin.Context) {
 	err = database.Pool.QueryRow(
 		c,
 		`
-		INSERT INTO users (username, password_hash, role, division)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO users (username, password_hash, role, division, email)
+		VALUES ($1, $2, $3, $4, $5)
 		RETURNING id
 		`,
 		req.Username,
 		string(hash),
 		req.Role,
 		normalizedDivision,
+		email,
 	).Scan(&id)
 
 	if err != nil {
@@ -87,5 +99,6 @@ func CreateUser(c *gin.Context) {
 		"username": req.Username,
 		"role":     req.Role,
 		"division": normalizedDivision,
+		"email":    email,
 	})
 }
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"sales-system-backend/database"
 	"strconv"
@@ -8,6 +9,23 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// record kepemilikan yang boleh dipindah ke user lain (?reassign_to)
+// kolom audit (created_by / merged_by, FK SET NULL) tidak dipindah supaya jejak pelaku tetap benar
+var userDependents = []dependentRef{
+	{Type: "owned_projects", Table: "projects", Column: "owner_user_id", Label: "project_code"},
+	{Type: "owned_leads", Table: "leads", Column: "owner_user_id", Label: "name"},
+	{Type: "next_steps", Table: "activities", Column: "next_step_owner_id", Label: "next_step"},
+}
+
+// record payroll / audit yang tidak boleh dipindah ke user lain → hard delete ditolak
+var userLockedDependents = []dependentRef{
+	{Type: "commission_statements", Table: "commission_statements", Column: "user_id", Label: "'run #' || run_id::text"},
+	{Type: "budget_realizations", Table: "budget_realization", Column: "created_by", Label: "category || ' - ' || amount::text"},
+}
+
+// DELETE /users/:id
+// default: nonaktifkan user (row tetap ada, login ditolak)
+// ?hard=true: hapus row; dependent → 409 kecuali ?reassign_to=<userId>
 func DeleteUser(c *gin.Context) {
 	if c.GetString("role") != "admin" {
 		c.JSON(http.StatusForbidden, gin.H{"error": "admin only"})
@@ -23,16 +41,117 @@ func DeleteUser(c *gin.Context) {
 		return
 	}
 
-	result, err := database.Pool.Exec(c, `DELETE FROM users WHERE id=$1`, id)
+	if c.Query("hard") != "true" {
+		result, err := database.Pool.Exec(c, `
+			UPDATE users
+			   SET is_active = false,
+			       deactivated_at = COALESCE(deactivated_at, NOW()),
+			       updated_at = NOW()
+			 WHERE id = $1
+		`, id)
+		if err != nil {
+			c.JSON(500, gin.H{"error": "deactivate failed"})
+			return
+		}
+
+		if result.RowsAffected() == 0 {
+			c.JSON(404, gin.H{"error": "user not found"})
+			return
+		}
+
+		c.JSON(200, gin.H{"status": "deactivated"})
+		return
+	}
+
+	var reassignTo int64
+	if s := c.Query("reassign_to"); s != "" {
+		var err error
+		reassignTo, err = strconv.ParseInt(s, 10, 64)
+		if err != nil || reassignTo <= 0 || reassignTo == id {
+			c.JSON(400, gin.H{"error": "invalid reassign_to"})
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+
+	tx, err := database.Pool.Begin(ctx)
 	if err != nil {
-		c.JSON(500, gin.H{"error": "delete failed"})
+		c.JSON(500, gin.H{"error": "db transaction error"})
 		return
 	}
+	defer tx.Rollback(ctx)
 
-	if result.RowsAffected() == 0 {
+	var locked int64
+	if err := tx.QueryRow(ctx, `SELECT id FROM users WHERE id=$1 FOR UPDATE`, id).Scan(&locked); err != nil {
 		c.JSON(404, gin.H{"error": "user not found"})
 		return
 	}
 
-	c.JSON(200, gin.H{"status": "deleted"})
+	history, err := loadDependents(ctx, tx, userLockedDependents, id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if len(history) > 0 {
+		c.JSON(409, gin.H{
+			"error":      "user has commission / realization history; deactivate instead",
+			"dependents": history,
+		})
+		return
+	}
+
+	deps, err := loadDependents(ctx, tx, userDependents, id)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(deps) > 0 {
+		if reassignTo == 0 {
+			c.JSON(409, gin.H{
+				"error":      "user has dependent records; deactivate instead or pass reassign_to",
+				"dependents": deps,
+			})
+			return
+		}
+
+		var active bool
+		if err := tx.QueryRow(ctx, `SELECT is_active FROM users WHERE id=$1`, reassignTo).Scan(&active); err != nil {
+			c.JSON(404, gin.H{"error": "reassign_to user not found"})
+			return
+		}
+		if !active {
+			c.JSON(400, gin.H{"error": "reassign_to user is deactivated"})
+			return
+		}
+
+		for _, ref := range userDependents {
+			// table / column dari whitelist userDependents
+			if _, err := tx.Exec(ctx,
+				fmt.Sprintf(`UPDATE %s SET %s = $2 WHERE %s = $1`, ref.Table, ref.Column, ref.Column),
+				id, reassignTo,
+			); err != nil {
+				c.JSON(500, gin.H{"error": err.Error()})
+				return
+			}
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM users WHERE id=$1`, id); err != nil {
+		c.JSON(500, gin.H{"error": "delete failed"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(500, gin.H{"error": "transaction commit error"})
+		return
+	}
+
+	resp := gin.H{"status": "deleted"}
+	if len(deps) > 0 {
+		resp["reassigned_to"] = reassignTo
+		resp["reassigned"] = deps
+	}
+	c.JSON(200, resp)
 }
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"sales-system-backend/database"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
@@ -22,6 +23,8 @@ func UpdateUser(c *gin.Context) {
 		Password *string `json:"password"`
 		Role     string  `json:"role"`
 		Division string  `json:"division"`
+		Email    *string `json:"email"` // nil = tidak diubah, "" = hapus email
+		IsActive *bool   `json:"is_active"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -29,6 +32,21 @@ func UpdateUser(c *gin.Context) {
 		return
 	}
 
+	emailSet := req.Email != nil
+	email := ""
+	if emailSet {
+		email = strings.TrimSpace(*req.Email)
+		if email != "" && !strings.Contains(email, "@") {
+			c.JSON(400, gin.H{"error": "invalid email"})
+			return
+		}
+	}
+
+	if req.IsActive != nil && !*req.IsActive && id == c.GetInt64("user_id") {
+		c.JSON(400, gin.H{"error": "cannot deactivate yourself"})
+		return
+	}
+
 	// Hash password only if provided
 	var passwordHash *string
 	if req.Password != nil && *req.Password != "" {
@@ -41,9 +59,17 @@ func UpdateUser(c *gin.Context) {
 	if passwordHash != nil {
 		_, err := database.Pool.Exec(c, `
 			UPDATE users
-			   SET username=$1, password_hash=$2, role=$3, division=$4, updated_at=NOW()
-			 WHERE id=$5
-		`, req.Username, *passwordHash, req.Role, NormalizeDivision(req.Division), id)
+			   SET username=$1, password_hash=$2, role=$3, division=$4,
+			       email=CASE WHEN $5::bool THEN NULLIF($6, '') ELSE email END,
+			       is_active=COALESCE($8, is_active),
+			       deactivated_at=CASE
+			           WHEN $8::bool IS NULL THEN deactivated_at
+			           WHEN $8 THEN NULL
+			           ELSE COALESCE(deactivated_at, NOW())
+			       END,
+			       updated_at=NOW()
+			 WHERE id=$7
+		`, req.Username, *passwordHash, req.Role, NormalizeDivision(req.Division), emailSet, email, id, req.IsActive)
 
 		if err != nil {
 			c.JSON(500, gin.H{"error": "update failed"})
@@ -52,9 +78,17 @@ func UpdateUser(c *gin.Context) {
 	} else {
 		_, err := database.Pool.Exec(c, `
 			UPDATE users
-			   SET username=$1, role=$2, division=$3, updated_at=NOW()
-			 WHERE id=$4
-		`, req.Username, req.Role, NormalizeDivision(req.Division), id)
+			   SET username=$1, role=$2, division=$3,
+			       email=CASE WHEN $4::bool THEN NULLIF($5, '') ELSE email END,
+			       is_active=COALESCE($7, is_active),
+			       deactivated_at=CASE
+			           WHEN $7::bool IS NULL THEN deactivated_at
+			           WHEN $7 THEN NULL
+			           ELSE COALESCE(deactivated_at, NOW())
+			       END,
+			       updated_at=NOW()
+			 WHERE id=$6
+		`, req.Username, req.Role, NormalizeDivision(req.Division), emailSet, email, id, req.IsActive)
 
 		if err != nil {
 			c.JSON(500, gin.H{"error": "update failed"})
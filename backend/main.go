@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"sales-system-backend/database"
+	"sales-system-backend/handlers"
+	"sales-system-backend/notifier"
 	"sales-system-backend/routes"
 
 	"github.com/gin-contrib/cors"
@@ -27,6 +29,14 @@ func main() {
 	}
 	defer database.Pool.Close()
 
+	// Email channel (opsional, via SMTP)
+	notifier.Init()
+
+	// Background job: deteksi stale deal (berhenti saat shutdown)
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	handlers.StartStaleDealJob(jobCtx)
+
 	// Setup Gin router
 	r := gin.Default()
 	r.Use(cors.New(cors.Config{
@@ -36,7 +46,7 @@ func main() {
 		},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    []string{"Content-Length", "X-Total-Count"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -82,6 +92,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("Shutting down server...")
+	stopJobs()
 
 	// Gracefully stop server
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strings"
 
+	"sales-system-backend/database"
 	"sales-system-backend/handlers"
 
 	"github.com/gin-gonic/gin"
@@ -44,6 +45,17 @@ func AuthRequired() gin.HandlerFunc {
 
 		division = handlers.NormalizeDivision(division)
 
+		// user yang sudah dinonaktifkan → token lama tidak berlaku lagi
+		var active bool
+		err = database.Pool.QueryRow(c.Request.Context(),
+			`SELECT is_active FROM users WHERE id = $1`, userID,
+		).Scan(&active)
+		if err != nil || !active {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user is inactive"})
+			c.Abort()
+			return
+		}
+
 		c.Set("role", role)
 		c.Set("division", division)
 		c.Set("user_id", userID)
@@ -0,0 +1,44 @@
+-- =====================================================
+--  BUDGET THRESHOLD ALERTS + IN-APP NOTIFICATIONS
+-- =====================================================
+
+-- email tujuan notifikasi (opsional)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email text;
+
+-- threshold per divisi, mis. 80 / 100 / 120 (%)
+CREATE TABLE IF NOT EXISTS budget_alert_thresholds (
+    id            bigserial PRIMARY KEY,
+    division      text NOT NULL,
+    threshold_pct numeric(6,2) NOT NULL CHECK (threshold_pct > 0),
+    notify_email  boolean NOT NULL DEFAULT true,
+    created_at    timestamptz NOT NULL DEFAULT now(),
+    updated_at    timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (division, threshold_pct)
+);
+
+-- threshold yang sudah terpicu per budget (supaya alert tidak dikirim berulang)
+CREATE TABLE IF NOT EXISTS budget_alert_events (
+    budget_id     bigint NOT NULL REFERENCES budgets(id) ON DELETE CASCADE,
+    threshold_pct numeric(6,2) NOT NULL,
+    achievement   numeric(10,2) NOT NULL,
+    triggered_at  timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (budget_id, threshold_pct)
+);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id         bigserial PRIMARY KEY,
+    user_id    bigint NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type       text NOT NULL,
+    title      text NOT NULL,
+    message    text NOT NULL,
+    ref_type   text,
+    ref_id     bigint,
+    read_at    timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_created
+    ON notifications (user_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_unread
+    ON notifications (user_id) WHERE read_at IS NULL;
@@ -0,0 +1,28 @@
+-- =====================================================
+--  CUSTOMER HIERARCHY (holding / group → subsidiaries)
+-- =====================================================
+
+ALTER TABLE customers
+    ADD COLUMN IF NOT EXISTS parent_id bigint REFERENCES customers(id) ON DELETE SET NULL;
+
+ALTER TABLE customers DROP CONSTRAINT IF EXISTS customers_parent_not_self;
+ALTER TABLE customers
+    ADD CONSTRAINT customers_parent_not_self CHECK (parent_id IS NULL OR parent_id <> id);
+
+CREATE INDEX IF NOT EXISTS idx_customers_parent_id ON customers (parent_id);
+
+-- setiap customer → root group paling atas (customer tanpa parent = root dirinya sendiri)
+CREATE OR REPLACE VIEW customer_group_roots AS
+WITH RECURSIVE chain AS (
+    SELECT id AS customer_id, id AS root_id, parent_id, 0 AS depth
+    FROM customers
+    UNION ALL
+    SELECT ch.customer_id, p.id, p.parent_id, ch.depth + 1
+    FROM chain ch
+    JOIN customers p ON p.id = ch.parent_id
+    WHERE ch.depth < 20
+)
+SELECT ch.customer_id, ch.root_id, r.name AS root_name
+FROM chain ch
+JOIN customers r ON r.id = ch.root_id
+WHERE ch.parent_id IS NULL;
@@ -0,0 +1,37 @@
+-- =====================================================
+--  CUSTOMER CONTACTS + PROJECT STAKEHOLDERS
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS customer_contacts (
+    id          bigserial PRIMARY KEY,
+    customer_id bigint NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    name        text NOT NULL,
+    title       text,
+    email       text,
+    phone       text,
+    buying_role text,
+    is_primary  boolean NOT NULL DEFAULT false,
+    notes       text,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    updated_at  timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT customer_contacts_buying_role_check CHECK (
+        buying_role IS NULL OR buying_role = ANY (ARRAY[
+            'Decision Maker', 'Economic Buyer', 'Technical Buyer',
+            'Influencer', 'Champion', 'User', 'Gatekeeper'
+        ])
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_contacts_customer_id ON customer_contacts (customer_id);
+
+-- maksimal 1 primary contact per customer
+CREATE UNIQUE INDEX IF NOT EXISTS uq_customer_contacts_primary
+    ON customer_contacts (customer_id) WHERE is_primary;
+
+CREATE TABLE IF NOT EXISTS project_stakeholders (
+    project_id bigint NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    contact_id bigint NOT NULL REFERENCES customer_contacts(id) ON DELETE CASCADE,
+    note       text,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (project_id, contact_id)
+);
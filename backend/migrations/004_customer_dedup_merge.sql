@@ -0,0 +1,37 @@
+-- =====================================================
+--  CUSTOMER DEDUPLICATION + MERGE HISTORY
+-- =====================================================
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- "PT Pertamina", "Pertamina (Persero)", "pertamina" → "pertamina"
+-- buang badan usaha (PT, CV, Tbk, Persero, ...) + tanda baca, lowercase
+CREATE OR REPLACE FUNCTION normalize_customer_name(n text) RETURNS text
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT btrim(regexp_replace(
+        regexp_replace(
+            regexp_replace(lower(coalesce(n, '')), '[^a-z0-9]+', ' ', 'g'),
+            '\m(pt|cv|tbk|persero|perum|perumda|ud|fa|inc|ltd|llc|corp)\M', ' ', 'g'
+        ),
+        '\s+', ' ', 'g'
+    ))
+$$;
+
+CREATE INDEX IF NOT EXISTS idx_customers_name_norm_trgm
+    ON customers USING gin (normalize_customer_name(name) gin_trgm_ops);
+
+CREATE TABLE IF NOT EXISTS customer_merge_history (
+    id              bigserial PRIMARY KEY,
+    target_id       bigint NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    source_id       bigint NOT NULL,          -- customer sudah dihapus, simpan id + snapshot
+    source_name     text NOT NULL,
+    source_industry text,
+    source_region   text,
+    projects_moved  int NOT NULL DEFAULT 0,
+    contacts_moved  int NOT NULL DEFAULT 0,
+    children_moved  int NOT NULL DEFAULT 0,
+    merged_by       bigint REFERENCES users(id) ON DELETE SET NULL,
+    merged_at       timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_merge_history_target ON customer_merge_history (target_id);
@@ -0,0 +1,15 @@
+-- =====================================================
+--  CUSTOMER OWNERSHIP PER DIVISION (opsional)
+--  customer tanpa baris di sini = terlihat semua divisi
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS customer_divisions (
+    customer_id bigint NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    division    text NOT NULL,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (customer_id, division)
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_divisions_division ON customer_divisions (division);
+
+CREATE INDEX IF NOT EXISTS idx_customers_name_trgm ON customers USING gin (name gin_trgm_ops);
@@ -0,0 +1,149 @@
+-- =====================================================
+--  REFERENCE DATA: INDUSTRIES + REGIONS (→ PROVINSI)
+--  customers.industry / customers.region menyimpan nama kanonik
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS industries (
+    id         bigserial PRIMARY KEY,
+    name       text NOT NULL UNIQUE,
+    aliases    text[] NOT NULL DEFAULT '{}',   -- ejaan lain untuk backfill / input bebas
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS regions (
+    id         bigserial PRIMARY KEY,
+    name       text NOT NULL UNIQUE,
+    aliases    text[] NOT NULL DEFAULT '{}',
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS provinces (
+    id        bigserial PRIMARY KEY,
+    name      text NOT NULL UNIQUE,
+    aliases   text[] NOT NULL DEFAULT '{}',   -- termasuk kota besar (Bandung → Jawa Barat)
+    region_id bigint REFERENCES regions(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_provinces_region_id ON provinces (region_id);
+
+-- ---------- seed: industries ----------
+INSERT INTO industries (name, aliases) VALUES
+    ('Telekomunikasi',       ARRAY['telco', 'telecommunication', 'telecommunications', 'telekomunikasi & media']),
+    ('Pemerintahan',         ARRAY['government', 'pemerintah', 'goverment', 'gov', 'kementerian', 'pemda']),
+    ('Militer',              ARRAY['military', 'tni', 'pertahanan', 'defense', 'defence']),
+    ('Transportasi',         ARRAY['transportation', 'transport', 'logistik', 'logistics']),
+    ('Teknologi',            ARRAY['technology', 'it', 'tech', 'teknologi informasi']),
+    ('Hospitality',          ARRAY['hotel', 'perhotelan', 'pariwisata']),
+    ('Kesehatan',            ARRAY['healthcare', 'health', 'rumah sakit', 'hospital']),
+    ('Pengelola Kawasan',    ARRAY['kawasan industri', 'estate management', 'perkebunan']),
+    ('Power System',         ARRAY['power', 'ketenagalistrikan', 'electricity', 'energi listrik']),
+    ('Minyak & Gas',         ARRAY['oil & gas', 'oil and gas', 'migas', 'oil gas']),
+    ('Pertambangan',         ARRAY['mining', 'tambang']),
+    ('Perbankan & Keuangan', ARRAY['banking', 'bank', 'finance', 'keuangan', 'perbankan']),
+    ('Pendidikan',           ARRAY['education', 'universitas', 'kampus']),
+    ('Manufaktur',           ARRAY['manufacturing', 'industri']),
+    ('Media',                ARRAY['broadcasting', 'media & broadcasting', 'penyiaran']),
+    ('Retail',               ARRAY['ritel', 'retail & distribution']),
+    ('Utilitas',             ARRAY['utilities', 'air bersih', 'water', 'pdam'])
+ON CONFLICT (name) DO NOTHING;
+
+-- ---------- seed: regions ----------
+INSERT INTO regions (name, aliases) VALUES
+    ('Sumatera',             ARRAY['sumatra']),
+    ('Jawa',                 ARRAY['java']),
+    ('Bali & Nusa Tenggara', ARRAY['bali nusra', 'nusra', 'nusa tenggara']),
+    ('Kalimantan',           ARRAY['borneo']),
+    ('Sulawesi',             ARRAY['celebes']),
+    ('Maluku',               ARRAY['moluccas']),
+    ('Papua',                ARRAY[]::text[]),
+    ('Nasional',             ARRAY['national', 'nationwide', 'seluruh indonesia', 'indonesia'])
+ON CONFLICT (name) DO NOTHING;
+
+-- ---------- seed: 38 provinsi ----------
+INSERT INTO provinces (name, aliases, region_id)
+SELECT v.name, v.aliases, r.id
+FROM (VALUES
+    ('Aceh',                      ARRAY['nanggroe aceh darussalam', 'banda aceh'],          'Sumatera'),
+    ('Sumatera Utara',            ARRAY['sumut', 'north sumatra', 'medan'],                 'Sumatera'),
+    ('Sumatera Barat',            ARRAY['sumbar', 'west sumatra', 'padang'],                'Sumatera'),
+    ('Riau',                      ARRAY['pekanbaru'],                                       'Sumatera'),
+    ('Kepulauan Riau',            ARRAY['kepri', 'riau islands', 'batam'],                  'Sumatera'),
+    ('Jambi',                     ARRAY[]::text[],                                          'Sumatera'),
+    ('Sumatera Selatan',          ARRAY['sumsel', 'south sumatra', 'palembang'],            'Sumatera'),
+    ('Kepulauan Bangka Belitung', ARRAY['babel', 'bangka belitung', 'pangkal pinang'],      'Sumatera'),
+    ('Bengkulu',                  ARRAY[]::text[],                                          'Sumatera'),
+    ('Lampung',                   ARRAY['bandar lampung'],                                  'Sumatera'),
+    ('DKI Jakarta',               ARRAY['jakarta', 'jakarta raya', 'dki'],                  'Jawa'),
+    ('Jawa Barat',                ARRAY['jabar', 'west java', 'bandung', 'bogor', 'bekasi', 'depok'], 'Jawa'),
+    ('Banten',                    ARRAY['tangerang', 'serang', 'cilegon'],                  'Jawa'),
+    ('Jawa Tengah',               ARRAY['jateng', 'central java', 'semarang'],              'Jawa'),
+    ('DI Yogyakarta',             ARRAY['yogyakarta', 'jogja', 'jogjakarta', 'diy'],        'Jawa'),
+    ('Jawa Timur',                ARRAY['jatim', 'east java', 'surabaya', 'malang'],        'Jawa'),
+    ('Bali',                      ARRAY['denpasar'],                                        'Bali & Nusa Tenggara'),
+    ('Nusa Tenggara Barat',       ARRAY['ntb', 'mataram', 'lombok'],                        'Bali & Nusa Tenggara'),
+    ('Nusa Tenggara Timur',       ARRAY['ntt', 'kupang'],                                   'Bali & Nusa Tenggara'),
+    ('Kalimantan Barat',          ARRAY['kalbar', 'pontianak'],                             'Kalimantan'),
+    ('Kalimantan Tengah',         ARRAY['kalteng', 'palangkaraya'],                         'Kalimantan'),
+    ('Kalimantan Selatan',        ARRAY['kalsel', 'banjarmasin'],                           'Kalimantan'),
+    ('Kalimantan Timur',          ARRAY['kaltim', 'balikpapan', 'samarinda', 'ikn'],        'Kalimantan'),
+    ('Kalimantan Utara',          ARRAY['kaltara', 'tarakan'],                              'Kalimantan'),
+    ('Sulawesi Utara',            ARRAY['sulut', 'manado'],                                 'Sulawesi'),
+    ('Gorontalo',                 ARRAY[]::text[],                                          'Sulawesi'),
+    ('Sulawesi Tengah',           ARRAY['sulteng', 'palu'],                                 'Sulawesi'),
+    ('Sulawesi Barat',            ARRAY['sulbar', 'mamuju'],                                'Sulawesi'),
+    ('Sulawesi Selatan',          ARRAY['sulsel', 'makassar'],                              'Sulawesi'),
+    ('Sulawesi Tenggara',         ARRAY['sultra', 'kendari'],                               'Sulawesi'),
+    ('Maluku',                    ARRAY['ambon'],                                           'Maluku'),
+    ('Maluku Utara',              ARRAY['malut', 'ternate', 'sofifi'],                      'Maluku'),
+    ('Papua',                     ARRAY['jayapura'],                                        'Papua'),
+    ('Papua Barat',               ARRAY['manokwari'],                                       'Papua'),
+    ('Papua Barat Daya',          ARRAY['sorong'],                                          'Papua'),
+    ('Papua Tengah',              ARRAY['nabire', 'timika'],                                'Papua'),
+    ('Papua Pegunungan',          ARRAY['wamena'],                                          'Papua'),
+    ('Papua Selatan',             ARRAY['merauke'],                                         'Papua')
+) AS v(name, aliases, region)
+JOIN regions r ON r.name = v.region
+ON CONFLICT (name) DO NOTHING;
+
+-- ---------- resolver: teks bebas → nama kanonik (NULL jika tidak dikenal) ----------
+CREATE OR REPLACE FUNCTION resolve_industry(t text) RETURNS text
+LANGUAGE sql STABLE AS $$
+    SELECT i.name
+    FROM industries i
+    WHERE lower(i.name) = lower(btrim(t))
+       OR lower(btrim(t)) = ANY (SELECT lower(a) FROM unnest(i.aliases) a)
+    ORDER BY (lower(i.name) = lower(btrim(t))) DESC, i.id
+    LIMIT 1
+$$;
+
+-- region bisa diisi nama region, alias, nama provinsi / kota (alias provinsi)
+CREATE OR REPLACE FUNCTION resolve_region(t text) RETURNS text
+LANGUAGE sql STABLE AS $$
+    SELECT name FROM (
+        SELECT r.name, 0 AS prio, r.id
+        FROM regions r
+        WHERE lower(r.name) = lower(btrim(t))
+           OR lower(btrim(t)) = ANY (SELECT lower(a) FROM unnest(r.aliases) a)
+        UNION ALL
+        SELECT r.name, 1 AS prio, r.id
+        FROM provinces pv
+        JOIN regions r ON r.id = pv.region_id
+        WHERE lower(pv.name) = lower(btrim(t))
+           OR lower(btrim(t)) = ANY (SELECT lower(a) FROM unnest(pv.aliases) a)
+    ) x
+    ORDER BY prio, id
+    LIMIT 1
+$$;
+
+-- ---------- FK kanonik (NOT VALID: data lama divalidasi oleh cmd/backfill-reference) ----------
+ALTER TABLE customers DROP CONSTRAINT IF EXISTS customers_industry_fkey;
+ALTER TABLE customers
+    ADD CONSTRAINT customers_industry_fkey FOREIGN KEY (industry)
+    REFERENCES industries(name) ON UPDATE CASCADE ON DELETE RESTRICT NOT VALID;
+
+ALTER TABLE customers DROP CONSTRAINT IF EXISTS customers_region_fkey;
+ALTER TABLE customers
+    ADD CONSTRAINT customers_region_fkey FOREIGN KEY (region)
+    REFERENCES regions(name) ON UPDATE CASCADE ON DELETE RESTRICT NOT VALID;
@@ -0,0 +1,13 @@
+-- =====================================================
+--  REFERENTIAL SAFETY: user deactivation + realization owner
+-- =====================================================
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS is_active boolean NOT NULL DEFAULT true,
+    ADD COLUMN IF NOT EXISTS deactivated_at timestamptz;
+
+-- siapa yang input realisasi budget (data lama: NULL)
+ALTER TABLE budget_realization
+    ADD COLUMN IF NOT EXISTS created_by bigint REFERENCES users(id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_budget_realization_created_by ON budget_realization (created_by);
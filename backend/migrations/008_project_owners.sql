@@ -0,0 +1,17 @@
+-- =====================================================
+--  PROJECT OWNERSHIP (sales rep) + CO-OWNERS
+-- =====================================================
+
+ALTER TABLE projects
+    ADD COLUMN IF NOT EXISTS owner_user_id bigint REFERENCES users(id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_projects_owner_user_id ON projects (owner_user_id);
+
+CREATE TABLE IF NOT EXISTS project_co_owners (
+    project_id bigint NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    user_id    bigint NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (project_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_co_owners_user_id ON project_co_owners (user_id);
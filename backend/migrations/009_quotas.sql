@@ -0,0 +1,27 @@
+-- =====================================================
+--  SALES QUOTAS (per user / per division)
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS quotas (
+    id           bigserial PRIMARY KEY,
+    scope        text NOT NULL CHECK (scope IN ('user', 'division')),
+    user_id      bigint REFERENCES users(id) ON DELETE CASCADE,
+    division     text,
+    period_type  text NOT NULL CHECK (period_type IN ('month', 'quarter', 'year')),
+    period_start date NOT NULL,          -- hari pertama bulan / kuartal / tahun
+    revenue_type text NOT NULL DEFAULT 'all'
+        CHECK (revenue_type IN ('all', 'Project Based', 'Recurring', 'New Recurring')),
+    amount       numeric(18,2) NOT NULL CHECK (amount > 0),
+    created_by   bigint REFERENCES users(id) ON DELETE SET NULL,
+    created_at   timestamptz NOT NULL DEFAULT now(),
+    updated_at   timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT quotas_scope_target_check CHECK (
+        (scope = 'user' AND user_id IS NOT NULL AND division IS NULL)
+        OR (scope = 'division' AND division IS NOT NULL AND user_id IS NULL)
+    )
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_quotas_target_period
+    ON quotas (scope, COALESCE(user_id, 0), COALESCE(division, ''), period_type, period_start, revenue_type);
+
+CREATE INDEX IF NOT EXISTS idx_quotas_period_start ON quotas (period_start);
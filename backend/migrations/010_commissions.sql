@@ -0,0 +1,77 @@
+-- =====================================================
+--  SALES COMMISSION (plans, runs, statements)
+-- =====================================================
+
+-- rate dalam persen dari realisasi, per tipe revenue
+CREATE TABLE IF NOT EXISTS commission_plans (
+    id                     bigserial PRIMARY KEY,
+    name                   text NOT NULL UNIQUE,
+    period_type            text NOT NULL DEFAULT 'quarter'
+        CHECK (period_type IN ('month', 'quarter', 'year')),
+    rate_project_based     numeric(7,4) NOT NULL DEFAULT 0 CHECK (rate_project_based >= 0),
+    rate_recurring         numeric(7,4) NOT NULL DEFAULT 0 CHECK (rate_recurring >= 0),
+    rate_new_recurring     numeric(7,4) NOT NULL DEFAULT 0 CHECK (rate_new_recurring >= 0),
+    -- realisasi di atas quota * threshold% dikali accelerator_multiplier
+    accelerator_threshold  numeric(7,2) NOT NULL DEFAULT 100 CHECK (accelerator_threshold > 0),
+    accelerator_multiplier numeric(7,4) NOT NULL DEFAULT 1 CHECK (accelerator_multiplier >= 1),
+    is_active              boolean NOT NULL DEFAULT true,
+    created_by             bigint REFERENCES users(id) ON DELETE SET NULL,
+    created_at             timestamptz NOT NULL DEFAULT now(),
+    updated_at             timestamptz NOT NULL DEFAULT now()
+);
+
+-- tier: attainment >= min_attainment_pct → rate dikali multiplier (tier tertinggi yang lolos)
+CREATE TABLE IF NOT EXISTS commission_plan_tiers (
+    id                 bigserial PRIMARY KEY,
+    plan_id            bigint NOT NULL REFERENCES commission_plans(id) ON DELETE CASCADE,
+    min_attainment_pct numeric(7,2) NOT NULL CHECK (min_attainment_pct >= 0),
+    multiplier         numeric(7,4) NOT NULL CHECK (multiplier >= 0),
+    UNIQUE (plan_id, min_attainment_pct)
+);
+
+CREATE TABLE IF NOT EXISTS commission_runs (
+    id               bigserial PRIMARY KEY,
+    plan_id          bigint NOT NULL REFERENCES commission_plans(id) ON DELETE RESTRICT,
+    period_type      text NOT NULL CHECK (period_type IN ('month', 'quarter', 'year')),
+    period_start     date NOT NULL,
+    period_end       date NOT NULL,  -- exclusive
+    status           text NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'closed')),
+    total_commission numeric(18,2) NOT NULL DEFAULT 0,
+    calculated_at    timestamptz NOT NULL DEFAULT now(),
+    calculated_by    bigint REFERENCES users(id) ON DELETE SET NULL,
+    closed_at        timestamptz,
+    closed_by        bigint REFERENCES users(id) ON DELETE SET NULL,
+    recalculated_at  timestamptz,    -- recalculation otomatis setelah closed
+    UNIQUE (plan_id, period_type, period_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_commission_runs_period ON commission_runs (period_start, period_end);
+
+-- statement = hasil per owner project; closed_amount = snapshot saat run di-close
+CREATE TABLE IF NOT EXISTS commission_statements (
+    id                bigserial PRIMARY KEY,
+    run_id            bigint NOT NULL REFERENCES commission_runs(id) ON DELETE CASCADE,
+    user_id           bigint NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    quota             numeric(18,2) NOT NULL DEFAULT 0,
+    realization       numeric(18,2) NOT NULL DEFAULT 0,
+    attainment_pct    numeric(9,2) NOT NULL DEFAULT 0,
+    tier_multiplier   numeric(7,4) NOT NULL DEFAULT 1,
+    commission_amount numeric(18,2) NOT NULL DEFAULT 0,
+    closed_amount     numeric(18,2),
+    updated_at        timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (run_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS commission_lines (
+    id                bigserial PRIMARY KEY,
+    statement_id      bigint NOT NULL REFERENCES commission_statements(id) ON DELETE CASCADE,
+    project_id        bigint NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    month             date NOT NULL,
+    revenue_type      text NOT NULL,
+    realization       numeric(18,2) NOT NULL,
+    base_rate         numeric(7,4) NOT NULL,
+    accelerated_amount numeric(18,2) NOT NULL DEFAULT 0, -- bagian realisasi yang kena accelerator
+    commission        numeric(18,2) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_commission_lines_statement ON commission_lines (statement_id);
@@ -0,0 +1,60 @@
+-- =====================================================
+--  SPH (SURAT PENAWARAN HARGA) DOCUMENTS
+--  1 dokumen per project, banyak versi; versi released terakhir
+--  menentukan sph_number / sph_release_date di projects
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS sph_documents (
+    id          bigserial PRIMARY KEY,
+    project_id  bigint NOT NULL UNIQUE REFERENCES projects(id) ON DELETE CASCADE,
+    title       text NOT NULL DEFAULT '',
+    currency    text NOT NULL DEFAULT 'IDR',
+    created_by  bigint REFERENCES users(id) ON DELETE SET NULL,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    updated_at  timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS sph_document_versions (
+    id             bigserial PRIMARY KEY,
+    document_id    bigint NOT NULL REFERENCES sph_documents(id) ON DELETE CASCADE,
+    version_no     int NOT NULL,
+    status         text NOT NULL DEFAULT 'draft'
+        CHECK (status IN ('draft', 'released', 'superseded')),
+    valid_until    date,
+    notes          text NOT NULL DEFAULT '',
+    terms          text NOT NULL DEFAULT '',
+    subtotal       numeric(18,2) NOT NULL DEFAULT 0,
+    discount_total numeric(18,2) NOT NULL DEFAULT 0,
+    tax_total      numeric(18,2) NOT NULL DEFAULT 0,
+    grand_total    numeric(18,2) NOT NULL DEFAULT 0,
+    sph_number     text,        -- snapshot nomor saat release
+    released_at    timestamptz,
+    released_by    bigint REFERENCES users(id) ON DELETE SET NULL,
+    created_by     bigint REFERENCES users(id) ON DELETE SET NULL,
+    created_at     timestamptz NOT NULL DEFAULT now(),
+    updated_at     timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (document_id, version_no)
+);
+
+-- hanya satu versi released per dokumen
+CREATE UNIQUE INDEX IF NOT EXISTS uq_sph_versions_released
+    ON sph_document_versions (document_id) WHERE status = 'released';
+
+CREATE TABLE IF NOT EXISTS sph_line_items (
+    id              bigserial PRIMARY KEY,
+    version_id      bigint NOT NULL REFERENCES sph_document_versions(id) ON DELETE CASCADE,
+    line_no         int NOT NULL,
+    product_name    text NOT NULL,
+    description     text NOT NULL DEFAULT '',
+    qty             numeric(14,2) NOT NULL CHECK (qty > 0),
+    unit            text NOT NULL DEFAULT 'unit',
+    unit_price      numeric(18,2) NOT NULL CHECK (unit_price >= 0),
+    discount_pct    numeric(5,2) NOT NULL DEFAULT 0 CHECK (discount_pct >= 0 AND discount_pct <= 100),
+    tax_pct         numeric(5,2) NOT NULL DEFAULT 0 CHECK (tax_pct >= 0 AND tax_pct <= 100),
+    -- computed (server side)
+    gross_amount    numeric(18,2) NOT NULL,
+    discount_amount numeric(18,2) NOT NULL,
+    tax_amount      numeric(18,2) NOT NULL,
+    line_total      numeric(18,2) NOT NULL,
+    UNIQUE (version_id, line_no)
+);
@@ -0,0 +1,46 @@
+-- =====================================================
+--  SPH NUMBER GENERATOR
+--  format per divisi; token: {seq} {divcode} {roman_month} {month} {year} {yy}
+--  counter reset tiap tahun (per division_code)
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS sph_number_formats (
+    division    text PRIMARY KEY,
+    format      text NOT NULL CHECK (position('{seq}' IN format) > 0),
+    seq_padding int NOT NULL DEFAULT 3 CHECK (seq_padding BETWEEN 1 AND 8),
+    updated_by  bigint REFERENCES users(id) ON DELETE SET NULL,
+    updated_at  timestamptz NOT NULL DEFAULT now()
+);
+
+INSERT INTO sph_number_formats (division, format) VALUES
+    ('NetCo',                   '{seq}/SPH/{divcode}/{roman_month}/{year}'),
+    ('Oil Mining & Goverments', '{seq}/SPH/{divcode}/{roman_month}/{year}'),
+    ('IT Solutions',            '{seq}/SPH/{divcode}/{roman_month}/{year}')
+ON CONFLICT (division) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS sph_number_counters (
+    year          int  NOT NULL,
+    division_code text NOT NULL,
+    last_seq      int  NOT NULL DEFAULT 0,
+    PRIMARY KEY (year, division_code)
+);
+
+-- kosong dianggap belum ada nomor
+UPDATE projects SET sph_number = NULL WHERE btrim(sph_number) = '';
+
+-- unique sph_number; data lama yang duplikat harus dibereskan dulu:
+--   SELECT sph_number, array_agg(project_code) FROM projects
+--   WHERE sph_number IS NOT NULL GROUP BY 1 HAVING COUNT(*) > 1;
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM projects
+        WHERE sph_number IS NOT NULL
+        GROUP BY sph_number HAVING COUNT(*) > 1
+    ) THEN
+        RAISE WARNING 'duplicate sph_number found; uq_projects_sph_number not created (fix duplicates and re-run)';
+    ELSE
+        CREATE UNIQUE INDEX IF NOT EXISTS uq_projects_sph_number
+            ON projects (sph_number) WHERE sph_number IS NOT NULL;
+    END IF;
+END $$;
@@ -0,0 +1,58 @@
+-- =====================================================
+--  PRODUCT / SERVICE CATALOG + PRICE BOOKS
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS products (
+    id            bigserial PRIMARY KEY,
+    sku           text NOT NULL UNIQUE,
+    name          text NOT NULL,
+    category      text NOT NULL DEFAULT 'Other',   -- product line (untuk breakdown dashboard)
+    unit          text NOT NULL DEFAULT 'unit',
+    billing_type  text NOT NULL DEFAULT 'one_time' CHECK (billing_type IN ('one_time', 'recurring')),
+    default_price numeric(18,2) NOT NULL DEFAULT 0 CHECK (default_price >= 0),
+    is_active     boolean NOT NULL DEFAULT true,
+    created_at    timestamptz NOT NULL DEFAULT now(),
+    updated_at    timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_products_category ON products (category);
+
+-- price book berlaku untuk divisi dan/atau segmen customer (industry); NULL = semua
+CREATE TABLE IF NOT EXISTS price_books (
+    id          bigserial PRIMARY KEY,
+    name        text NOT NULL UNIQUE,
+    division    text,
+    industry    text REFERENCES industries(name) ON UPDATE CASCADE ON DELETE RESTRICT,
+    valid_from  date NOT NULL,
+    valid_to    date,            -- inklusif; NULL = tanpa batas
+    is_active   boolean NOT NULL DEFAULT true,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    updated_at  timestamptz NOT NULL DEFAULT now(),
+    CHECK (valid_to IS NULL OR valid_to >= valid_from)
+);
+
+CREATE TABLE IF NOT EXISTS price_book_entries (
+    price_book_id bigint NOT NULL REFERENCES price_books(id) ON DELETE CASCADE,
+    product_id    bigint NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    unit_price    numeric(18,2) NOT NULL CHECK (unit_price >= 0),
+    PRIMARY KEY (price_book_id, product_id)
+);
+
+-- item yang dijual di project
+CREATE TABLE IF NOT EXISTS project_line_items (
+    id            bigserial PRIMARY KEY,
+    project_id    bigint NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    product_id    bigint NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    price_book_id bigint REFERENCES price_books(id) ON DELETE SET NULL,  -- sumber harga (NULL = default / manual)
+    qty           numeric(14,2) NOT NULL CHECK (qty > 0),
+    unit_price    numeric(18,2) NOT NULL CHECK (unit_price >= 0),
+    discount_pct  numeric(5,2) NOT NULL DEFAULT 0 CHECK (discount_pct >= 0 AND discount_pct <= 100),
+    amount        numeric(18,2) NOT NULL,     -- qty * unit_price * (1 - discount)
+    notes         text NOT NULL DEFAULT '',
+    created_by    bigint REFERENCES users(id) ON DELETE SET NULL,
+    created_at    timestamptz NOT NULL DEFAULT now(),
+    updated_at    timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_line_items_project ON project_line_items (project_id);
+CREATE INDEX IF NOT EXISTS idx_project_line_items_product ON project_line_items (product_id);
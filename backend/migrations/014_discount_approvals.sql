@@ -0,0 +1,53 @@
+-- =====================================================
+--  DISCOUNT APPROVAL (SPH)
+--  level: manager (1) < director (2); approver level lebih tinggi
+--  boleh approve request level di bawahnya
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS approvers (
+    id         bigserial PRIMARY KEY,
+    user_id    bigint NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    level      text NOT NULL CHECK (level IN ('manager', 'director')),
+    division   text,                 -- NULL = semua divisi
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_approvers_user_level_division
+    ON approvers (user_id, level, COALESCE(division, ''));
+
+-- diskon (max discount_pct line item) > min_discount_pct → butuh approval level tsb
+CREATE TABLE IF NOT EXISTS discount_approval_rules (
+    id               bigserial PRIMARY KEY,
+    division         text,           -- NULL = semua divisi
+    min_discount_pct numeric(5,2) NOT NULL CHECK (min_discount_pct >= 0 AND min_discount_pct < 100),
+    level            text NOT NULL CHECK (level IN ('manager', 'director')),
+    is_active        boolean NOT NULL DEFAULT true,
+    created_at       timestamptz NOT NULL DEFAULT now(),
+    updated_at       timestamptz NOT NULL DEFAULT now()
+);
+
+INSERT INTO discount_approval_rules (division, min_discount_pct, level)
+SELECT NULL, v.pct, v.level
+FROM (VALUES (10.00, 'manager'), (20.00, 'director')) AS v(pct, level)
+WHERE NOT EXISTS (SELECT 1 FROM discount_approval_rules);
+
+CREATE TABLE IF NOT EXISTS sph_approval_requests (
+    id           bigserial PRIMARY KEY,
+    version_id   bigint NOT NULL REFERENCES sph_document_versions(id) ON DELETE CASCADE,
+    rule_id      bigint REFERENCES discount_approval_rules(id) ON DELETE SET NULL,
+    level        text NOT NULL CHECK (level IN ('manager', 'director')),
+    discount_pct numeric(5,2) NOT NULL,   -- diskon versi saat request dibuat
+    status       text NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled')),
+    requested_by bigint REFERENCES users(id) ON DELETE SET NULL,
+    decided_by   bigint REFERENCES users(id) ON DELETE SET NULL,
+    decided_at   timestamptz,
+    note         text NOT NULL DEFAULT '',
+    created_at   timestamptz NOT NULL DEFAULT now()
+);
+
+-- satu request aktif per level per versi
+CREATE UNIQUE INDEX IF NOT EXISTS uq_sph_approval_active
+    ON sph_approval_requests (version_id, level) WHERE status <> 'cancelled';
+
+CREATE INDEX IF NOT EXISTS idx_sph_approval_status ON sph_approval_requests (status);
@@ -0,0 +1,49 @@
+-- =====================================================
+--  LEADS (sebelum ada project)
+--  status: new → contacted → qualified / unqualified → converted
+--  convert → customer (buat / link) + project via createProjectTx
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS leads (
+    id                   bigserial PRIMARY KEY,
+    name                 text NOT NULL,       -- nama kontak
+    company_name         text,
+    email                text,
+    phone                text,
+    title                text,                -- jabatan kontak
+    source               text NOT NULL,       -- event | inbound | referral | ...
+    source_detail        text,                -- mis. nama event / campaign
+    division             text NOT NULL,
+    owner_user_id        bigint REFERENCES users(id) ON DELETE RESTRICT,
+    status               text NOT NULL DEFAULT 'new'
+                         CHECK (status IN ('new', 'contacted', 'qualified', 'unqualified', 'converted')),
+
+    -- kualifikasi (BANT)
+    has_budget           boolean,
+    has_authority        boolean,
+    has_need             boolean,
+    timeline             text,
+    estimated_value      numeric(18,2),
+    qualification_note   text,
+    qualified_at         timestamptz,
+
+    customer_id          bigint REFERENCES customers(id) ON DELETE RESTRICT,
+    converted_project_id bigint REFERENCES projects(id) ON DELETE SET NULL,
+    converted_at         timestamptz,
+
+    created_by           bigint REFERENCES users(id) ON DELETE SET NULL,
+    created_at           timestamptz NOT NULL DEFAULT now(),
+    updated_at           timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_leads_division_status ON leads (division, status);
+CREATE INDEX IF NOT EXISTS idx_leads_owner_user_id ON leads (owner_user_id);
+CREATE INDEX IF NOT EXISTS idx_leads_customer_id ON leads (customer_id);
+
+-- atribusi: project hasil convert simpan lead + source-nya
+-- (lead_source tetap ada walaupun lead dihapus)
+ALTER TABLE projects
+    ADD COLUMN IF NOT EXISTS lead_id bigint REFERENCES leads(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS lead_source text;
+
+CREATE INDEX IF NOT EXISTS idx_projects_lead_source ON projects (lead_source);
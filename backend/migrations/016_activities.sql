@@ -0,0 +1,47 @@
+-- =====================================================
+--  ACTIVITIES (call / meeting / visit / email) + NEXT STEP
+--  activity menempel ke project ATAU customer (customer project
+--  diambil dari projects.customer_id, supaya ikut kalau project pindah customer)
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS activities (
+    id                 bigserial PRIMARY KEY,
+    type               text NOT NULL CHECK (type IN ('call', 'meeting', 'visit', 'email', 'other')),
+    activity_date      timestamptz NOT NULL,
+    project_id         bigint REFERENCES projects(id) ON DELETE CASCADE,
+    customer_id        bigint REFERENCES customers(id) ON DELETE RESTRICT,
+    division           text NOT NULL,   -- ACL (divisi project / divisi pembuat)
+    subject            text NOT NULL,
+    notes              text,
+    outcome            text,
+
+    next_step          text,
+    next_step_due      date,
+    next_step_owner_id bigint REFERENCES users(id) ON DELETE RESTRICT,
+    next_step_done_at  timestamptz,
+
+    created_by         bigint REFERENCES users(id) ON DELETE SET NULL,
+    created_at         timestamptz NOT NULL DEFAULT now(),
+    updated_at         timestamptz NOT NULL DEFAULT now(),
+
+    CONSTRAINT activities_target_check CHECK (num_nonnulls(project_id, customer_id) = 1),
+    CONSTRAINT activities_next_step_check CHECK (next_step_due IS NULL OR next_step IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_activities_project_date ON activities (project_id, activity_date DESC);
+CREATE INDEX IF NOT EXISTS idx_activities_customer_date ON activities (customer_id, activity_date DESC);
+CREATE INDEX IF NOT EXISTS idx_activities_open_next_steps
+    ON activities (next_step_owner_id, next_step_due)
+    WHERE next_step_due IS NOT NULL AND next_step_done_at IS NULL;
+
+-- peserta: contact customer, user internal, atau nama bebas
+CREATE TABLE IF NOT EXISTS activity_participants (
+    id          bigserial PRIMARY KEY,
+    activity_id bigint NOT NULL REFERENCES activities(id) ON DELETE CASCADE,
+    contact_id  bigint REFERENCES customer_contacts(id) ON DELETE CASCADE,
+    user_id     bigint REFERENCES users(id) ON DELETE CASCADE,
+    name        text,
+    CONSTRAINT activity_participants_ref_check CHECK (num_nonnulls(contact_id, user_id, name) = 1)
+);
+
+CREATE INDEX IF NOT EXISTS idx_activity_participants_activity_id ON activity_participants (activity_id);
@@ -0,0 +1,27 @@
+-- =====================================================
+--  PROJECT COMMENTS (thread) + @MENTIONS
+--  hapus = soft delete supaya reply tetap punya parent
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS project_comments (
+    id         bigserial PRIMARY KEY,
+    project_id bigint NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    parent_id  bigint REFERENCES project_comments(id) ON DELETE CASCADE,
+    author_id  bigint REFERENCES users(id) ON DELETE SET NULL,
+    body       text NOT NULL,
+    edited_at  timestamptz,
+    deleted_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_comments_project_id ON project_comments (project_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_project_comments_parent_id ON project_comments (parent_id);
+
+CREATE TABLE IF NOT EXISTS project_comment_mentions (
+    comment_id bigint NOT NULL REFERENCES project_comments(id) ON DELETE CASCADE,
+    user_id    bigint NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (comment_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_comment_mentions_user_id ON project_comment_mentions (user_id);
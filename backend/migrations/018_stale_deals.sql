@@ -0,0 +1,61 @@
+-- =====================================================
+--  STALE DEAL DETECTION
+--  rule_type:
+--    stage_idle         → sales_stage tidak berubah > N hari (per stage)
+--    sph_open           → SPH sudah release tapi sph_status masih Open > N hari
+--    revenue_unrealized → bulan revenue plan sudah lewat (+N hari) tapi realisasi 0
+--  division NULL = default semua divisi; rule divisi override default
+-- =====================================================
+
+ALTER TABLE projects
+    ADD COLUMN IF NOT EXISTS stage_changed_at timestamptz;
+
+UPDATE projects
+SET stage_changed_at = COALESCE(updated_at, created_at, now())
+WHERE stage_changed_at IS NULL;
+
+ALTER TABLE projects
+    ALTER COLUMN stage_changed_at SET DEFAULT now(),
+    ALTER COLUMN stage_changed_at SET NOT NULL;
+
+CREATE TABLE IF NOT EXISTS stale_rules (
+    id             bigserial PRIMARY KEY,
+    division       text,
+    rule_type      text NOT NULL CHECK (rule_type IN ('stage_idle', 'sph_open', 'revenue_unrealized')),
+    sales_stage    int,                 -- wajib untuk stage_idle
+    threshold_days int NOT NULL CHECK (threshold_days >= 0),
+    is_active      boolean NOT NULL DEFAULT true,
+    created_at     timestamptz NOT NULL DEFAULT now(),
+    updated_at     timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT stale_rules_stage_check CHECK ((rule_type = 'stage_idle') = (sales_stage IS NOT NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_stale_rules_scope
+    ON stale_rules (COALESCE(division, ''), rule_type, COALESCE(sales_stage, 0));
+
+INSERT INTO stale_rules (division, rule_type, sales_stage, threshold_days)
+SELECT NULL, v.rule_type, v.stage, v.days
+FROM (VALUES
+    ('stage_idle', 1, 30),
+    ('stage_idle', 2, 30),
+    ('stage_idle', 3, 45),
+    ('stage_idle', 4, 45),
+    ('stage_idle', 5, 30),
+    ('sph_open', NULL, 30),
+    ('revenue_unrealized', NULL, 15)
+) AS v(rule_type, stage, days)
+WHERE NOT EXISTS (SELECT 1 FROM stale_rules);
+
+-- hasil evaluasi terakhir; row hilang saat project tidak stale lagi
+-- (notifikasi hanya saat flag pertama kali muncul)
+CREATE TABLE IF NOT EXISTS stale_project_flags (
+    project_id        bigint NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    rule_type         text NOT NULL,
+    rule_id           bigint REFERENCES stale_rules(id) ON DELETE SET NULL,
+    threshold_days    int NOT NULL,
+    days_stale        int NOT NULL,
+    detail            text NOT NULL,
+    first_flagged_at  timestamptz NOT NULL DEFAULT now(),
+    last_evaluated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (project_id, rule_type)
+);
@@ -0,0 +1,18 @@
+-- =====================================================
+--  WIN/LOSS ANALYTICS
+--  sph_status_changed_at = kapan sph_status terakhir berubah
+--  (untuk Win/Loss/Drop = tanggal keputusan)
+-- =====================================================
+
+ALTER TABLE projects
+    ADD COLUMN IF NOT EXISTS sph_status_changed_at timestamptz;
+
+-- data lama: perkiraan terbaik = updated_at
+UPDATE projects
+SET sph_status_changed_at = COALESCE(updated_at, created_at)
+WHERE sph_status_changed_at IS NULL
+  AND sph_status IS NOT NULL AND sph_status <> '';
+
+CREATE INDEX IF NOT EXISTS idx_projects_sph_decision
+    ON projects (sph_status, sph_status_changed_at)
+    WHERE sph_status IN ('Win', 'Loss', 'Drop');
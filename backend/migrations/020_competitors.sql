@@ -0,0 +1,35 @@
+-- =====================================================
+--  COMPETITORS + PROJECT COMPETITORS
+--  winning_competitor_id diisi saat sph_status = Loss
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS competitors (
+    id         bigserial PRIMARY KEY,
+    name       text NOT NULL,
+    website    text,
+    notes      text,
+    is_active  boolean NOT NULL DEFAULT true,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_competitors_name ON competitors (lower(name));
+
+CREATE TABLE IF NOT EXISTS project_competitors (
+    id               bigserial PRIMARY KEY,
+    project_id       bigint NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    competitor_id    bigint NOT NULL REFERENCES competitors(id) ON DELETE RESTRICT,
+    competitor_price numeric(18,2) CHECK (competitor_price >= 0),
+    is_incumbent     boolean NOT NULL DEFAULT false,
+    notes            text,
+    created_at       timestamptz NOT NULL DEFAULT now(),
+    updated_at       timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (project_id, competitor_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_competitors_competitor_id ON project_competitors (competitor_id);
+
+ALTER TABLE projects
+    ADD COLUMN IF NOT EXISTS winning_competitor_id bigint REFERENCES competitors(id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_projects_winning_competitor_id ON projects (winning_competitor_id);
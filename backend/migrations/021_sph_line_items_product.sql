@@ -0,0 +1,11 @@
+-- =====================================================
+--  SPH LINE ITEMS ↔ PRODUCT CATALOG
+--  item SPH bisa menunjuk produk katalog (nama / unit snapshot tetap disimpan);
+--  versi SPH bisa dibangun dari project_line_items supaya diskon katalog
+--  ikut dievaluasi discount rules
+-- =====================================================
+
+ALTER TABLE sph_line_items
+    ADD COLUMN IF NOT EXISTS product_id bigint REFERENCES products(id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_sph_line_items_product_id ON sph_line_items (product_id);
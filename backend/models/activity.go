@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+type ActivityParticipant struct {
+	ContactID *int64  `json:"contact_id,omitempty"`
+	UserID    *int64  `json:"user_id,omitempty"`
+	Name      *string `json:"name,omitempty"` // nama contact / username / nama bebas
+	Kind      string  `json:"kind"`           // contact | user | external
+}
+
+type Activity struct {
+	ID              int64                 `json:"id"`
+	Type            string                `json:"type"`
+	ActivityDate    time.Time             `json:"activity_date"`
+	ProjectID       *int64                `json:"project_id,omitempty"`
+	ProjectCode     *string               `json:"project_code,omitempty"`
+	CustomerID      *int64                `json:"customer_id,omitempty"`
+	CustomerName    *string               `json:"customer_name,omitempty"`
+	Division        string                `json:"division"`
+	Subject         string                `json:"subject"`
+	Notes           *string               `json:"notes,omitempty"`
+	Outcome         *string               `json:"outcome,omitempty"`
+	NextStep        *string               `json:"next_step,omitempty"`
+	NextStepDue     *time.Time            `json:"next_step_due,omitempty"`
+	NextStepOwnerID *int64                `json:"next_step_owner_id,omitempty"`
+	NextStepOwner   *string               `json:"next_step_owner,omitempty"`
+	NextStepDoneAt  *time.Time            `json:"next_step_done_at,omitempty"`
+	DaysOverdue     *int                  `json:"days_overdue,omitempty"` // hanya di /activities/overdue
+	CreatedBy       *int64                `json:"created_by,omitempty"`
+	CreatedByName   *string               `json:"created_by_name,omitempty"`
+	Participants    []ActivityParticipant `json:"participants"`
+	CreatedAt       time.Time             `json:"created_at"`
+	UpdatedAt       time.Time             `json:"updated_at"`
+}
+
+type ActivityParticipantRequest struct {
+	ContactID *int64  `json:"contact_id"`
+	UserID    *int64  `json:"user_id"`
+	Name      *string `json:"name"`
+}
+
+// ActivityRequest → project_id ATAU customer_id
+type ActivityRequest struct {
+	Type            string                       `json:"type" binding:"required"`
+	ActivityDate    string                       `json:"activity_date" binding:"required"` // RFC3339 / YYYY-MM-DD
+	ProjectID       *int64                       `json:"project_id"`
+	CustomerID      *int64                       `json:"customer_id"`
+	Division        string                       `json:"division"` // activity customer (admin)
+	Subject         string                       `json:"subject" binding:"required"`
+	Notes           *string                      `json:"notes"`
+	Outcome         *string                      `json:"outcome"`
+	NextStep        *string                      `json:"next_step"`
+	NextStepDue     *string                      `json:"next_step_due"` // YYYY-MM-DD
+	NextStepOwnerID *int64                       `json:"next_step_owner_id"`
+	Participants    []ActivityParticipantRequest `json:"participants"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+type Approver struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Username  string    `json:"username"`
+	Level     string    `json:"level"`              // manager | director
+	Division  *string   `json:"division,omitempty"` // nil = semua divisi
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ApproverRequest struct {
+	UserID   int64   `json:"user_id" binding:"required"`
+	Level    string  `json:"level" binding:"required"`
+	Division *string `json:"division"`
+}
+
+type DiscountApprovalRule struct {
+	ID             int64     `json:"id"`
+	Division       *string   `json:"division,omitempty"`
+	MinDiscountPct float64   `json:"min_discount_pct"`
+	Level          string    `json:"level"`
+	IsActive       bool      `json:"is_active"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type DiscountApprovalRuleRequest struct {
+	Division       *string `json:"division"`
+	MinDiscountPct float64 `json:"min_discount_pct"`
+	Level          string  `json:"level" binding:"required"`
+	IsActive       *bool   `json:"is_active"`
+}
+
+type SPHApprovalRequest struct {
+	ID            int64      `json:"id"`
+	VersionID     int64      `json:"version_id"`
+	VersionNo     int        `json:"version_no"`
+	ProjectID     int64      `json:"project_id"`
+	ProjectCode   string     `json:"project_code"`
+	Division      string     `json:"division"`
+	RuleID        *int64     `json:"rule_id,omitempty"`
+	Level         string     `json:"level"`
+	DiscountPct   float64    `json:"discount_pct"`
+	Status        string     `json:"status"` // pending | approved | rejected | cancelled
+	RequestedBy   *int64     `json:"requested_by,omitempty"`
+	RequestedName *string    `json:"requested_by_name,omitempty"`
+	DecidedBy     *int64     `json:"decided_by,omitempty"`
+	DecidedName   *string    `json:"decided_by_name,omitempty"`
+	DecidedAt     *time.Time `json:"decided_at,omitempty"`
+	Note          string     `json:"note"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type DecideApprovalRequest struct {
+	Note string `json:"note"`
+}
@@ -41,3 +41,49 @@ type AddRealizationRequest struct {
 	Amount   float64 `json:"amount" binding:"required"`
 	Note     string  `json:"note"`
 }
+
+type CopyBudgetRequest struct {
+	StartMonth string `json:"start_month" binding:"required"` // YYYY-MM
+	EndMonth   string `json:"end_month" binding:"required"`   // YYYY-MM
+	Overwrite  bool   `json:"overwrite"`                      // timpa amount budget yang sudah ada
+}
+
+type BulkBudgetItem struct {
+	Month        string  `json:"month"` // YYYY-MM
+	BudgetAmount float64 `json:"budget_amount"`
+}
+
+type BulkUpdateBudgetRequest struct {
+	Division string           `json:"division" binding:"required"`
+	Year     int              `json:"year" binding:"required"`
+	Items    []BulkBudgetItem `json:"items" binding:"required"`
+}
+
+// BudgetRollupRow = satu periode (bulan / quarter / YTD) budget vs realisasi,
+// plus kolom pembanding tahun sebelumnya.
+type BudgetRollupRow struct {
+	Period            string  `json:"period"`
+	Budget            float64 `json:"budget"`
+	Realization       float64 `json:"realization"`
+	Remaining         float64 `json:"remaining"`
+	Achievement       float64 `json:"achievement"`
+	PriorBudget       float64 `json:"prior_budget"`
+	PriorRealization  float64 `json:"prior_realization"`
+	PriorAchievement  float64 `json:"prior_achievement"`
+	RealizationGrowth float64 `json:"realization_growth_pct"`
+}
+
+type BudgetRollupDivision struct {
+	Division string            `json:"division"`
+	Periods  []BudgetRollupRow `json:"periods"`
+	Subtotal BudgetRollupRow   `json:"subtotal"`
+}
+
+type BudgetRollupResponse struct {
+	Year         int                    `json:"year"`
+	Granularity  string                 `json:"granularity"`
+	ThroughMonth int                    `json:"through_month"`
+	Divisions    []BudgetRollupDivision `json:"divisions"`
+	Totals       []BudgetRollupRow      `json:"totals"`
+	GrandTotal   BudgetRollupRow        `json:"grand_total"`
+}
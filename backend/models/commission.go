@@ -0,0 +1,81 @@
+package models
+
+import "time"
+
+type CommissionTier struct {
+	MinAttainmentPct float64 `json:"min_attainment_pct"`
+	Multiplier       float64 `json:"multiplier"`
+}
+
+type CommissionPlan struct {
+	ID                    int64            `json:"id"`
+	Name                  string           `json:"name"`
+	PeriodType            string           `json:"period_type"`
+	RateProjectBased      float64          `json:"rate_project_based"` // persen
+	RateRecurring         float64          `json:"rate_recurring"`
+	RateNewRecurring      float64          `json:"rate_new_recurring"`
+	AcceleratorThreshold  float64          `json:"accelerator_threshold"` // attainment %
+	AcceleratorMultiplier float64          `json:"accelerator_multiplier"`
+	IsActive              bool             `json:"is_active"`
+	Tiers                 []CommissionTier `json:"tiers"`
+	CreatedAt             time.Time        `json:"created_at"`
+	UpdatedAt             time.Time        `json:"updated_at"`
+}
+
+type CommissionPlanRequest struct {
+	Name                  string           `json:"name" binding:"required"`
+	PeriodType            string           `json:"period_type"`
+	RateProjectBased      float64          `json:"rate_project_based"`
+	RateRecurring         float64          `json:"rate_recurring"`
+	RateNewRecurring      float64          `json:"rate_new_recurring"`
+	AcceleratorThreshold  float64          `json:"accelerator_threshold"`
+	AcceleratorMultiplier float64          `json:"accelerator_multiplier"`
+	IsActive              *bool            `json:"is_active"`
+	Tiers                 []CommissionTier `json:"tiers"`
+}
+
+type CommissionRunRequest struct {
+	PlanID int64  `json:"plan_id" binding:"required"`
+	Period string `json:"period" binding:"required"` // sesuai period_type plan
+}
+
+type CommissionRun struct {
+	ID              int64                 `json:"id"`
+	PlanID          int64                 `json:"plan_id"`
+	PlanName        string                `json:"plan_name"`
+	PeriodType      string                `json:"period_type"`
+	Period          string                `json:"period"`
+	Status          string                `json:"status"` // draft | closed
+	TotalCommission float64               `json:"total_commission"`
+	CalculatedAt    time.Time             `json:"calculated_at"`
+	ClosedAt        *time.Time            `json:"closed_at,omitempty"`
+	RecalculatedAt  *time.Time            `json:"recalculated_at,omitempty"`
+	Statements      []CommissionStatement `json:"statements,omitempty"`
+}
+
+type CommissionStatement struct {
+	ID               int64            `json:"id"`
+	RunID            int64            `json:"run_id"`
+	UserID           int64            `json:"user_id"`
+	Username         string           `json:"username"`
+	Division         string           `json:"division"`
+	Quota            float64          `json:"quota"`
+	Realization      float64          `json:"realization"`
+	AttainmentPct    float64          `json:"attainment_pct"`
+	TierMultiplier   float64          `json:"tier_multiplier"`
+	CommissionAmount float64          `json:"commission_amount"`
+	ClosedAmount     *float64         `json:"closed_amount,omitempty"`
+	Adjustment       float64          `json:"adjustment"` // commission_amount - closed_amount
+	Lines            []CommissionLine `json:"lines,omitempty"`
+}
+
+type CommissionLine struct {
+	ProjectID         int64   `json:"project_id"`
+	ProjectCode       string  `json:"project_code"`
+	Month             string  `json:"month"` // YYYY-MM
+	RevenueType       string  `json:"revenue_type"`
+	Realization       float64 `json:"realization"`
+	BaseRate          float64 `json:"base_rate"`
+	AcceleratedAmount float64 `json:"accelerated_amount"`
+	Commission        float64 `json:"commission"`
+}
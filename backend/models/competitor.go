@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+type Competitor struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Website   *string   `json:"website,omitempty"`
+	Notes     *string   `json:"notes,omitempty"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CompetitorRequest struct {
+	Name     string  `json:"name" binding:"required"`
+	Website  *string `json:"website"`
+	Notes    *string `json:"notes"`
+	IsActive *bool   `json:"is_active"`
+}
+
+// ProjectCompetitor = kompetitor yang ikut di satu opportunity
+type ProjectCompetitor struct {
+	CompetitorID    int64     `json:"competitor_id"`
+	Name            string    `json:"name"`
+	CompetitorPrice *float64  `json:"competitor_price,omitempty"`
+	IsIncumbent     bool      `json:"is_incumbent"`
+	IsWinner        bool      `json:"is_winner"` // pemenang saat project Loss
+	Notes           *string   `json:"notes,omitempty"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type ProjectCompetitorRequest struct {
+	CompetitorPrice *float64 `json:"competitor_price"`
+	IsIncumbent     *bool    `json:"is_incumbent"`
+	Notes           *string  `json:"notes"`
+}
+
+// CompetitorReportRow = head-to-head vs satu kompetitor (deal yang sudah diputus)
+type CompetitorReportRow struct {
+	CompetitorID       int64    `json:"competitor_id"`
+	Name               string   `json:"name"`
+	Engaged            int64    `json:"engaged"` // semua project yang diikuti
+	Incumbent          int64    `json:"incumbent"`
+	Won                int64    `json:"won"`          // kita menang
+	LostToThem         int64    `json:"lost_to_them"` // Loss, pemenang = kompetitor ini
+	LostToOthers       int64    `json:"lost_to_others"`
+	Dropped            int64    `json:"dropped"`
+	Open               int64    `json:"open"`
+	HeadToHeadWinRate  float64  `json:"head_to_head_win_rate"` // won / (won + lost_to_them) (%)
+	WinRate            float64  `json:"win_rate"`              // won / (won + semua loss + drop) (%)
+	WonValue           float64  `json:"won_value"`
+	LostToThemValue    float64  `json:"lost_to_them_value"`
+	AvgCompetitorPrice *float64 `json:"avg_competitor_price"`
+	AvgPriceDeltaPct   *float64 `json:"avg_price_delta_pct"` // (harga kompetitor - nilai kita) / nilai kita
+}
+
+type CompetitorReportResponse struct {
+	Division    string                `json:"division,omitempty"`
+	From        *string               `json:"from,omitempty"`
+	To          *string               `json:"to,omitempty"`
+	Competitors []CompetitorReportRow `json:"competitors"`
+}
@@ -7,6 +7,47 @@ type Customer struct {
 	Name      string    `json:"name"`
 	Industry  string    `json:"industry"`
 	Region    string    `json:"region"`
+	ParentID  *int64    `json:"parent_id,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+// CustomerDuplicate = pasangan customer dengan nama (ter-normalisasi) mirip
+type CustomerDuplicate struct {
+	CustomerA  Customer `json:"customer_a"`
+	CustomerB  Customer `json:"customer_b"`
+	Normalized string   `json:"normalized_name"`
+	Similarity float64  `json:"similarity"`
+	Exact      bool     `json:"exact"`
+}
+
+type MergeCustomersRequest struct {
+	SourceIDs []int64 `json:"source_ids" binding:"required"`
+}
+
+type CustomerMergeHistory struct {
+	ID             int64     `json:"id"`
+	TargetID       int64     `json:"target_id"`
+	SourceID       int64     `json:"source_id"`
+	SourceName     string    `json:"source_name"`
+	SourceIndustry *string   `json:"source_industry,omitempty"`
+	SourceRegion   *string   `json:"source_region,omitempty"`
+	ProjectsMoved  int       `json:"projects_moved"`
+	ContactsMoved  int       `json:"contacts_moved"`
+	ChildrenMoved  int       `json:"children_moved"`
+	MergedBy       *int64    `json:"merged_by,omitempty"`
+	MergedByName   *string   `json:"merged_by_name,omitempty"`
+	MergedAt       time.Time `json:"merged_at"`
+}
+
+// CustomerListItem = row di GET /customers (+ ownership & agregat project)
+type CustomerListItem struct {
+	Customer
+	Divisions    []string `json:"divisions"`
+	ProjectCount int64    `json:"project_count"`
+	TotalRevenue float64  `json:"total_revenue"`
+}
+
+type SetCustomerDivisionsRequest struct {
+	Divisions []string `json:"divisions"`
+}
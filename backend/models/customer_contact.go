@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+type CustomerContact struct {
+	ID         int64     `json:"id"`
+	CustomerID int64     `json:"customer_id"`
+	Name       string    `json:"name"`
+	Title      *string   `json:"title,omitempty"`
+	Email      *string   `json:"email,omitempty"`
+	Phone      *string   `json:"phone,omitempty"`
+	BuyingRole *string   `json:"buying_role,omitempty"` // Decision Maker, Influencer, ...
+	IsPrimary  bool      `json:"is_primary"`
+	Notes      *string   `json:"notes,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type CustomerContactRequest struct {
+	Name       *string `json:"name"`
+	Title      *string `json:"title"`
+	Email      *string `json:"email"`
+	Phone      *string `json:"phone"`
+	BuyingRole *string `json:"buying_role"`
+	IsPrimary  *bool   `json:"is_primary"`
+	Notes      *string `json:"notes"`
+}
+
+// ProjectStakeholder = contact customer yang terlibat di project
+type ProjectStakeholder struct {
+	ContactID    int64   `json:"contact_id"`
+	CustomerID   int64   `json:"customer_id"`
+	CustomerName string  `json:"customer_name"`
+	Name         string  `json:"name"`
+	Title        *string `json:"title,omitempty"`
+	Email        *string `json:"email,omitempty"`
+	Phone        *string `json:"phone,omitempty"`
+	BuyingRole   *string `json:"buying_role,omitempty"`
+	IsPrimary    bool    `json:"is_primary"`
+	Note         *string `json:"note,omitempty"`
+}
@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// ======================================================
+// CUSTOMER 360 SUMMARY
+// ======================================================
+
+type CustomerSummaryProject struct {
+	ID                      int64   `json:"id"`
+	ProjectCode             string  `json:"project_code"`
+	Description             string  `json:"description"`
+	Division                string  `json:"division"`
+	Status                  string  `json:"status"`
+	ProjectType             string  `json:"project_type"`
+	SalesStage              int     `json:"sales_stage"`
+	SPHStatus               *string `json:"sph_status,omitempty"`
+	SPHStatusReasonCategory *string `json:"sph_status_reason_category,omitempty"`
+	TargetRevenue           float64 `json:"target_revenue"`
+	Realization             float64 `json:"realization"`
+}
+
+type CustomerSummaryGroup struct {
+	Key           string                   `json:"key"`
+	Count         int                      `json:"count"`
+	TargetRevenue float64                  `json:"target_revenue"`
+	Realization   float64                  `json:"realization"`
+	Projects      []CustomerSummaryProject `json:"projects"`
+}
+
+type CustomerRevenueTotals struct {
+	TargetRevenue  float64 `json:"target_revenue"`
+	Realization    float64 `json:"realization"`
+	AchievementPct float64 `json:"achievement_pct"`
+}
+
+type CustomerWinLossReason struct {
+	SPHStatus string `json:"sph_status"`
+	Category  string `json:"category"`
+	Count     int    `json:"count"`
+}
+
+type CustomerWinLoss struct {
+	Open    int                     `json:"open"`
+	Win     int                     `json:"win"`
+	Hold    int                     `json:"hold"`
+	Loss    int                     `json:"loss"`
+	Drop    int                     `json:"drop"`
+	WinRate float64                 `json:"win_rate"` // win / (win+loss+drop)
+	Reasons []CustomerWinLossReason `json:"reasons"`
+}
+
+type CustomerOpenPostPO struct {
+	ProjectID   int64      `json:"project_id"`
+	ProjectCode string     `json:"project_code"`
+	Stage       int        `json:"stage"`
+	Status      string     `json:"status"`
+	Date        *time.Time `json:"date,omitempty"`
+	Note        *string    `json:"note,omitempty"`
+}
+
+type CustomerTimelinePoint struct {
+	Month         string  `json:"month"` // YYYY-MM
+	TargetRevenue float64 `json:"target_revenue"`
+	Realization   float64 `json:"realization"`
+}
+
+type CustomerSummaryResponse struct {
+	Customer   Customer                `json:"customer"`
+	Division   string                  `json:"division,omitempty"` // filter ACL yang dipakai
+	ByStatus   []CustomerSummaryGroup  `json:"by_status"`
+	ByStage    []CustomerSummaryGroup  `json:"by_stage"`
+	Lifetime   CustomerRevenueTotals   `json:"lifetime"`
+	YTD        CustomerRevenueTotals   `json:"ytd"`
+	Year       int                     `json:"year"`
+	WinLoss    CustomerWinLoss         `json:"win_loss"`
+	OpenPostPO []CustomerOpenPostPO    `json:"open_postpo"`
+	Timeline   []CustomerTimelinePoint `json:"timeline"`
+}
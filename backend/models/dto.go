@@ -18,5 +18,7 @@ type CreateProjectRequest struct {
 	SphNumber               *string           `json:"sph_number"`
 	SPHStatusReasonCategory *string           `json:"sph_status_reason_category,omitempty"`
 	SPHStatusReasonNote     *string           `json:"sph_status_reason_note,omitempty"`
+	WinningCompetitorID     *int64            `json:"winning_competitor_id,omitempty"` // hanya untuk Loss
 	RevenuePlans            []RevenuePlanItem `json:"revenue_plans"`
+	LeadSource              *string           `json:"lead_source,omitempty"` // create saja; atribusi tanpa lead
 }
@@ -0,0 +1,91 @@
+package models
+
+import "time"
+
+type Lead struct {
+	ID                 int64      `json:"id"`
+	Name               string     `json:"name"`
+	CompanyName        *string    `json:"company_name,omitempty"`
+	Email              *string    `json:"email,omitempty"`
+	Phone              *string    `json:"phone,omitempty"`
+	Title              *string    `json:"title,omitempty"`
+	Source             string     `json:"source"`
+	SourceDetail       *string    `json:"source_detail,omitempty"`
+	Division           string     `json:"division"`
+	OwnerUserID        *int64     `json:"owner_user_id,omitempty"`
+	OwnerName          *string    `json:"owner_name,omitempty"`
+	Status             string     `json:"status"`
+	HasBudget          *bool      `json:"has_budget,omitempty"`
+	HasAuthority       *bool      `json:"has_authority,omitempty"`
+	HasNeed            *bool      `json:"has_need,omitempty"`
+	Timeline           *string    `json:"timeline,omitempty"`
+	EstimatedValue     *float64   `json:"estimated_value,omitempty"`
+	QualificationNote  *string    `json:"qualification_note,omitempty"`
+	QualifiedAt        *time.Time `json:"qualified_at,omitempty"`
+	CustomerID         *int64     `json:"customer_id,omitempty"`
+	CustomerName       *string    `json:"customer_name,omitempty"`
+	ConvertedProjectID *int64     `json:"converted_project_id,omitempty"`
+	ConvertedAt        *time.Time `json:"converted_at,omitempty"`
+	CreatedBy          *int64     `json:"created_by,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+type LeadRequest struct {
+	Name           string   `json:"name" binding:"required"`
+	CompanyName    *string  `json:"company_name"`
+	Email          *string  `json:"email"`
+	Phone          *string  `json:"phone"`
+	Title          *string  `json:"title"`
+	Source         string   `json:"source" binding:"required"`
+	SourceDetail   *string  `json:"source_detail"`
+	Division       string   `json:"division"`
+	OwnerUserID    *int64   `json:"owner_user_id"`
+	Status         *string  `json:"status"` // new | contacted (kualifikasi lewat /qualify)
+	EstimatedValue *float64 `json:"estimated_value"`
+}
+
+type AssignLeadRequest struct {
+	OwnerUserID int64 `json:"owner_user_id" binding:"required"`
+}
+
+type QualifyLeadRequest struct {
+	Qualified      *bool    `json:"qualified" binding:"required"`
+	HasBudget      *bool    `json:"has_budget"`
+	HasAuthority   *bool    `json:"has_authority"`
+	HasNeed        *bool    `json:"has_need"`
+	Timeline       *string  `json:"timeline"`
+	EstimatedValue *float64 `json:"estimated_value"`
+	Note           *string  `json:"note"` // wajib kalau unqualified
+}
+
+// ConvertLeadRequest → customer_id (link) atau customer baru dari company_name lead
+type ConvertLeadRequest struct {
+	CustomerID   *int64               `json:"customer_id"`
+	CustomerName *string              `json:"customer_name"` // default: company_name lead
+	Industry     string               `json:"industry"`
+	Region       string               `json:"region"`
+	Project      CreateProjectRequest `json:"project"`
+}
+
+type ConvertLeadResponse struct {
+	LeadID          int64   `json:"lead_id"`
+	CustomerID      int64   `json:"customer_id"`
+	CustomerCreated bool    `json:"customer_created"`
+	ProjectID       int64   `json:"project_id"`
+	ProjectCode     string  `json:"project_code"`
+	SPHNumber       *string `json:"sph_number"`
+}
+
+type LeadSourceReportRow struct {
+	Source          string  `json:"source"`
+	Leads           int64   `json:"leads"`
+	Qualified       int64   `json:"qualified"` // qualified + converted
+	Unqualified     int64   `json:"unqualified"`
+	Converted       int64   `json:"converted"`
+	ConversionRate  float64 `json:"conversion_rate"` // converted / leads (%)
+	Projects        int64   `json:"projects"`
+	WonProjects     int64   `json:"won_projects"`
+	PipelineRevenue float64 `json:"pipeline_revenue"` // target revenue semua project
+	WonRevenue      float64 `json:"won_revenue"`
+}
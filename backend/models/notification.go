@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+type Notification struct {
+	ID        int64      `json:"id"`
+	UserID    int64      `json:"user_id"`
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Message   string     `json:"message"`
+	RefType   *string    `json:"ref_type,omitempty"`
+	RefID     *int64     `json:"ref_id,omitempty"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type BudgetAlertThreshold struct {
+	ID           int64   `json:"id,omitempty"`
+	Division     string  `json:"division"`
+	ThresholdPct float64 `json:"threshold_pct"`
+	NotifyEmail  bool    `json:"notify_email"`
+}
+
+type SetBudgetThresholdsRequest struct {
+	Division   string `json:"division" binding:"required"`
+	Thresholds []struct {
+		ThresholdPct float64 `json:"threshold_pct"`
+		NotifyEmail  *bool   `json:"notify_email"`
+	} `json:"thresholds"`
+}
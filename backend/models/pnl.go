@@ -0,0 +1,44 @@
+package models
+
+// PnLMonthRow = revenue vs budget divisi untuk satu bulan (atau total)
+type PnLMonthRow struct {
+	Month              string  `json:"month"`
+	RevenueTarget      float64 `json:"revenue_target"`
+	RevenueRealization float64 `json:"revenue_realization"`
+	Budget             float64 `json:"budget"`
+	BudgetSpend        float64 `json:"budget_spend"`
+	ContributionMargin float64 `json:"contribution_margin"`
+	MarginPct          float64 `json:"margin_pct"`
+}
+
+type PnLProjectLine struct {
+	ID            int64   `json:"id"`
+	ProjectCode   string  `json:"project_code"`
+	Description   string  `json:"description"`
+	Customer      string  `json:"customer"`
+	Division      string  `json:"division"`
+	Status        string  `json:"status"`
+	TargetRevenue float64 `json:"target_revenue"`
+	Realization   float64 `json:"realization"`
+}
+
+type PnLSpendLine struct {
+	ID       int64   `json:"id"`
+	BudgetID int64   `json:"budget_id"`
+	Division string  `json:"division"`
+	Category string  `json:"category"`
+	Amount   float64 `json:"amount"`
+	Note     string  `json:"note"`
+}
+
+type PnLResponse struct {
+	Months []PnLMonthRow `json:"months"`
+	Total  PnLMonthRow   `json:"total"`
+}
+
+// PnLDetailResponse = drill-down satu bulan
+type PnLDetailResponse struct {
+	Summary           PnLMonthRow      `json:"summary"`
+	Projects          []PnLProjectLine `json:"projects"`
+	BudgetRealization []PnLSpendLine   `json:"budget_realization"`
+}
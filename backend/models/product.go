@@ -0,0 +1,97 @@
+package models
+
+import "time"
+
+type Product struct {
+	ID           int64     `json:"id"`
+	SKU          string    `json:"sku"`
+	Name         string    `json:"name"`
+	Category     string    `json:"category"`
+	Unit         string    `json:"unit"`
+	BillingType  string    `json:"billing_type"` // one_time | recurring
+	DefaultPrice float64   `json:"default_price"`
+	IsActive     bool      `json:"is_active"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type ProductRequest struct {
+	SKU          string   `json:"sku" binding:"required"`
+	Name         string   `json:"name" binding:"required"`
+	Category     string   `json:"category"`
+	Unit         string   `json:"unit"`
+	BillingType  string   `json:"billing_type"`
+	DefaultPrice *float64 `json:"default_price"`
+	IsActive     *bool    `json:"is_active"`
+}
+
+type PriceBookEntry struct {
+	ProductID   int64   `json:"product_id"`
+	SKU         string  `json:"sku,omitempty"`
+	ProductName string  `json:"product_name,omitempty"`
+	UnitPrice   float64 `json:"unit_price"`
+}
+
+type PriceBook struct {
+	ID         int64            `json:"id"`
+	Name       string           `json:"name"`
+	Division   *string          `json:"division,omitempty"` // nil = semua divisi
+	Industry   *string          `json:"industry,omitempty"` // segmen customer; nil = semua
+	ValidFrom  string           `json:"valid_from"`         // YYYY-MM-DD
+	ValidTo    *string          `json:"valid_to,omitempty"`
+	IsActive   bool             `json:"is_active"`
+	EntryCount int              `json:"entry_count"`
+	Entries    []PriceBookEntry `json:"entries,omitempty"`
+	CreatedAt  time.Time        `json:"created_at"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+}
+
+type PriceBookRequest struct {
+	Name      string  `json:"name" binding:"required"`
+	Division  *string `json:"division"`
+	Industry  *string `json:"industry"`
+	ValidFrom string  `json:"valid_from" binding:"required"`
+	ValidTo   *string `json:"valid_to"`
+	IsActive  *bool   `json:"is_active"`
+}
+
+type SetPriceBookEntriesRequest struct {
+	Entries []PriceBookEntry `json:"entries"`
+}
+
+// ResolvedPrice = harga produk untuk konteks divisi / segmen / tanggal tertentu
+type ResolvedPrice struct {
+	ProductID     int64   `json:"product_id"`
+	UnitPrice     float64 `json:"unit_price"`
+	PriceBookID   *int64  `json:"price_book_id,omitempty"`
+	PriceBookName *string `json:"price_book_name,omitempty"`
+	Source        string  `json:"source"` // price_book | default
+}
+
+type ProjectLineItem struct {
+	ID          int64     `json:"id"`
+	ProjectID   int64     `json:"project_id"`
+	ProductID   int64     `json:"product_id"`
+	SKU         string    `json:"sku"`
+	ProductName string    `json:"product_name"`
+	Category    string    `json:"category"`
+	BillingType string    `json:"billing_type"`
+	Unit        string    `json:"unit"`
+	PriceBookID *int64    `json:"price_book_id,omitempty"`
+	Qty         float64   `json:"qty"`
+	UnitPrice   float64   `json:"unit_price"`
+	DiscountPct float64   `json:"discount_pct"`
+	Amount      float64   `json:"amount"`
+	Notes       string    `json:"notes"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// unit_price kosong → harga dari price book / default produk
+type ProjectLineItemRequest struct {
+	ProductID   int64    `json:"product_id" binding:"required"`
+	Qty         float64  `json:"qty" binding:"required"`
+	UnitPrice   *float64 `json:"unit_price"`
+	DiscountPct float64  `json:"discount_pct"`
+	Notes       string   `json:"notes"`
+}
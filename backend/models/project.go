@@ -19,12 +19,20 @@ type Project struct {
 	Status                  string     `json:"status"`
 	ProjectType             string     `json:"project_type"`
 	SPHStatus               *string    `json:"sph_status,omitempty"`
+	SPHStatusChangedAt      *time.Time `json:"sph_status_changed_at,omitempty"`
 	SPHRelease              *time.Time `json:"sph_release_date,omitempty"`
 	SalesStage              int        `json:"sales_stage"`
+	StageChangedAt          *time.Time `json:"stage_changed_at,omitempty"`
 	SPHReleaseStatus        string     `json:"sph_release_status"`
 	SPHNumber               *string    `json:"sph_number"`
 	SPHStatusReasonCategory *string    `json:"sph_status_reason_category,omitempty"`
 	SPHStatusReasonNote     *string    `json:"sph_status_reason_note,omitempty"`
+	WinningCompetitorID     *int64     `json:"winning_competitor_id,omitempty"`
+	WinningCompetitorName   *string    `json:"winning_competitor_name,omitempty"`
+	OwnerUserID             *int64     `json:"owner_user_id,omitempty"`
+	OwnerName               *string    `json:"owner_name,omitempty"`
+	LeadID                  *int64     `json:"lead_id,omitempty"`
+	LeadSource              *string    `json:"lead_source,omitempty"`
 	CreatedAt               time.Time  `json:"created_at"`
 	UpdatedAt               time.Time  `json:"updated_at"`
 }
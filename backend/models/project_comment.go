@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+type CommentMention struct {
+	UserID   int64  `json:"user_id"`
+	Username string `json:"username"`
+}
+
+type ProjectComment struct {
+	ID         int64             `json:"id"`
+	ProjectID  int64             `json:"project_id"`
+	ParentID   *int64            `json:"parent_id,omitempty"`
+	AuthorID   *int64            `json:"author_id,omitempty"`
+	AuthorName *string           `json:"author_name,omitempty"`
+	Body       string            `json:"body"` // kosong kalau sudah dihapus
+	IsDeleted  bool              `json:"is_deleted"`
+	EditedAt   *time.Time        `json:"edited_at,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	Mentions   []CommentMention  `json:"mentions"`
+	Replies    []*ProjectComment `json:"replies"`
+}
+
+type ProjectCommentRequest struct {
+	Body     string `json:"body" binding:"required"`
+	ParentID *int64 `json:"parent_id"` // create saja
+}
@@ -0,0 +1,15 @@
+package models
+
+// ProjectOwnerRef = user (sales rep) yang memegang project
+type ProjectOwnerRef struct {
+	UserID   int64   `json:"user_id"`
+	Username string  `json:"username"`
+	Division string  `json:"division"`
+	Email    *string `json:"email,omitempty"`
+}
+
+// nil co_owner_ids = co-owner tidak diubah; [] = hapus semua
+type SetProjectOwnerRequest struct {
+	OwnerUserID int64    `json:"owner_user_id" binding:"required"`
+	CoOwnerIDs  *[]int64 `json:"co_owner_ids"`
+}
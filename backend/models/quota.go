@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+type Quota struct {
+	ID          int64     `json:"id"`
+	Scope       string    `json:"scope"` // user | division
+	UserID      *int64    `json:"user_id,omitempty"`
+	Username    *string   `json:"username,omitempty"`
+	Division    string    `json:"division"` // scope user → divisi user
+	PeriodType  string    `json:"period_type"`
+	Period      string    `json:"period"` // 2026-05 | 2026-Q2 | 2026
+	RevenueType string    `json:"revenue_type"`
+	Amount      float64   `json:"amount"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type QuotaRequest struct {
+	Scope       string  `json:"scope" binding:"required"`
+	UserID      *int64  `json:"user_id"`
+	Division    string  `json:"division"`
+	PeriodType  string  `json:"period_type" binding:"required"`
+	Period      string  `json:"period" binding:"required"`
+	RevenueType string  `json:"revenue_type"`
+	Amount      float64 `json:"amount" binding:"required"`
+}
+
+type UpdateQuotaRequest struct {
+	Amount float64 `json:"amount" binding:"required"`
+}
+
+type QuotaAttainmentRow struct {
+	Rank          int     `json:"rank"`
+	UserID        int64   `json:"user_id"`
+	Username      string  `json:"username"`
+	Quota         float64 `json:"quota"`
+	Realization   float64 `json:"realization"`
+	AttainmentPct float64 `json:"attainment_pct"`
+}
+
+type QuotaAttainmentDivision struct {
+	Division      string               `json:"division"`
+	Quota         float64              `json:"quota"`
+	Realization   float64              `json:"realization"`
+	AttainmentPct float64              `json:"attainment_pct"`
+	Leaderboard   []QuotaAttainmentRow `json:"leaderboard"`
+}
+
+type QuotaAttainmentResponse struct {
+	PeriodType  string                    `json:"period_type"`
+	Period      string                    `json:"period"`
+	Start       string                    `json:"start"` // YYYY-MM-DD
+	End         string                    `json:"end"`   // exclusive
+	RevenueType string                    `json:"revenue_type"`
+	Divisions   []QuotaAttainmentDivision `json:"divisions"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// ======================================================
+// REFERENCE DATA (industry / region / provinsi)
+// ======================================================
+
+type Industry struct {
+	ID            int64     `json:"id"`
+	Name          string    `json:"name"`
+	Aliases       []string  `json:"aliases"`
+	CustomerCount int64     `json:"customer_count"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type Region struct {
+	ID            int64     `json:"id"`
+	Name          string    `json:"name"`
+	Aliases       []string  `json:"aliases"`
+	Provinces     []string  `json:"provinces"`
+	CustomerCount int64     `json:"customer_count"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type Province struct {
+	ID         int64    `json:"id"`
+	Name       string   `json:"name"`
+	Aliases    []string `json:"aliases"`
+	RegionID   *int64   `json:"region_id,omitempty"`
+	RegionName *string  `json:"region_name,omitempty"`
+}
+
+// nil = tidak diubah (partial update)
+type IndustryRequest struct {
+	Name    *string   `json:"name"`
+	Aliases *[]string `json:"aliases"`
+}
+
+type RegionRequest struct {
+	Name      *string   `json:"name"`
+	Aliases   *[]string `json:"aliases"`
+	Provinces *[]string `json:"provinces"` // nama provinsi yang dipetakan ke region ini
+}
@@ -0,0 +1,93 @@
+package models
+
+import "time"
+
+type SPHLineItem struct {
+	LineNo         int     `json:"line_no"`
+	ProductID      *int64  `json:"product_id,omitempty"` // produk katalog (opsional)
+	ProductName    string  `json:"product_name"`
+	Description    string  `json:"description"`
+	Qty            float64 `json:"qty"`
+	Unit           string  `json:"unit"`
+	UnitPrice      float64 `json:"unit_price"`
+	DiscountPct    float64 `json:"discount_pct"`
+	TaxPct         float64 `json:"tax_pct"`
+	GrossAmount    float64 `json:"gross_amount"`
+	DiscountAmount float64 `json:"discount_amount"`
+	TaxAmount      float64 `json:"tax_amount"`
+	LineTotal      float64 `json:"line_total"`
+}
+
+type SPHVersion struct {
+	ID            int64         `json:"id"`
+	DocumentID    int64         `json:"document_id"`
+	VersionNo     int           `json:"version_no"`
+	Status        string        `json:"status"` // draft | released | superseded
+	ValidUntil    *string       `json:"valid_until,omitempty"`
+	Notes         string        `json:"notes"`
+	Terms         string        `json:"terms"`
+	Subtotal      float64       `json:"subtotal"`
+	DiscountTotal float64       `json:"discount_total"`
+	TaxTotal      float64       `json:"tax_total"`
+	GrandTotal    float64       `json:"grand_total"`
+	SPHNumber     *string       `json:"sph_number,omitempty"`
+	MaxDiscount   float64       `json:"max_discount_pct"`
+	Approval      string        `json:"approval_status"` // not_required | pending | approved | rejected
+	ReleasedAt    *time.Time    `json:"released_at,omitempty"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+	Items         []SPHLineItem `json:"items,omitempty"`
+}
+
+type SPHDocument struct {
+	ID        int64        `json:"id"`
+	ProjectID int64        `json:"project_id"`
+	Title     string       `json:"title"`
+	Currency  string       `json:"currency"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+	Versions  []SPHVersion `json:"versions"`
+}
+
+// product_id → product_name / unit kosong diisi dari katalog
+type SPHLineItemRequest struct {
+	ProductID   *int64  `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	Description string  `json:"description"`
+	Qty         float64 `json:"qty"`
+	Unit        string  `json:"unit"`
+	UnitPrice   float64 `json:"unit_price"`
+	DiscountPct float64 `json:"discount_pct"`
+	TaxPct      float64 `json:"tax_pct"`
+}
+
+// SPHVersionRequest dipakai create & update versi draft.
+// Create tanpa items + copy_from_version_id → items disalin dari versi tsb.
+// Create tanpa items + from_line_items → items dari project line items (katalog).
+type SPHVersionRequest struct {
+	Title             *string              `json:"title"`
+	ValidUntil        *string              `json:"valid_until"` // YYYY-MM-DD
+	Notes             string               `json:"notes"`
+	Terms             string               `json:"terms"`
+	Items             []SPHLineItemRequest `json:"items"`
+	CopyFromVersionID *int64               `json:"copy_from_version_id"`
+	FromLineItems     bool                 `json:"from_line_items"`
+}
+
+type ReleaseSPHRequest struct {
+	SPHNumber   *string `json:"sph_number"`
+	ReleaseDate *string `json:"release_date"` // YYYY-MM-DD, default hari ini
+}
+
+type SPHNumberFormat struct {
+	Division   string    `json:"division"`
+	Format     string    `json:"format"`
+	SeqPadding int       `json:"seq_padding"`
+	Preview    string    `json:"preview"` // contoh nomor berikutnya (tidak mengubah counter)
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type SPHNumberFormatRequest struct {
+	Format     string `json:"format" binding:"required"`
+	SeqPadding int    `json:"seq_padding"`
+}
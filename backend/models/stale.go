@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+type StaleRule struct {
+	ID            int64     `json:"id"`
+	Division      *string   `json:"division"` // null = default semua divisi
+	RuleType      string    `json:"rule_type"`
+	SalesStage    *int      `json:"sales_stage,omitempty"`
+	ThresholdDays int       `json:"threshold_days"`
+	IsActive      bool      `json:"is_active"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type StaleRuleRequest struct {
+	Division      *string `json:"division"`
+	RuleType      string  `json:"rule_type" binding:"required"`
+	SalesStage    *int    `json:"sales_stage"`
+	ThresholdDays *int    `json:"threshold_days" binding:"required"`
+	IsActive      *bool   `json:"is_active"`
+}
+
+// EffectiveStaleRule = rule yang berlaku untuk satu divisi (hasil override)
+type EffectiveStaleRule struct {
+	StaleRule
+	Inherited bool `json:"inherited"` // true = dari rule default
+}
+
+type StaleProjectRow struct {
+	ProjectID      int64     `json:"project_id"`
+	ProjectCode    string    `json:"project_code"`
+	Description    string    `json:"description"`
+	CustomerName   string    `json:"customer_name"`
+	Division       string    `json:"division"`
+	SalesStage     int       `json:"sales_stage"`
+	SPHStatus      *string   `json:"sph_status,omitempty"`
+	OwnerUserID    *int64    `json:"owner_user_id,omitempty"`
+	OwnerName      *string   `json:"owner_name,omitempty"`
+	RuleType       string    `json:"rule_type"`
+	ThresholdDays  int       `json:"threshold_days"`
+	DaysStale      int       `json:"days_stale"`
+	Detail         string    `json:"detail"`
+	FirstFlaggedAt time.Time `json:"first_flagged_at"`
+	LastEvaluated  time.Time `json:"last_evaluated_at"`
+}
@@ -3,11 +3,14 @@ package models
 import "time"
 
 type User struct {
-	ID           int64     `json:"id"`
-	Username     string    `json:"username"`
-	PasswordHash string    `json:"-"`
-	Role         string    `json:"role"`     // "admin" or "user"
-	Division     string    `json:"division"` // NetCo, Oil Gas & Mining, IT Solutions
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID            int64      `json:"id"`
+	Username      string     `json:"username"`
+	PasswordHash  string     `json:"-"`
+	Role          string     `json:"role"`     // "admin" or "user"
+	Division      string     `json:"division"` // NetCo, Oil Gas & Mining, IT Solutions
+	Email         *string    `json:"email,omitempty"`
+	IsActive      bool       `json:"is_active"`
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
 }
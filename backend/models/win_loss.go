@@ -0,0 +1,39 @@
+package models
+
+// WinLossBucket = agregat keputusan SPH untuk satu dimensi (divisi, customer, ...)
+type WinLossBucket struct {
+	Key               string   `json:"key"`
+	Label             string   `json:"label,omitempty"`
+	Decided           int64    `json:"decided"` // win + loss + drop
+	Win               int64    `json:"win"`
+	Loss              int64    `json:"loss"`
+	Drop              int64    `json:"drop"`
+	WinRate           float64  `json:"win_rate"` // win / decided (%)
+	WinValue          float64  `json:"win_value"`
+	LossValue         float64  `json:"loss_value"`
+	DropValue         float64  `json:"drop_value"`
+	AvgDaysToDecision *float64 `json:"avg_days_to_decision"` // SPH release → keputusan
+}
+
+type WinLossReasonRow struct {
+	Category  string  `json:"category"` // Administrasi | Teknis | Other | Unspecified
+	Loss      int64   `json:"loss"`
+	LossValue float64 `json:"loss_value"`
+	Drop      int64   `json:"drop"`
+	DropValue float64 `json:"drop_value"`
+}
+
+type WinLossResponse struct {
+	PeriodType    string             `json:"period_type"`
+	From          *string            `json:"from,omitempty"`
+	To            *string            `json:"to,omitempty"`
+	Division      string             `json:"division"`
+	Summary       WinLossBucket      `json:"summary"`
+	OpenCount     int64              `json:"open"` // masih Open / Hold (belum diputus)
+	HoldCount     int64              `json:"hold"`
+	ByReason      []WinLossReasonRow `json:"by_reason"`
+	ByDivision    []WinLossBucket    `json:"by_division"`
+	ByCustomer    []WinLossBucket    `json:"by_customer"`
+	ByProjectType []WinLossBucket    `json:"by_project_type"`
+	ByPeriod      []WinLossBucket    `json:"by_period"`
+}
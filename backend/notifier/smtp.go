@@ -0,0 +1,98 @@
+package notifier
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+// Sender mengirim email notifikasi.
+type Sender interface {
+	Send(to []string, subject, body string) error
+}
+
+// SMTPSender mengirim email lewat server SMTP biasa.
+// Tanpa username/password (mis. MailHog lokal) email dikirim tanpa AUTH.
+type SMTPSender struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+var Default Sender
+
+// Init membaca konfigurasi SMTP dari environment.
+// Kalau SMTP_HOST kosong, channel email dimatikan (hanya in-app notification).
+func Init() {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		log.Println("SMTP_HOST not set, email notifications disabled")
+		return
+	}
+
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "1025"
+	}
+
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		from = "sales-system@localhost"
+	}
+
+	Default = &SMTPSender{
+		Host:     host,
+		Port:     port,
+		Username: os.Getenv("SMTP_USER"),
+		Password: os.Getenv("SMTP_PASS"),
+		From:     from,
+	}
+	log.Println("SMTP sender configured:", net.JoinHostPort(host, port))
+}
+
+func (s *SMTPSender) Send(to []string, subject, body string) error {
+	if len(to) == 0 {
+		return nil
+	}
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+
+	addr := net.JoinHostPort(s.Host, s.Port)
+	return smtp.SendMail(addr, auth, s.From, to, buildMessage(s.From, to, subject, body))
+}
+
+func buildMessage(from string, to []string, subject, body string) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// SendAsync mengirim email di background supaya request API tidak menunggu SMTP.
+// Error hanya di-log.
+func SendAsync(to []string, subject, body string) {
+	if Default == nil || len(to) == 0 {
+		return
+	}
+
+	go func() {
+		if err := Default.Send(to, subject, body); err != nil {
+			log.Println("EMAIL SEND ERROR:", err)
+		}
+	}()
+}
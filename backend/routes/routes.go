@@ -48,20 +48,216 @@ func Register(r *gin.Engine) {
 
 	auth.GET("/projects/summary", handlers.GetProjectsSummary)
 
+	auth.PUT("/projects/:id/owner", middleware.AdminOnly(), handlers.SetProjectOwner)
+
+	auth.POST("/projects/:id/stakeholders", handlers.AddProjectStakeholder)
+	auth.DELETE("/projects/:id/stakeholders/:contactId", handlers.RemoveProjectStakeholder)
+
+	auth.GET("/projects/:id/line-items", handlers.ListProjectLineItems)
+	auth.POST("/projects/:id/line-items", handlers.AddProjectLineItem)
+	auth.PUT("/projects/:id/line-items/:itemId", handlers.UpdateProjectLineItem)
+	auth.DELETE("/projects/:id/line-items/:itemId", handlers.DeleteProjectLineItem)
+
+	auth.GET("/projects/:id/competitors", handlers.ListProjectCompetitors)
+	auth.PUT("/projects/:id/competitors/:competitorId", handlers.SetProjectCompetitor)
+	auth.DELETE("/projects/:id/competitors/:competitorId", handlers.RemoveProjectCompetitor)
+
+	auth.GET("/projects/:id/comments", handlers.ListProjectComments)
+	auth.POST("/projects/:id/comments", handlers.CreateProjectComment)
+	auth.PUT("/projects/:id/comments/:commentId", handlers.UpdateProjectComment)
+	auth.DELETE("/projects/:id/comments/:commentId", handlers.DeleteProjectComment)
+
+	auth.GET("/projects/:id/sph", handlers.GetProjectSPH)
+	auth.POST("/projects/:id/sph/release", handlers.ReleaseProjectSPH)
+	auth.POST("/projects/:id/sph/versions", handlers.CreateSPHVersion)
+	auth.GET("/projects/:id/sph/versions/:versionId", handlers.GetSPHVersion)
+	auth.PUT("/projects/:id/sph/versions/:versionId", handlers.UpdateSPHVersion)
+	auth.DELETE("/projects/:id/sph/versions/:versionId", handlers.DeleteSPHVersion)
+	auth.POST("/projects/:id/sph/versions/:versionId/release", handlers.ReleaseSPHVersion)
+	auth.GET("/projects/:id/sph/versions/:versionId/pdf", handlers.DownloadSPHVersionPDF)
+	auth.GET("/projects/:id/sph/versions/:versionId/approvals", handlers.ListSPHVersionApprovals)
+	auth.POST("/projects/:id/sph/versions/:versionId/approvals", handlers.RequestSPHApproval)
+
 	// ===============================
 	// CUSTOMER ROUTES
 	// ===============================
 	auth.GET("/customers", handlers.GetCustomers)
+	auth.GET("/customers/duplicates", handlers.GetCustomerDuplicates)
 	auth.GET("/customers/:id", handlers.GetCustomer)
 	auth.POST("/customers", handlers.CreateCustomer)
 	auth.PUT("/customers/:id", handlers.UpdateCustomer)
 	auth.DELETE("/customers/:id", handlers.DeleteCustomer)
 
+	auth.GET("/customers/:id/children", handlers.GetCustomerChildren)
+	auth.POST("/customers/:id/children", handlers.AttachCustomerChild)
+	auth.DELETE("/customers/:id/children/:childId", handlers.DetachCustomerChild)
+
+	auth.GET("/customers/:id/contacts", handlers.ListCustomerContacts)
+	auth.POST("/customers/:id/contacts", handlers.CreateCustomerContact)
+	auth.PUT("/customers/:id/contacts/:contactId", handlers.UpdateCustomerContact)
+	auth.DELETE("/customers/:id/contacts/:contactId", handlers.DeleteCustomerContact)
+
+	auth.POST("/customers/:id/merge", middleware.AdminOnly(), handlers.MergeCustomers)
+	auth.GET("/customers/:id/merge-history", handlers.GetCustomerMergeHistory)
+
+	auth.GET("/customers/:id/summary", handlers.GetCustomerSummary)
+	auth.PUT("/customers/:id/divisions", middleware.AdminOnly(), handlers.SetCustomerDivisions)
+
+	// ===============================
+	// REFERENCE DATA (industry / region)
+	// ===============================
+	auth.GET("/industries", handlers.ListIndustries)
+	auth.POST("/industries", middleware.AdminOnly(), handlers.CreateIndustry)
+	auth.PUT("/industries/:id", middleware.AdminOnly(), handlers.UpdateIndustry)
+	auth.DELETE("/industries/:id", middleware.AdminOnly(), handlers.DeleteIndustry)
+
+	auth.GET("/regions", handlers.ListRegions)
+	auth.POST("/regions", middleware.AdminOnly(), handlers.CreateRegion)
+	auth.PUT("/regions/:id", middleware.AdminOnly(), handlers.UpdateRegion)
+	auth.DELETE("/regions/:id", middleware.AdminOnly(), handlers.DeleteRegion)
+	auth.GET("/provinces", handlers.ListProvinces)
+
+	// ===============================
+	// DISCOUNT APPROVAL
+	// ===============================
+	auth.GET("/approvals", handlers.ListSPHApprovals)
+	auth.POST("/approvals/:id/approve", handlers.ApproveSPHApproval)
+	auth.POST("/approvals/:id/reject", handlers.RejectSPHApproval)
+
+	auth.GET("/discount-rules", handlers.ListDiscountRules)
+	auth.POST("/discount-rules", middleware.AdminOnly(), handlers.CreateDiscountRule)
+	auth.PUT("/discount-rules/:id", middleware.AdminOnly(), handlers.UpdateDiscountRule)
+	auth.DELETE("/discount-rules/:id", middleware.AdminOnly(), handlers.DeleteDiscountRule)
+
+	auth.GET("/approvers", middleware.AdminOnly(), handlers.ListApprovers)
+	auth.POST("/approvers", middleware.AdminOnly(), handlers.CreateApprover)
+	auth.DELETE("/approvers/:id", middleware.AdminOnly(), handlers.DeleteApprover)
+
+	// ===============================
+	// PRODUCT CATALOG + PRICE BOOKS
+	// ===============================
+	auth.GET("/products", handlers.ListProducts)
+	auth.POST("/products", middleware.AdminOnly(), handlers.CreateProduct)
+	auth.PUT("/products/:id", middleware.AdminOnly(), handlers.UpdateProduct)
+	auth.DELETE("/products/:id", middleware.AdminOnly(), handlers.DeleteProduct)
+	auth.GET("/products/:id/price", handlers.GetProductPrice)
+
+	auth.GET("/price-books", handlers.ListPriceBooks)
+	auth.GET("/price-books/:id", handlers.GetPriceBook)
+	auth.POST("/price-books", middleware.AdminOnly(), handlers.CreatePriceBook)
+	auth.PUT("/price-books/:id", middleware.AdminOnly(), handlers.UpdatePriceBook)
+	auth.DELETE("/price-books/:id", middleware.AdminOnly(), handlers.DeletePriceBook)
+	auth.PUT("/price-books/:id/entries", middleware.AdminOnly(), handlers.SetPriceBookEntries)
+
+	// ===============================
+	// COMPETITORS
+	// ===============================
+	auth.GET("/competitors", handlers.ListCompetitors)
+	auth.POST("/competitors", middleware.AdminOnly(), handlers.CreateCompetitor)
+	auth.PUT("/competitors/:id", middleware.AdminOnly(), handlers.UpdateCompetitor)
+	auth.DELETE("/competitors/:id", middleware.AdminOnly(), handlers.DeleteCompetitor)
+
+	// ===============================
+	// SPH NUMBER FORMAT
+	// ===============================
+	auth.GET("/sph-number-formats", handlers.ListSPHNumberFormats)
+	auth.PUT("/sph-number-formats/:division", middleware.AdminOnly(), handlers.SetSPHNumberFormat)
+
 	// ===============================
 	// DASHBOARD ROUTES
 	// ===============================
 	auth.GET("/dashboard", handlers.GetDashboard)
 
+	// ===============================
+	// REPORT ROUTES
+	// ===============================
+	reports := auth.Group("/reports")
+	{
+		reports.GET("/pnl", handlers.GetDivisionPnL)
+		reports.GET("/pnl/:month", handlers.GetDivisionPnLDetail)
+		reports.GET("/lead-sources", handlers.GetLeadSourceReport)
+		reports.GET("/win-loss", handlers.GetWinLossReport)
+		reports.GET("/competitors", handlers.GetCompetitorReport)
+		reports.GET("/stale-projects", handlers.GetStaleProjectsReport)
+		reports.POST("/stale-projects/run", middleware.AdminOnly(), handlers.RunStaleProjectEvaluation)
+	}
+
+	// ===============================
+	// STALE DEAL RULES
+	// ===============================
+	staleRules := auth.Group("/stale-rules")
+	{
+		staleRules.GET("", handlers.ListStaleRules)
+		staleRules.PUT("", middleware.AdminOnly(), handlers.SetStaleRule)
+		staleRules.DELETE("/:id", middleware.AdminOnly(), handlers.DeleteStaleRule)
+	}
+
+	// ===============================
+	// ACTIVITY ROUTES
+	// ===============================
+	activities := auth.Group("/activities")
+	{
+		activities.GET("", handlers.ListActivities)
+		activities.GET("/overdue", handlers.ListOverdueNextSteps)
+		activities.POST("", handlers.CreateActivity)
+		activities.GET("/:id", handlers.GetActivity)
+		activities.PUT("/:id", handlers.UpdateActivity)
+		activities.PUT("/:id/next-step", handlers.SetActivityNextStepDone)
+		activities.DELETE("/:id", handlers.DeleteActivity)
+	}
+
+	// ===============================
+	// LEAD ROUTES
+	// ===============================
+	leads := auth.Group("/leads")
+	{
+		leads.GET("", handlers.ListLeads)
+		leads.GET("/sources", handlers.ListLeadSources)
+		leads.POST("", handlers.CreateLead)
+		leads.GET("/:id", handlers.GetLead)
+		leads.PUT("/:id", handlers.UpdateLead)
+		leads.DELETE("/:id", handlers.DeleteLead)
+		leads.PUT("/:id/assign", handlers.AssignLead)
+		leads.POST("/:id/qualify", handlers.QualifyLead)
+		leads.POST("/:id/convert", handlers.ConvertLead)
+	}
+
+	// ===============================
+	// QUOTA ROUTES
+	// ===============================
+	quotas := auth.Group("/quotas")
+	{
+		quotas.GET("", handlers.ListQuotas)
+		quotas.GET("/attainment", handlers.GetQuotaAttainment)
+		quotas.POST("", middleware.AdminOnly(), handlers.SetQuota)
+		quotas.PUT("/:id", middleware.AdminOnly(), handlers.UpdateQuota)
+		quotas.DELETE("/:id", middleware.AdminOnly(), handlers.DeleteQuota)
+	}
+
+	// ===============================
+	// COMMISSION ROUTES
+	// ===============================
+	commissions := auth.Group("/commissions")
+	{
+		commissions.GET("/plans", handlers.ListCommissionPlans)
+		commissions.POST("/plans", middleware.AdminOnly(), handlers.CreateCommissionPlan)
+		commissions.PUT("/plans/:id", middleware.AdminOnly(), handlers.UpdateCommissionPlan)
+		commissions.DELETE("/plans/:id", middleware.AdminOnly(), handlers.DeleteCommissionPlan)
+
+		commissions.GET("/runs", handlers.ListCommissionRuns)
+		commissions.POST("/runs", middleware.AdminOnly(), handlers.RunCommissionCalculation)
+		commissions.GET("/runs/:id", handlers.GetCommissionRun)
+		commissions.POST("/runs/:id/close", middleware.AdminOnly(), handlers.CloseCommissionRun)
+		commissions.GET("/runs/:id/statements/:userId", handlers.GetCommissionStatement)
+	}
+
+	// ===============================
+	// NOTIFICATION ROUTES
+	// ===============================
+	auth.GET("/notifications", handlers.ListNotifications)
+	auth.PUT("/notifications/read-all", handlers.MarkAllNotificationsRead)
+	auth.PUT("/notifications/:id/read", handlers.MarkNotificationRead)
+
 	// ===============================
 	// BUDGET ROUTES
 	// ===============================
@@ -71,6 +267,12 @@ func Register(r *gin.Engine) {
 		budgets.POST("", handlers.CreateBudget)
 		budgets.GET("", handlers.ListBudgets)
 		budgets.GET("/trend", handlers.GetBudgetTrend)
+		budgets.GET("/rollup", handlers.GetBudgetRollup)
+		budgets.GET("/export", handlers.ExportBudgets)
+		budgets.POST("/import", handlers.ImportBudgets)
+		budgets.GET("/thresholds", handlers.GetBudgetThresholds)
+		budgets.PUT("/thresholds", middleware.AdminOnly(), handlers.SetBudgetThresholds)
+		budgets.PUT("/bulk", handlers.BulkUpdateBudgets)
 
 		// REALIZATIONS FIRST (before :budgetId)
 		realizations := budgets.Group("/:budgetId/realizations")
@@ -83,5 +285,7 @@ func Register(r *gin.Engine) {
 		// WILDCARD LAST (budget detail)
 		budgets.GET("/:budgetId", handlers.GetBudgetDetail)
 		budgets.PUT("/:budgetId", handlers.UpdateBudget)
+		budgets.DELETE("/:budgetId", handlers.DeleteBudget)
+		budgets.POST("/:budgetId/copy", handlers.CopyBudget)
 	}
 }